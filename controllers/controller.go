@@ -46,6 +46,12 @@ type ControllerConfig struct {
 	InstanceID string
 	// namespace is a label selector filter to limit controller's watch to specific namespace
 	Namespace string
+	// Framework is the build framework builder jobs run with, one of docker, buildah, podman or kaniko.
+	// Defaults to docker
+	Framework v1alpha1.Framework
+	// Image is the image of the builder job container.
+	// Defaults to the ocictl image, or the kaniko executor image for kaniko builds
+	Image string
 }
 
 // Controller listens for new ocibuilder resources and hands off handling of each resource on the queue to the operator
@@ -17,12 +17,18 @@ limitations under the License.
 package ocibuilder
 
 import (
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/common"
 	"github.com/ocibuilder/ocibuilder/pkg/validate"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // the context of an operation on a ocibuilder object.
@@ -66,6 +72,9 @@ func (opCtx *operationContext) operate() error {
 
 	switch opCtx.builder.Status.Phase {
 	case v1alpha1.NodePhaseNew:
+		if err := opCtx.applySpecConfigMap(); err != nil {
+			return err
+		}
 		opCtx.constructBuilderJob()
 	case v1alpha1.NodePhaseRunning:
 	case v1alpha1.NodePhaseCompleted:
@@ -77,7 +86,175 @@ func (opCtx *operationContext) operate() error {
 	return nil
 }
 
+// specConfigMapName returns the name of the configmap builder jobs of the resource read its spec from
+func (opCtx *operationContext) specConfigMapName() string {
+	return opCtx.builder.Name + "-spec"
+}
+
+// constructSpecConfigMap constructs the K8s configmap holding the spec of the resource as an ocibuilder.yaml,
+// which is mounted into the builder job
+func (opCtx *operationContext) constructSpecConfigMap() (*corev1.ConfigMap, error) {
+	spec, err := yaml.Marshal(opCtx.builder.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the resource spec")
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opCtx.specConfigMapName(),
+			Namespace: opCtx.builder.Namespace,
+			Labels: map[string]string{
+				common.LabelOCIBuilderName: opCtx.builder.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(opCtx.builder, v1alpha1.SchemaGroupVersionKind),
+			},
+		},
+		Data: map[string]string{
+			common.BuilderJobSpecKey: string(spec),
+		},
+	}, nil
+}
+
+// applySpecConfigMap creates the configmap holding the spec of the resource, or updates it if it already exists,
+// so that it can be mounted by the builder job
+func (opCtx *operationContext) applySpecConfigMap() error {
+	configMap, err := opCtx.constructSpecConfigMap()
+	if err != nil {
+		return err
+	}
+	cmClient := opCtx.controller.kubeClient.CoreV1().ConfigMaps(configMap.Namespace)
+	existing, err := cmClient.Get(configMap.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get the spec configmap %s", configMap.Name)
+		}
+		if _, err := cmClient.Create(configMap); err != nil {
+			return errors.Wrapf(err, "failed to create the spec configmap %s", configMap.Name)
+		}
+		return nil
+	}
+	existing.Labels = configMap.Labels
+	existing.OwnerReferences = configMap.OwnerReferences
+	existing.Data = configMap.Data
+	if _, err := cmClient.Update(existing); err != nil {
+		return errors.Wrapf(err, "failed to update the spec configmap %s", configMap.Name)
+	}
+	return nil
+}
+
 // constructBuilderJob constructs a K8s job for ocibuilder build step.
+// The job runs ocictl with the framework chosen in the controller config, reading the spec from the configmap
+// constructed by constructSpecConfigMap. Kaniko jobs run unprivileged in the kaniko executor image, with ocictl
+// copied in by an init container.
 func (opCtx *operationContext) constructBuilderJob() *batchv1.Job {
-	return &batchv1.Job{}
+	framework := v1alpha1.DockerFramework
+	image := ""
+	if config := opCtx.controller.config; config != nil {
+		if config.Framework != "" {
+			framework = config.Framework
+		}
+		image = config.Image
+	}
+
+	container := corev1.Container{
+		Name:    "builder",
+		Image:   image,
+		Command: []string{"ocictl"},
+		Args:    []string{"build", "--builder", string(framework), "--path", common.BuilderJobSpecDirectory},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "spec",
+				MountPath: common.BuilderJobSpecDirectory,
+				ReadOnly:  true,
+			},
+		},
+	}
+	podSpec := corev1.PodSpec{
+		RestartPolicy: corev1.RestartPolicyNever,
+		Volumes: []corev1.Volume{
+			{
+				Name: "spec",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: opCtx.specConfigMapName()},
+						Items:                []corev1.KeyToPath{{Key: common.BuilderJobSpecKey, Path: common.BuilderJobSpecKey}},
+					},
+				},
+			},
+		},
+	}
+
+	switch framework {
+	case v1alpha1.DockerFramework:
+		// builds on a remote docker daemon do not need the docker socket of the node
+		if docker := opCtx.builder.Spec.Docker; docker != nil && docker.Host != "" {
+			break
+		}
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "docker-socket",
+			MountPath: common.DockerSocketPath,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "docker-socket",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: common.DockerSocketPath},
+			},
+		})
+	case v1alpha1.BuildahFramework, v1alpha1.PodmanFramework:
+		privileged := true
+		container.SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+	case v1alpha1.KanikoFramework:
+		if container.Image == "" {
+			container.Image = common.KanikoImage
+		}
+		container.Command = []string{filepath.Join(common.BuilderJobBinDirectory, "ocictl")}
+		container.Env = append(container.Env, corev1.EnvVar{Name: "DOCKER_CONFIG", Value: common.KanikoDockerConfigDir})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "ocictl",
+			MountPath: common.BuilderJobBinDirectory,
+		})
+		podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+			Name:    "ocictl",
+			Image:   common.OCICtlImage,
+			Command: []string{"cp", "/bin/ocictl", common.BuilderJobBinDirectory},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "ocictl",
+					MountPath: common.BuilderJobBinDirectory,
+				},
+			},
+		})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "ocictl",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+	if container.Image == "" {
+		container.Image = common.OCICtlImage
+	}
+	podSpec.Containers = []corev1.Container{container}
+
+	labels := map[string]string{
+		common.LabelOCIBuilderName: opCtx.builder.Name,
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: opCtx.builder.Name + "-",
+			Namespace:    opCtx.builder.Namespace,
+			Labels:       labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(opCtx.builder, v1alpha1.SchemaGroupVersionKind),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
 }
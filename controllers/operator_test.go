@@ -15,3 +15,140 @@ limitations under the License.
 */
 
 package ocibuilder
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConstructBuilderJob(t *testing.T) {
+	opCtx := &operationContext{
+		builder:    builder,
+		controller: &Controller{},
+	}
+
+	job := opCtx.constructBuilderJob()
+	assert.Equal(t, "test-builder-", job.GenerateName)
+	assert.Equal(t, "builds", job.Namespace)
+
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, 1, len(podSpec.Containers))
+	assert.Equal(t, common.OCICtlImage, podSpec.Containers[0].Image)
+	assert.Equal(t, []string{"build", "--builder", "docker", "--path", common.BuilderJobSpecDirectory}, podSpec.Containers[0].Args)
+	assert.Equal(t, "test-builder-spec", podSpec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, common.BuilderJobSpecDirectory, podSpec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, common.DockerSocketPath, podSpec.Volumes[1].HostPath.Path)
+}
+
+func TestConstructBuilderJob_RemoteDocker(t *testing.T) {
+	remoteBuilder := builder.DeepCopy()
+	remoteBuilder.Spec.Docker = &v1alpha1.DockerSpec{Host: "tcp://build-host:2376"}
+	opCtx := &operationContext{
+		builder:    remoteBuilder,
+		controller: &Controller{},
+	}
+
+	podSpec := opCtx.constructBuilderJob().Spec.Template.Spec
+	// only the spec is mounted
+	assert.Equal(t, 1, len(podSpec.Volumes))
+	assert.Equal(t, 1, len(podSpec.Containers[0].VolumeMounts))
+}
+
+func TestConstructBuilderJob_Kaniko(t *testing.T) {
+	opCtx := &operationContext{
+		builder: builder,
+		controller: &Controller{
+			config: &ControllerConfig{Framework: v1alpha1.KanikoFramework},
+		},
+	}
+
+	job := opCtx.constructBuilderJob()
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, 1, len(podSpec.InitContainers))
+	assert.Equal(t, common.OCICtlImage, podSpec.InitContainers[0].Image)
+
+	container := podSpec.Containers[0]
+	assert.Equal(t, common.KanikoImage, container.Image)
+	assert.Equal(t, []string{"/ocib/bin/ocictl"}, container.Command)
+	assert.Equal(t, []string{"build", "--builder", "kaniko", "--path", common.BuilderJobSpecDirectory}, container.Args)
+	assert.Nil(t, container.SecurityContext)
+}
+
+func TestConstructSpecConfigMap(t *testing.T) {
+	specBuilder := builder.DeepCopy()
+	specBuilder.Spec.Build = &v1alpha1.BuildSpec{
+		Steps: []v1alpha1.BuildStep{{ImageMetadata: &v1alpha1.ImageMetadata{Name: "my-step"}}},
+	}
+	opCtx := &operationContext{
+		builder:    specBuilder,
+		controller: &Controller{},
+	}
+
+	configMap, err := opCtx.constructSpecConfigMap()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "test-builder-spec", configMap.Name)
+	assert.Equal(t, "builds", configMap.Namespace)
+
+	var spec v1alpha1.OCIBuilderSpec
+	assert.Equal(t, nil, yaml.Unmarshal([]byte(configMap.Data[common.BuilderJobSpecKey]), &spec))
+	assert.Equal(t, "my-step", spec.Build.Steps[0].Name)
+}
+
+func TestApplySpecConfigMap(t *testing.T) {
+	specBuilder := builder.DeepCopy()
+	specBuilder.Spec.Build = &v1alpha1.BuildSpec{
+		Steps: []v1alpha1.BuildStep{{ImageMetadata: &v1alpha1.ImageMetadata{Name: "my-step"}}},
+	}
+	kubeClient := fake.NewSimpleClientset()
+	opCtx := &operationContext{
+		builder:    specBuilder,
+		controller: &Controller{kubeClient: kubeClient},
+	}
+
+	err := opCtx.applySpecConfigMap()
+	assert.Equal(t, nil, err)
+
+	configMap, err := kubeClient.CoreV1().ConfigMaps("builds").Get("test-builder-spec", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "test-builder", configMap.OwnerReferences[0].Name)
+	assert.Equal(t, true, *configMap.OwnerReferences[0].Controller)
+
+	var spec v1alpha1.OCIBuilderSpec
+	assert.Equal(t, nil, yaml.Unmarshal([]byte(configMap.Data[common.BuilderJobSpecKey]), &spec))
+	assert.Equal(t, "my-step", spec.Build.Steps[0].Name)
+
+	specBuilder.Spec.Build.Steps[0].Name = "my-updated-step"
+	err = opCtx.applySpecConfigMap()
+	assert.Equal(t, nil, err)
+
+	configMap, err = kubeClient.CoreV1().ConfigMaps("builds").Get("test-builder-spec", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, yaml.Unmarshal([]byte(configMap.Data[common.BuilderJobSpecKey]), &spec))
+	assert.Equal(t, "my-updated-step", spec.Build.Steps[0].Name)
+}
+
+func TestConstructBuilderJob_Buildah(t *testing.T) {
+	opCtx := &operationContext{
+		builder: builder,
+		controller: &Controller{
+			config: &ControllerConfig{Framework: v1alpha1.BuildahFramework, Image: "example-registry/ocictl:v0.1.0"},
+		},
+	}
+
+	container := opCtx.constructBuilderJob().Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "example-registry/ocictl:v0.1.0", container.Image)
+	assert.Equal(t, true, *container.SecurityContext.Privileged)
+}
+
+var builder = &v1alpha1.OCIBuilder{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-builder",
+		Namespace: "builds",
+	},
+}
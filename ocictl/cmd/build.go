@@ -17,14 +17,10 @@ limitations under the License.
 package cmd
 
 import (
-	"errors"
 	"io"
 
-	"github.com/docker/docker/client"
 	"github.com/ocibuilder/ocibuilder/ocictl/pkg/utils"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
-	"github.com/ocibuilder/ocibuilder/pkg/buildah"
-	"github.com/ocibuilder/ocibuilder/pkg/docker"
 	"github.com/ocibuilder/ocibuilder/pkg/oci"
 	"github.com/ocibuilder/ocibuilder/pkg/read"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
@@ -33,7 +29,7 @@ import (
 
 const buildDesc = `
 This command runs an image build with the specification defined in your projects ocibuilder.yaml file.
-It can run a build in both docker and buildah varieties.
+It can run a build with docker, buildah, podman, kaniko or buildkit.
 `
 
 type buildCmd struct {
@@ -49,7 +45,7 @@ func newBuildCmd(out io.Writer) *cobra.Command {
 	bc := &buildCmd{out: out}
 	cmd := &cobra.Command{
 		Use:   "build",
-		Short: "builds an oci compliant image using either docker or buildah",
+		Short: "builds an oci compliant image using docker, buildah, podman, kaniko or buildkit",
 		Long:  buildDesc,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return bc.run(args)
@@ -58,7 +54,7 @@ func newBuildCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.StringVarP(&bc.name, "name", "n", "", "Specify the name of your build or defined in ocibuilder.yaml")
 	f.StringVarP(&bc.path, "path", "p", "", "Path to your ocibuilder.yaml or build.yaml. By default will look in the current working directory")
-	f.StringVarP(&bc.builder, "builder", "b", "docker", "Choose either docker and buildah as the targeted image builder. By default the builder is docker.")
+	f.StringVarP(&bc.builder, "builder", "b", "docker", builderFlagDesc)
 	f.BoolVarP(&bc.debug, "debug", "d", false, "Turn on debug logging")
 	f.StringVarP(&bc.overlay, "overlay", "o", "", "Path to your overlay.yaml file")
 
@@ -66,7 +62,6 @@ func newBuildCmd(out io.Writer) *cobra.Command {
 }
 
 func (b *buildCmd) run(args []string) error {
-	var cli v1alpha1.BuilderClient
 	logger := util.GetLogger(b.debug)
 	reader := read.Reader{Logger: logger}
 	ociBuilderSpec := v1alpha1.OCIBuilderSpec{Daemon: true}
@@ -78,49 +73,28 @@ func (b *buildCmd) run(args []string) error {
 
 	// Prioritise builder passed in as argument, default builder is docker
 	builderType := b.builder
-	if !ociBuilderSpec.Daemon {
+	if !ociBuilderSpec.Daemon && builderType == "docker" {
 		builderType = "buildah"
 	}
 
-	switch v1alpha1.Framework(builderType) {
-
-	case v1alpha1.DockerFramework:
-		{
-			apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-			if err != nil {
-				log.WithError(err).Errorln("failed to fetch docker api client")
-				return err
-			}
-
-			cli = docker.Client{
-				APIClient: apiClient,
-				Logger:    logger,
-			}
-
-			ociBuilderSpec.Daemon = true
-		}
-
-	case v1alpha1.BuildahFramework:
-		{
-			cli = buildah.Client{
-				Logger: logger,
-			}
-
-			ociBuilderSpec.Daemon = false
-		}
-
-	default:
-		{
-			return errors.New("invalid builder specified, try --builder=docker or --builder=buildah")
-		}
-
+	cli, err := newBuilderClient(v1alpha1.Framework(builderType), ociBuilderSpec, logger)
+	if err != nil {
+		return err
 	}
+	ociBuilderSpec.Daemon = v1alpha1.Framework(builderType) == v1alpha1.DockerFramework
 
 	builder := oci.Builder{
 		Logger: logger,
 		Client: cli,
 	}
 
+	if pushesOnBuild(v1alpha1.Framework(builderType)) && len(ociBuilderSpec.Login) > 0 {
+		if err := login(&builder, ociBuilderSpec); err != nil {
+			logger.WithError(err).Errorln("failed to login to registries before building")
+			return err
+		}
+	}
+
 	res := make(chan v1alpha1.OCIBuildResponse)
 	errChan := make(chan error)
 	finished := make(chan bool)
@@ -147,7 +121,7 @@ func (b *buildCmd) run(args []string) error {
 		case buildResponse := <-res:
 			{
 				logger.Infoln("executing build step")
-				if builderType == "docker" {
+				if jsonBuildOutput(v1alpha1.Framework(builderType)) {
 					if err := utils.OutputJson(buildResponse.Body); err != nil {
 						return err
 					}
@@ -172,3 +146,23 @@ func (b *buildCmd) run(args []string) error {
 	}
 
 }
+
+// login logs into all registries of the spec, for builders which push images as part of the build
+func login(builder *oci.Builder, spec v1alpha1.OCIBuilderSpec) error {
+	res := make(chan v1alpha1.OCILoginResponse)
+	errChan := make(chan error)
+	finished := make(chan bool)
+
+	go builder.Login(spec, res, errChan, finished)
+
+	for {
+		select {
+		case err := <-errChan:
+			return err
+		case loginResponse := <-res:
+			builder.Logger.WithField("status", loginResponse.Status).Debugln("login step complete")
+		case <-finished:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/buildah"
+	"github.com/ocibuilder/ocibuilder/pkg/buildkit"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
+	"github.com/ocibuilder/ocibuilder/pkg/context"
+	"github.com/ocibuilder/ocibuilder/pkg/docker"
+	"github.com/ocibuilder/ocibuilder/pkg/kaniko"
+	"github.com/ocibuilder/ocibuilder/pkg/podman"
+	"github.com/ocibuilder/ocibuilder/pkg/registry"
+	"github.com/sirupsen/logrus"
+)
+
+// builderFlagDesc is the description of the --builder flag shared by all commands
+const builderFlagDesc = "Choose either docker, buildah, podman, kaniko or buildkit as the targeted image builder, or registry to pull and push images without a builder. By default the builder is docker."
+
+// newBuilderClient returns the builder client for the passed in framework, configured by the builder spec
+func newBuilderClient(framework v1alpha1.Framework, spec v1alpha1.OCIBuilderSpec, logger *logrus.Logger) (v1alpha1.BuilderClient, error) {
+	switch framework {
+
+	case v1alpha1.DockerFramework:
+		{
+			apiClient, err := newDockerAPIClient(spec.Docker)
+			if err != nil {
+				log.WithError(err).Errorln("failed to fetch docker api client")
+				return nil, err
+			}
+
+			return docker.Client{
+				APIClient: apiClient,
+				Logger:    logger,
+			}, nil
+		}
+
+	case v1alpha1.BuildahFramework:
+		{
+			return buildah.Client{
+				Logger:  logger,
+				Options: spec.Buildah,
+			}, nil
+		}
+
+	case v1alpha1.PodmanFramework:
+		{
+			return podman.Client{
+				Logger: logger,
+			}, nil
+		}
+
+	case v1alpha1.KanikoFramework:
+		{
+			return kaniko.Client{
+				Logger: logger,
+			}, nil
+		}
+
+	case v1alpha1.BuildKitFramework:
+		{
+			// buildctl targets the buildkitd instance at $BUILDKIT_HOST
+			return buildkit.Client{
+				Logger: logger,
+			}, nil
+		}
+
+	case v1alpha1.RegistryFramework:
+		{
+			cli, err := registry.NewClient(spec.Login, spec.Registry, logger)
+			if err != nil {
+				log.WithError(err).Errorln("failed to create registry client")
+				return nil, err
+			}
+			// images are pulled into and pushed from an image layout in the home directory by default
+			if cli.LayoutPath == "" {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return nil, err
+				}
+				cli.LayoutPath = filepath.Join(home, common.ImageLayoutDirectory)
+			}
+			return cli, nil
+		}
+
+	default:
+		{
+			return nil, errors.New("invalid builder specified, try --builder=docker, --builder=buildah, --builder=podman, --builder=kaniko, --builder=buildkit or --builder=registry")
+		}
+
+	}
+}
+
+// newDockerAPIClient returns the docker API client of the docker spec. TLS credentials stored in kubernetes
+// secrets are read with the kubernetes client of the kubeconfig at $KUBE_CONFIG.
+func newDockerAPIClient(spec *v1alpha1.DockerSpec) (*client.Client, error) {
+	k8sClient, err := context.NewK8sClient(os.Getenv(common.EnvVarKubeConfig))
+	if err != nil {
+		return nil, err
+	}
+	return docker.NewAPIClient(spec, k8sClient)
+}
+
+// jsonOutput returns whether the responses of the builder client of a framework are streams of docker json messages
+func jsonOutput(framework v1alpha1.Framework) bool {
+	return framework == v1alpha1.DockerFramework || framework == v1alpha1.BuildKitFramework || framework == v1alpha1.RegistryFramework
+}
+
+// jsonBuildOutput returns whether the build responses of the builder client of a framework are streams of docker
+// json messages
+func jsonBuildOutput(framework v1alpha1.Framework) bool {
+	return jsonOutput(framework) || framework == v1alpha1.BuildahFramework
+}
+
+// pushesOnBuild returns whether the builder client of a framework pushes images as part of the build, and so
+// must be logged in to registries before building
+func pushesOnBuild(framework v1alpha1.Framework) bool {
+	return framework == v1alpha1.KanikoFramework || framework == v1alpha1.BuildKitFramework
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/oci"
+	"github.com/ocibuilder/ocibuilder/pkg/read"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const exportDesc = `
+This command exports a built image from the docker daemon or buildah storage to the local filesystem, without
+pushing it to a registry.
+
+The image can be exported as an OCI image layout directory (oci), a tarball of an OCI image layout (oci-archive)
+or a tarball in the docker save format (docker-archive).
+
+e.g. ocictl export --name myimage/cool-image:0.0.1 --format oci-archive --output ./cool-image.tar
+`
+
+type exportCmd struct {
+	out     io.Writer
+	name    string
+	format  string
+	output  string
+	path    string
+	builder string
+	debug   bool
+}
+
+func newExportCmd(out io.Writer) *cobra.Command {
+	ec := &exportCmd{out: out}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "exports a built image to an OCI image layout or image archive",
+		Long:  exportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ec.run(args)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&ec.name, "name", "i", "", "Specify the name of the image you want to export")
+	f.StringVarP(&ec.format, "format", "f", string(v1alpha1.OCIArchiveExportFormat), "The export format, one of oci, oci-archive or docker-archive")
+	f.StringVarP(&ec.output, "output", "o", "", "Path to export the image to, a directory for the oci format and a file for archive formats")
+	f.StringVarP(&ec.path, "path", "p", "", "Path to your ocibuilder.yaml. By default will look in the current working directory")
+	f.StringVarP(&ec.builder, "builder", "b", "docker", builderFlagDesc)
+	f.BoolVarP(&ec.debug, "debug", "d", false, "Turn on debug logging")
+	return cmd
+}
+
+func (e *exportCmd) run(args []string) error {
+	logger := util.GetLogger(e.debug)
+
+	if e.name == "" {
+		return errors.New("the name of the image to export must be specified with --name")
+	}
+
+	reader := read.Reader{Logger: logger}
+	ociBuilderSpec := v1alpha1.OCIBuilderSpec{Daemon: true}
+	if err := reader.Read(&ociBuilderSpec, "", e.path); err != nil {
+		log.WithError(err).Errorln("failed to read spec")
+		return err
+	}
+
+	// Prioritise builder passed in as argument, default builder is docker
+	builderType := e.builder
+	if !ociBuilderSpec.Daemon && builderType == "docker" {
+		builderType = "buildah"
+	}
+
+	cli, err := newBuilderClient(v1alpha1.Framework(builderType), ociBuilderSpec, logger)
+	if err != nil {
+		return err
+	}
+
+	builder := oci.Builder{
+		Logger: logger,
+		Client: cli,
+	}
+
+	exportSpec := v1alpha1.ExportSpec{
+		Format: v1alpha1.ExportFormat(e.format),
+		Path:   e.output,
+	}
+
+	if err := builder.Export(e.name, []v1alpha1.ExportSpec{exportSpec}); err != nil {
+		logger.WithError(err).Errorln("error exporting image")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/oci"
+	"github.com/ocibuilder/ocibuilder/pkg/read"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+const loadDesc = `
+This command loads an image from the local filesystem into the docker daemon or buildah storage, so that offline
+environments can be seeded with base images before a build.
+
+The input can be an OCI image layout directory (oci), a tarball of an OCI image layout (oci-archive) or a tarball in
+the docker save format (docker-archive). If no format is passed in, it is detected from the input.
+
+e.g. ocictl load --input ./cool-image.tar
+`
+
+type loadCmd struct {
+	out     io.Writer
+	input   string
+	format  string
+	path    string
+	builder string
+	debug   bool
+}
+
+func newLoadCmd(out io.Writer) *cobra.Command {
+	lc := &loadCmd{out: out}
+	cmd := &cobra.Command{
+		Use:   "load",
+		Short: "loads an image from an OCI image layout or image archive",
+		Long:  loadDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lc.run(args)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&lc.input, "input", "i", "", "Path of the image to load")
+	f.StringVarP(&lc.format, "format", "f", "", "The format of the image, one of oci, oci-archive or docker-archive. By default the format is detected from the input.")
+	f.StringVarP(&lc.path, "path", "p", "", "Path to your ocibuilder.yaml. By default will look in the current working directory")
+	f.StringVarP(&lc.builder, "builder", "b", "docker", builderFlagDesc)
+	f.BoolVarP(&lc.debug, "debug", "d", false, "Turn on debug logging")
+	return cmd
+}
+
+func (l *loadCmd) run(args []string) error {
+	logger := util.GetLogger(l.debug)
+
+	if l.input == "" {
+		return errors.New("the path of the image to load must be specified with --input")
+	}
+
+	reader := read.Reader{Logger: logger}
+	ociBuilderSpec := v1alpha1.OCIBuilderSpec{Daemon: true}
+	if err := reader.Read(&ociBuilderSpec, "", l.path); err != nil {
+		log.WithError(err).Errorln("failed to read spec")
+		return err
+	}
+
+	// Prioritise builder passed in as argument, default builder is docker
+	builderType := l.builder
+	if !ociBuilderSpec.Daemon && builderType == "docker" {
+		builderType = "buildah"
+	}
+
+	cli, err := newBuilderClient(v1alpha1.Framework(builderType), ociBuilderSpec, logger)
+	if err != nil {
+		return err
+	}
+
+	builder := oci.Builder{
+		Logger: logger,
+		Client: cli,
+	}
+
+	loadSpec := v1alpha1.LoadSpec{
+		Input:  l.input,
+		Format: v1alpha1.ExportFormat(l.format),
+	}
+
+	if err := builder.Load([]v1alpha1.LoadSpec{loadSpec}); err != nil {
+		logger.WithError(err).Errorln("error loading image")
+		return err
+	}
+	return nil
+}
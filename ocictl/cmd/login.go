@@ -17,14 +17,10 @@ limitations under the License.
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"io"
 
-	"github.com/docker/docker/client"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
-	"github.com/ocibuilder/ocibuilder/pkg/buildah"
-	"github.com/ocibuilder/ocibuilder/pkg/docker"
 	"github.com/ocibuilder/ocibuilder/pkg/oci"
 	"github.com/ocibuilder/ocibuilder/pkg/read"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
@@ -55,13 +51,12 @@ func newLoginCmd(out io.Writer) *cobra.Command {
 	}
 	f := cmd.Flags()
 	f.StringVarP(&lc.path, "path", "p", "", "Path to your ocibuilder.yaml or login.yaml. By default will look in the current working directory")
-	f.StringVarP(&lc.builder, "builder", "b", "docker", "Choose either docker and buildah as the targeted image builder. By default the builder is docker.")
+	f.StringVarP(&lc.builder, "builder", "b", "docker", builderFlagDesc)
 	f.BoolVarP(&lc.debug, "debug", "d", false, "Turn on debug logging")
 	return cmd
 }
 
 func (l *loginCmd) run(args []string) error {
-	var cli v1alpha1.BuilderClient
 	logger := util.GetLogger(l.debug)
 	reader := read.Reader{Logger: logger}
 	ociBuilderSpec := v1alpha1.OCIBuilderSpec{Daemon: true}
@@ -73,38 +68,13 @@ func (l *loginCmd) run(args []string) error {
 
 	// Prioritise builder passed in as argument, default builder is docker
 	builderType := l.builder
-	if !ociBuilderSpec.Daemon {
+	if !ociBuilderSpec.Daemon && builderType == "docker" {
 		builderType = "buildah"
 	}
 
-	switch v1alpha1.Framework(builderType) {
-
-	case v1alpha1.DockerFramework:
-		{
-			apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-			if err != nil {
-				log.WithError(err).Errorln("failed to fetch docker api client")
-				return err
-			}
-
-			cli = docker.Client{
-				APIClient: apiClient,
-				Logger:    logger,
-			}
-		}
-
-	case v1alpha1.BuildahFramework:
-		{
-			cli = buildah.Client{
-				Logger: logger,
-			}
-		}
-
-	default:
-		{
-			return errors.New("invalid builder specified, try --builder=docker or --builder=buildah")
-		}
-
+	cli, err := newBuilderClient(v1alpha1.Framework(builderType), ociBuilderSpec, logger)
+	if err != nil {
+		return err
 	}
 
 	builder := oci.Builder{
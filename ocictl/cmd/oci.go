@@ -50,6 +50,8 @@ func NewRootCmd(args []string) *cobra.Command {
 		newLoginCmd(out),
 		newPullCmd(out),
 		newPushCmd(out),
+		newExportCmd(out),
+		newLoadCmd(out),
 		newVersionCmd(out),
 		newInitCmd(out),
 		newSignCmd(out),
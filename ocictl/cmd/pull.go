@@ -17,44 +17,44 @@ limitations under the License.
 package cmd
 
 import (
-	"errors"
 	"io"
 
 	"github.com/ocibuilder/ocibuilder/pkg/oci"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
 
-	"github.com/docker/docker/client"
 	"github.com/ocibuilder/ocibuilder/ocictl/pkg/utils"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
-	"github.com/ocibuilder/ocibuilder/pkg/buildah"
-	"github.com/ocibuilder/ocibuilder/pkg/docker"
 	"github.com/ocibuilder/ocibuilder/pkg/read"
 	"github.com/spf13/cobra"
 )
 
 const pullDesc = `
-This command pulls an image that you have passed in by name. The name should include the path to the image but not
-the image registry itself.
+This command pulls the images in the pull specification of your ocibuilder.yaml. Each image is pulled from its registry
+with the credentials of the matching login specification, and images which are already present are skipped unless
+their pull policy is Always.
 
-e.g. myimage/cool-image:0.0.1
+A single image can be pulled instead by passing in its name, optionally with a registry.
 
-The pull command looks to pull from any registries that have been specified in the login specification. Once the image has
-been found in any of the specified registries, a pull is executed.
+e.g. ocictl pull --name myimage/cool-image:0.0.1 --registry example-registry
+
+With --builder=registry, images are pulled over the registry HTTP API without a builder into an OCI image layout in
+~/.ocibuilder/images, where they can be pushed from with the registry builder.
 `
 
 type pullCmd struct {
-	out     io.Writer
-	name    string
-	path    string
-	builder string
-	debug   bool
+	out      io.Writer
+	name     string
+	registry string
+	path     string
+	builder  string
+	debug    bool
 }
 
 func newPullCmd(out io.Writer) *cobra.Command {
 	pc := &pullCmd{out: out}
 	cmd := &cobra.Command{
 		Use:   "pull",
-		Short: "pulls an image passed in with the name flag",
+		Short: "pulls the images in the pull specification or an image passed in with the name flag",
 		Long:  pullDesc,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return pc.run(args)
@@ -62,14 +62,14 @@ func newPullCmd(out io.Writer) *cobra.Command {
 	}
 	f := cmd.Flags()
 	f.StringVarP(&pc.name, "name", "i", "", "Specify the name of the image you want to pull")
+	f.StringVarP(&pc.registry, "registry", "r", "", "Specify the registry of the image passed in with the name flag")
 	f.StringVarP(&pc.path, "path", "p", "", "Path to your ocibuilder.yaml. By default will look in the current working directory")
-	f.StringVarP(&pc.builder, "builder", "b", "docker", "Choose either docker and buildah as the targeted image builder. By default the builder is docker.")
+	f.StringVarP(&pc.builder, "builder", "b", "docker", builderFlagDesc)
 	f.BoolVarP(&pc.debug, "debug", "d", false, "Turn on debug logging")
 	return cmd
 }
 
 func (p *pullCmd) run(args []string) error {
-	var cli v1alpha1.BuilderClient
 	logger := util.GetLogger(p.debug)
 	reader := read.Reader{Logger: logger}
 	ociBuilderSpec := v1alpha1.OCIBuilderSpec{Daemon: true}
@@ -81,38 +81,13 @@ func (p *pullCmd) run(args []string) error {
 
 	// Prioritise builder passed in as argument, default builder is docker
 	builderType := p.builder
-	if !ociBuilderSpec.Daemon {
+	if !ociBuilderSpec.Daemon && builderType == "docker" {
 		builderType = "buildah"
 	}
 
-	switch v1alpha1.Framework(builderType) {
-
-	case v1alpha1.DockerFramework:
-		{
-			apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-			if err != nil {
-				log.WithError(err).Errorln("failed to fetch docker api client")
-				return err
-			}
-
-			cli = docker.Client{
-				APIClient: apiClient,
-				Logger:    logger,
-			}
-		}
-
-	case v1alpha1.BuildahFramework:
-		{
-			cli = buildah.Client{
-				Logger: logger,
-			}
-		}
-
-	default:
-		{
-			return errors.New("invalid builder specified, try --builder=docker or --builder=buildah")
-		}
-
+	cli, err := newBuilderClient(v1alpha1.Framework(builderType), ociBuilderSpec, logger)
+	if err != nil {
+		return err
 	}
 
 	builder := oci.Builder{
@@ -129,7 +104,16 @@ func (p *pullCmd) run(args []string) error {
 		close(errChan)
 	}()
 
-	go builder.Pull(ociBuilderSpec, p.name, res, errChan, finished)
+	pulls := ociBuilderSpec.Pull
+	if p.name != "" {
+		pulls = []v1alpha1.PullSpec{{
+			Image:    p.name,
+			Registry: p.registry,
+			Policy:   v1alpha1.PullAlways,
+		}}
+	}
+
+	go builder.Pull(ociBuilderSpec, pulls, res, errChan, finished)
 
 	for {
 		select {
@@ -145,7 +129,7 @@ func (p *pullCmd) run(args []string) error {
 		case pullResponse := <-res:
 			{
 				logger.Infoln("executing pull step")
-				if builderType == "docker" {
+				if jsonOutput(v1alpha1.Framework(builderType)) {
 					if err := utils.OutputJson(pullResponse.Body); err != nil {
 						return err
 					}
@@ -17,16 +17,15 @@ limitations under the License.
 package cmd
 
 import (
-	"errors"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 
 	"github.com/ocibuilder/ocibuilder/ocictl/pkg/utils"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
 
-	"github.com/docker/docker/client"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
-	"github.com/ocibuilder/ocibuilder/pkg/buildah"
-	"github.com/ocibuilder/ocibuilder/pkg/docker"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
 	"github.com/ocibuilder/ocibuilder/pkg/oci"
 	"github.com/ocibuilder/ocibuilder/pkg/read"
 	"github.com/spf13/cobra"
@@ -42,13 +41,18 @@ authentication passed in the login spec.
 The registry, image and tag are used to create a full qualified image path
 
 e.g. my-image-registry.docker.com:1111/myimage/cool-image:0.0.1
+
+With --builder=registry, images previously pulled with the registry builder are pushed over the registry HTTP API without
+a builder.
 `
 
 type pushCmd struct {
-	out     io.Writer
-	path    string
-	builder string
-	debug   bool
+	out         io.Writer
+	path        string
+	builder     string
+	debug       bool
+	digestFile  string
+	parallelism int
 }
 
 func newPushCmd(out io.Writer) *cobra.Command {
@@ -63,13 +67,14 @@ func newPushCmd(out io.Writer) *cobra.Command {
 	}
 	f := cmd.Flags()
 	f.StringVarP(&pc.path, "path", "p", "", "Path to your ocibuilder.yaml or push.yaml. By default will look in the current working directory")
-	f.StringVarP(&pc.builder, "builder", "b", "docker", "Choose either docker and buildah as the targeted image builder. By default the builder is docker.")
+	f.StringVarP(&pc.builder, "builder", "b", "docker", builderFlagDesc)
 	f.BoolVarP(&pc.debug, "debug", "d", false, "Turn on debug logging")
+	f.StringVar(&pc.digestFile, "digest-file", "", "Path to write the digests of all pushed images to as json")
+	f.IntVar(&pc.parallelism, "parallelism", common.DefaultPushParallelism, "The maximum number of images to push concurrently")
 	return cmd
 }
 
 func (p *pushCmd) run(args []string) error {
-	var cli v1alpha1.BuilderClient
 	logger := util.GetLogger(p.debug)
 	reader := read.Reader{Logger: logger}
 	ociBuilderSpec := v1alpha1.OCIBuilderSpec{Daemon: true}
@@ -81,43 +86,19 @@ func (p *pushCmd) run(args []string) error {
 
 	// Prioritise builder passed in as argument, default builder is docker
 	builderType := p.builder
-	if !ociBuilderSpec.Daemon {
+	if !ociBuilderSpec.Daemon && builderType == "docker" {
 		builderType = "buildah"
 	}
 
-	switch v1alpha1.Framework(builderType) {
-
-	case v1alpha1.DockerFramework:
-		{
-			apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-			if err != nil {
-				log.WithError(err).Errorln("failed to fetch docker api client")
-				return err
-			}
-
-			cli = docker.Client{
-				APIClient: apiClient,
-				Logger:    logger,
-			}
-		}
-
-	case v1alpha1.BuildahFramework:
-		{
-			cli = buildah.Client{
-				Logger: logger,
-			}
-		}
-
-	default:
-		{
-			return errors.New("invalid builder specified, try --builder=docker or --builder=buildah")
-		}
-
+	cli, err := newBuilderClient(v1alpha1.Framework(builderType), ociBuilderSpec, logger)
+	if err != nil {
+		return err
 	}
 
 	builder := oci.Builder{
-		Logger: logger,
-		Client: cli,
+		Logger:          logger,
+		Client:          cli,
+		PushParallelism: p.parallelism,
 	}
 
 	res := make(chan v1alpha1.OCIPushResponse)
@@ -138,6 +119,12 @@ func (p *pushCmd) run(args []string) error {
 			{
 				if err != nil {
 					logger.WithError(err).Errorln("error received from error channel whilst pushing")
+					// the digests of the images pushed before the failure are still written
+					if p.digestFile != "" && len(builder.PushResults) > 0 {
+						if err := writeDigestFile(p.digestFile, builder.PushResults); err != nil {
+							logger.WithError(err).Errorln("failed to write digests of pushed images")
+						}
+					}
 					return err
 				}
 			}
@@ -145,7 +132,7 @@ func (p *pushCmd) run(args []string) error {
 		case pushResponse := <-res:
 			{
 				logger.Infoln("executing push step")
-				if builderType == "docker" {
+				if jsonOutput(v1alpha1.Framework(builderType)) {
 					if err := utils.OutputJson(pushResponse.Body); err != nil {
 						return err
 					}
@@ -156,13 +143,16 @@ func (p *pushCmd) run(args []string) error {
 				}
 				pushResponse.Finished = true
 				res <- pushResponse
-				logger.Infoln("push step complete")
+				logger.WithField("digest", pushResponse.Digest).Infoln("push step complete")
 			}
 
 		case <-finished:
 			{
 				logger.Infoln("all push steps complete")
 				close(finished)
+				if p.digestFile != "" {
+					return writeDigestFile(p.digestFile, builder.PushResults)
+				}
 				return nil
 			}
 
@@ -170,3 +160,17 @@ func (p *pushCmd) run(args []string) error {
 	}
 
 }
+
+// writeDigestFile writes the results of all pushed images to a json file at path
+func writeDigestFile(path string, results []v1alpha1.PushResult) error {
+	digests, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, digests, 0644); err != nil {
+		log.WithError(err).Errorln("failed to write digest file")
+		return err
+	}
+	log.WithField("path", path).Infoln("push digests written to file")
+	return nil
+}
@@ -21,7 +21,6 @@ import (
 	"io"
 	"io/ioutil"
 
-	"github.com/docker/docker/client"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/crypto"
 	"github.com/ocibuilder/ocibuilder/pkg/docker"
@@ -69,7 +68,7 @@ func (sc *signCmd) run(args []string) error {
 		return err
 	}
 
-	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	apiClient, err := newDockerAPIClient(ociBuilderSpec.Docker)
 	if err != nil {
 		logger.WithError(err).Errorln("failed to fetch docker api client")
 		return err
@@ -29,8 +29,14 @@ type BuilderClient interface {
 	ImagePull(options OCIPullOptions) (OCIPullResponse, error)
 	ImagePush(options OCIPushOptions) (OCIPushResponse, error)
 	ImageRemove(options OCIRemoveOptions) (OCIRemoveResponse, error)
+	ImageTag(options OCITagOptions) (OCITagResponse, error)
+	ImagesPrune(options OCIPruneOptions) (OCIPruneResponse, error)
 	ImageInspect(imageId string) (types.ImageInspect, error)
 	ImageHistory(imageId string) ([]image.HistoryResponseItem, error)
+	ImageSave(options OCISaveOptions) (OCISaveResponse, error)
+	ImageLoad(options OCILoadOptions) (OCILoadResponse, error)
+	ContainerRun(options OCIRunOptions) (OCIRunResponse, error)
 	RegistryLogin(options OCILoginOptions) (OCILoginResponse, error)
 	GenerateAuthRegistryString(auth types.AuthConfig) string
+	Capabilities() BuilderCapabilities
 }
@@ -29,41 +29,68 @@ import (
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AliyunOSSContext":      schema_pkg_apis_ocibuilder_v1alpha1_AliyunOSSContext(ref),
-		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleGalaxy":         schema_pkg_apis_ocibuilder_v1alpha1_AnsibleGalaxy(ref),
-		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleLocal":          schema_pkg_apis_ocibuilder_v1alpha1_AnsibleLocal(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleStep":           schema_pkg_apis_ocibuilder_v1alpha1_AnsibleStep(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AzureBlobContext":      schema_pkg_apis_ocibuilder_v1alpha1_AzureBlobContext(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base":                  schema_pkg_apis_ocibuilder_v1alpha1_Base(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildContext":          schema_pkg_apis_ocibuilder_v1alpha1_BuildContext(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildGenTemplate":      schema_pkg_apis_ocibuilder_v1alpha1_BuildGenTemplate(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildProvenance":       schema_pkg_apis_ocibuilder_v1alpha1_BuildProvenance(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildSpec":             schema_pkg_apis_ocibuilder_v1alpha1_BuildSpec(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildStep":             schema_pkg_apis_ocibuilder_v1alpha1_BuildStep(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplate":         schema_pkg_apis_ocibuilder_v1alpha1_BuildTemplate(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep":     schema_pkg_apis_ocibuilder_v1alpha1_BuildTemplateStep(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildahOptions":        schema_pkg_apis_ocibuilder_v1alpha1_BuildahOptions(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuilderCapabilities":   schema_pkg_apis_ocibuilder_v1alpha1_BuilderCapabilities(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Command":               schema_pkg_apis_ocibuilder_v1alpha1_Command(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.CommandTest":           schema_pkg_apis_ocibuilder_v1alpha1_CommandTest(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials":           schema_pkg_apis_ocibuilder_v1alpha1_Credentials(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DistrolessConfig":      schema_pkg_apis_ocibuilder_v1alpha1_DistrolessConfig(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerSpec":            schema_pkg_apis_ocibuilder_v1alpha1_DockerSpec(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerStep":            schema_pkg_apis_ocibuilder_v1alpha1_DockerStep(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerTLS":             schema_pkg_apis_ocibuilder_v1alpha1_DockerTLS(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds":              schema_pkg_apis_ocibuilder_v1alpha1_EnvCreds(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ExportSpec":            schema_pkg_apis_ocibuilder_v1alpha1_ExportSpec(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.FileTest":              schema_pkg_apis_ocibuilder_v1alpha1_FileTest(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GCSContext":            schema_pkg_apis_ocibuilder_v1alpha1_GCSContext(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GenerateTemplate":      schema_pkg_apis_ocibuilder_v1alpha1_GenerateTemplate(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitContext":            schema_pkg_apis_ocibuilder_v1alpha1_GitContext(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitRemoteConfig":       schema_pkg_apis_ocibuilder_v1alpha1_GitRemoteConfig(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Grafeas":               schema_pkg_apis_ocibuilder_v1alpha1_Grafeas(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageBuildArgs":        schema_pkg_apis_ocibuilder_v1alpha1_ImageBuildArgs(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageMetadata":         schema_pkg_apis_ocibuilder_v1alpha1_ImageMetadata(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageTest":             schema_pkg_apis_ocibuilder_v1alpha1_ImageTest(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.K8sCreds":              schema_pkg_apis_ocibuilder_v1alpha1_K8sCreds(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.KubeSecretCredentials": schema_pkg_apis_ocibuilder_v1alpha1_KubeSecretCredentials(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoadSpec":              schema_pkg_apis_ocibuilder_v1alpha1_LoadSpec(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LocalContext":          schema_pkg_apis_ocibuilder_v1alpha1_LocalContext(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoginSpec":             schema_pkg_apis_ocibuilder_v1alpha1_LoginSpec(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Metadata":              schema_pkg_apis_ocibuilder_v1alpha1_Metadata(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.MetadataTest":          schema_pkg_apis_ocibuilder_v1alpha1_MetadataTest(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.NodeStatus":            schema_pkg_apis_ocibuilder_v1alpha1_NodeStatus(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Notes":                 schema_pkg_apis_ocibuilder_v1alpha1_Notes(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilder":            schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilder(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderList":        schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderList(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderSpec":        schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderSpec(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderStatus":      schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderStatus(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Package":               schema_pkg_apis_ocibuilder_v1alpha1_Package(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PackagesStep":          schema_pkg_apis_ocibuilder_v1alpha1_PackagesStep(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Param":                 schema_pkg_apis_ocibuilder_v1alpha1_Param(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds":            schema_pkg_apis_ocibuilder_v1alpha1_PlainCreds(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PullSpec":              schema_pkg_apis_ocibuilder_v1alpha1_PullSpec(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PushResult":            schema_pkg_apis_ocibuilder_v1alpha1_PushResult(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PushSpec":              schema_pkg_apis_ocibuilder_v1alpha1_PushSpec(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryCreds":         schema_pkg_apis_ocibuilder_v1alpha1_RegistryCreds(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryOptions":       schema_pkg_apis_ocibuilder_v1alpha1_RegistryOptions(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RemoteCreds":           schema_pkg_apis_ocibuilder_v1alpha1_RemoteCreds(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket":              schema_pkg_apis_ocibuilder_v1alpha1_S3Bucket(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Context":             schema_pkg_apis_ocibuilder_v1alpha1_S3Context(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.SignKey":               schema_pkg_apis_ocibuilder_v1alpha1_SignKey(ref),
 		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Stage":                 schema_pkg_apis_ocibuilder_v1alpha1_Stage(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.StageGenTemplate":      schema_pkg_apis_ocibuilder_v1alpha1_StageGenTemplate(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.StoreConfig":           schema_pkg_apis_ocibuilder_v1alpha1_StoreConfig(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateInput":         schema_pkg_apis_ocibuilder_v1alpha1_TemplateInput(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateRef":           schema_pkg_apis_ocibuilder_v1alpha1_TemplateRef(ref),
+		"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateSource":        schema_pkg_apis_ocibuilder_v1alpha1_TemplateSource(ref),
 	}
 }
 
@@ -108,79 +135,38 @@ func schema_pkg_apis_ocibuilder_v1alpha1_AliyunOSSContext(ref common.ReferenceCa
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_AnsibleGalaxy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_AnsibleStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "AnsibleGalaxy contains the information about the role to install through galaxy",
+				Description: "AnsibleStep represents an ansible install  within a build",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"requirements": {
+					"playbook": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requirements refer to the requirements.yaml file",
+							Description: "Playbook refers to playbook.yaml file",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"name": {
+					"requirements": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the galaxy role",
+							Description: "Requirements refer to the requirements.yaml file",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-				Required: []string{"name"},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_ocibuilder_v1alpha1_AnsibleLocal(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "AnsibleLocal contains information to install a ansible role through local playbook",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"playbook": {
+					"workspace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Playbook refers to playbook.yaml file",
+							Description: "Workspace is the name of your ansible workspce NOT including /etc/ansible/ ansible path",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"playbook"},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_ocibuilder_v1alpha1_AnsibleStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "AnsibleStep represents an ansible install  within a build",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"local": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Local contains information to install a ansible role through local playbook",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleLocal"),
-						},
-					},
-					"galaxy": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Galaxy contains information to install a ansible role through ansible-galaxy",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleGalaxy"),
-						},
-					},
-				},
+				Required: []string{"playbook", "workspace"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleGalaxy", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleLocal"},
 	}
 }
 
@@ -303,6 +289,130 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildContext(ref common.ReferenceCallba
 	}
 }
 
+func schema_pkg_apis_ocibuilder_v1alpha1_BuildGenTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BuildGenTemplate is the template for a build template in docker generate",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"Name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"Cmds": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"Name", "Cmds"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_BuildProvenance(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BuildProvenance represents build image metadata",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"buildFile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildFile is the path to the buildfile that was used for the image build",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"contextDirectory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ContextDirectory is the path to the build context",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"daemon": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Daemon is whether the daemon was used to build or not (Docker or Buildah)",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"createTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time at which the build was created.",
+							Type:        []string{"string"},
+							Format:      "date-time",
+						},
+					},
+					"startTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time at which execution of the build was started.",
+							Type:        []string{"string"},
+							Format:      "date-time",
+						},
+					},
+					"endTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time at which execution of the build was finished.",
+							Type:        []string{"string"},
+							Format:      "date-time",
+						},
+					},
+					"creator": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Creator is the email of the build creator",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source is the URI of the source code for the build",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the image name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tag is the image tag",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"id": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ID is the ID of the image",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"buildFile", "contextDirectory", "daemon"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_ocibuilder_v1alpha1_BuildSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -346,12 +456,62 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
+					"storageDriver": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageDriver is the storage driver flag (default overlay2) see https://docs.docker.com/storage/storagedriver/select-storage-driver/",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"preload": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Preload are images loaded from the local filesystem into the builder before any build steps run",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoadSpec"),
+									},
+								},
+							},
+						},
+					},
+					"pullBaseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PullBaseImages pulls the base image of every stage before any build steps run defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"templateSources": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "TemplateSources are shared build templates imported from git repositories or urls. Templates of the spec take precedence over imported templates with the same name, and templates of a source take precedence over templates with the same name of the sources listed before it",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateSource"),
+									},
+								},
+							},
+						},
+					},
 				},
-				Required: []string{"templates", "steps"},
+				Required: []string{"templates", "steps", "storageDriver"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplate"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplate", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoadSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateSource"},
 	}
 }
 
@@ -362,13 +522,6 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildStep(ref common.ReferenceCallback)
 				Description: "BuildStep represents a step within the build",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"daemon": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Type of the build framework. Defaults to docker",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
 					"stages": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -396,11 +549,30 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildStep(ref common.ReferenceCallback)
 					},
 					"tag": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tag the tag of the build",
+							Description: "Tag the tag of the build, this can be a go template e.g. {{ .Git.ShortSHA }}",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"tags": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Tags are additional tags of the build which are applied alongside Tag",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 					"distroless": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Distroless if set to true generates a distroless image",
@@ -408,6 +580,12 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildStep(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"distrolessConfig": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DistrolessConfig configures the final distroless stage when Distroless is set",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DistrolessConfig"),
+						},
+					},
 					"cache": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Cache for build Set to false by default",
@@ -415,6 +593,13 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildStep(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"cacheRepo": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CacheRepo is the repository cached layers are pushed to and pulled from, used for Kaniko builds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"purge": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Purge the build defaults to false",
@@ -428,12 +613,36 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildStep(ref common.ReferenceCallback)
 							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildContext"),
 						},
 					},
+					"export": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Export writes the built image to the local filesystem in the specified formats",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ExportSpec"),
+									},
+								},
+							},
+						},
+					},
+					"test": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Test contains structure tests which the built image must pass before it can be pushed",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageTest"),
+						},
+					},
 				},
 				Required: []string{"stages"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildContext", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Stage"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildContext", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DistrolessConfig", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ExportSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageTest", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Stage"},
 	}
 }
 
@@ -469,12 +678,30 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildTemplate(ref common.ReferenceCallb
 							},
 						},
 					},
+					"inputs": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Inputs are the inputs of the template, which are substituted into the cmds of the template as go template values e.g. {{ .goVersion }}",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateInput"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"name", "cmd"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateInput"},
 	}
 }
 
@@ -497,64 +724,49 @@ func schema_pkg_apis_ocibuilder_v1alpha1_BuildTemplateStep(ref common.ReferenceC
 							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleStep"),
 						},
 					},
+					"packages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Packages represents a package installation step within build template steps",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PackagesStep"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerStep"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PackagesStep"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_Command(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_BuildahOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Command Represents a single line in a Dockerfile",
+				Description: "BuildahOptions contains the options for running buildah, such as running rootless in CI",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"cmd": {
+					"isolation": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Cmd lowercased command name (e.g `from`)",
+							Description: "Isolation of RUN instructions, one of oci, rootless or chroot defaults to the buildah default",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"subCmd": {
+					"userns": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SubCmd for ONBUILD only this holds the sub-command",
+							Description: "UserNS is the user namespace of RUN instructions, e.g. host, auto or the path of a namespace",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"isJSON": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Json bool for whether the value is written in json",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"original": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Original is the original source line",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"startLine": {
-						SchemaProps: spec.SchemaProps{
-							Description: "StartLine is the original source line number",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"flags": {
+					"uidMap": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
 								"x-kubernetes-list-type": "map",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "Flags such as `--from=...` for `COPY`.",
+							Description: "UIDMap maps container UIDs to host UIDs in a new user namespace, in the form container-uid:host-uid:size",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -566,14 +778,14 @@ func schema_pkg_apis_ocibuilder_v1alpha1_Command(ref common.ReferenceCallback) c
 							},
 						},
 					},
-					"value": {
+					"gidMap": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
 								"x-kubernetes-list-type": "map",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "Value is the contents of the command (e.g `ubuntu:xenial`)",
+							Description: "GIDMap maps container GIDs to host GIDs in a new user namespace, in the form container-gid:host-gid:size",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -585,262 +797,158 @@ func schema_pkg_apis_ocibuilder_v1alpha1_Command(ref common.ReferenceCallback) c
 							},
 						},
 					},
-				},
-				Required: []string{"cmd", "subCmd", "isJSON", "original", "startLine", "flags", "value"},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_ocibuilder_v1alpha1_Credentials(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "Credentials encapsulates different ways of storing the credentials",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"plain": {
+					"storageRoot": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Plain text credentials",
+							Description: "StorageRoot is the root directory of buildah storage, used by every buildah command",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"env": {
+					"storageRunRoot": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Env refers to credentials stored in environment variable",
+							Description: "StorageRunRoot is the directory of buildah runtime state, used by every buildah command",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"kubeSecret": {
+					"layers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KubeSecret refers to K8s secret that holds the credentials",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.KubeSecretCredentials"),
+							Description: "Layers caches an intermediate image for every instruction of a build defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.KubeSecretCredentials"},
-	}
-}
-
-func schema_pkg_apis_ocibuilder_v1alpha1_DockerStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "DockerStep represents a step within a build that contains docker commands",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"inline": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "map",
-							},
-						},
+					"format": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Inline Dockerfile commands",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Format of built images, one of oci or docker defaults to oci",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"path": {
+					"network": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Path to a file that contains Dockerfile commands",
+							Description: "Network is the network mode of RUN instructions, e.g. host, none or private",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_ocibuilder_v1alpha1_EnvCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "EnvCreds refers to credentials stored in env vars.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"username": {
+					"cgroupParent": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Username refers to an env var that holds the username",
+							Description: "CgroupParent is the parent cgroup of RUN instructions",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"password": {
+					"cgroupns": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Password refers to an en var that holds the password",
+							Description: "CgroupNS is the cgroup namespace of RUN instructions, one of host or private",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"username", "password"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_GCSContext(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_BuilderCapabilities(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GCSContext refers to the context stored on GCP Storage",
+				Description: "BuilderCapabilities are the capabilities of a builder client beyond building images",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"credentialsFilePath": {
-						SchemaProps: spec.SchemaProps{
-							Description: "CredentialsFilePath refers to the credentials file path",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"apiKey": {
-						SchemaProps: spec.SchemaProps{
-							Description: "APIKey for authentication",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
-						},
-					},
-					"authRequired": {
+					"LocalImages": {
 						SchemaProps: spec.SchemaProps{
-							Description: "AuthRequired checks if authentication is required to connect to GCS",
+							Description: "LocalImages is whether built images are stored locally, where they can be inspected, run and saved after the build e.g. for structure tests, metadata and exports",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"endpoint": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Endpoint is the storage to connect to",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"bucket": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Bucket refers to the bucket name on gcs",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"),
-						},
-					},
-					"region": {
+					"BuildExports": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Region refers to GCS region",
-							Type:        []string{"string"},
+							Description: "BuildExports is whether exports are written as outputs of the build",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
 				},
-				Required: []string{"authRequired", "endpoint", "bucket"},
+				Required: []string{"LocalImages", "BuildExports"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_GitContext(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_Command(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GitContext contains information about an artifact stored in git",
+				Description: "Command Represents a single line in a Dockerfile",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"url": {
+					"cmd": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Git URL",
+							Description: "Cmd lowercased command name (e.g `from`)",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"username": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Username for authentication",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
-						},
-					},
-					"password": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Password for authentication",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
-						},
-					},
-					"sshKeyPath": {
+					"subCmd": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SSHKeyPath is path to your ssh key path. Use this if you don't want to provide username and password. ssh key path must be mounted in sensor pod.",
+							Description: "SubCmd for ONBUILD only this holds the sub-command",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"branch": {
+					"isJSON": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Branch to use to pull trigger resource",
-							Type:        []string{"string"},
+							Description: "Json bool for whether the value is written in json",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"tag": {
+					"original": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tag to use to pull trigger resource",
+							Description: "Original is the original source line",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"ref": {
+					"startLine": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Ref to use to pull trigger resource. Will result in a shallow clone and fetch.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "StartLine is the original source line number",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"remote": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Remote to manage set of tracked repositories. Defaults to \"origin\". Refer https://git-scm.com/docs/git-remote",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitRemoteConfig"),
+					"flags": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
 						},
-					},
-				},
-				Required: []string{"url"},
-			},
-		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitRemoteConfig"},
-	}
-}
-
-func schema_pkg_apis_ocibuilder_v1alpha1_GitRemoteConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "GitRemoteConfig contains the configuration of a Git remote",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the remote to fetch from.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Flags such as `--from=...` for `COPY`.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"urls": {
+					"value": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "urls",
+								"x-kubernetes-list-type": "map",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "URLs the URLs of a remote repository. It must be non-empty. Fetch will always use the first URL, while push will use all of them.",
+							Description: "Value is the contents of the command (e.g `ubuntu:xenial`)",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -853,390 +961,477 @@ func schema_pkg_apis_ocibuilder_v1alpha1_GitRemoteConfig(ref common.ReferenceCal
 						},
 					},
 				},
-				Required: []string{"name", "urls"},
+				Required: []string{"cmd", "subCmd", "isJSON", "original", "startLine", "flags", "value"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_ImageBuildArgs(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_CommandTest(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ImageBuildArgs describes the arguments for running a build command",
+				Description: "CommandTest is a command run in a container of the image and its expected output",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name is the name of the build",
+							Description: "Name of the test",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"tag": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Tag is the tag of the build",
-							Type:        []string{"string"},
-							Format:      "",
+					"command": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
 						},
-					},
-					"dockerfile": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Dockerfile is the path to the generated Dockerfile",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Command to run in the container, replacing the entrypoint of the image",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"ansible": {
+					"exitCode": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Ansible step outlines the ansible steps in the build *optional",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleStep"),
+							Description: "ExitCode is the expected exit code of the command defaults to 0",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"purge": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Purge the image after it has been pushed defaults to false",
-							Type:        []string{"boolean"},
-							Format:      "",
+					"expectedOutput": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectedOutput are regexes which must match the stdout of the command",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"context": {
+					"excludedOutput": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Description: "BuildContext is the context for Docker and Buildah defaults to LocalContext in current working directory",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildContext"),
+							Description: "ExcludedOutput are regexes which must not match the stdout of the command",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
 				},
-				Required: []string{"name", "tag"},
+				Required: []string{"name", "command"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.AnsibleStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildContext"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_K8sCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_Credentials(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "K8sCreds refers to the K8s secret that holds the registry creds.",
+				Description: "Credentials encapsulates different ways of storing the credentials",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"username": {
+					"plain": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Username refers to the K8s secret that holds username",
-							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+							Description: "Plain text credentials",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"password": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Password refers to the K8s secret that holds password",
-							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+							Description: "Env refers to credentials stored in environment variable",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"kubeSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KubeSecret refers to K8s secret that holds the credentials",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.KubeSecretCredentials"),
+						},
+					},
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "File refers to credentials stored in a file",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"username", "password"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.SecretKeySelector"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.KubeSecretCredentials"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_KubeSecretCredentials(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_DistrolessConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "KubeSecretCredentials refers to K8s secret that holds the credentials",
+				Description: "DistrolessConfig contains the configuration of the distroless stage appended to a build step",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"secret": {
+					"base": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Secret is the K8s secret key selector",
-							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+							Description: "Base is the base image of the distroless stage, e.g. scratch defaults to gcr.io/distroless/base",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base"),
 						},
 					},
-					"namespace": {
+					"paths": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Description: "Namespace where the secret is stored",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Paths in the last stage to copy into the distroless stage defaults to the entrypoint binary of the last stage and its dynamic libraries",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
 				},
-				Required: []string{"secret", "namespace"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.SecretKeySelector"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_LocalContext(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_DockerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "LocalContext stores the path for your local build context, implements the ContextReader interface",
+				Description: "DockerSpec contains the configuration of the docker daemon, such as a remote daemon on a dedicated build host",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"contextPath": {
+					"host": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ContextPath is the path to your build context",
+							Description: "Host is the address of the docker daemon e.g. tcp://build-host:2376 defaults to $DOCKER_HOST or the local daemon",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion is the version of the docker API defaults to negotiating the version with the daemon",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"tls": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLS secures the connection to the docker daemon",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerTLS"),
+						},
+					},
 				},
-				Required: []string{"contextPath"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerTLS"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_LoginSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_DockerStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "LoginSpec holds the information to log into a registry.",
+				Description: "DockerStep represents a step within a build that contains docker commands",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"registry": {
+					"inline": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Description: "Registry refers to a OCI image registry",
+							Description: "Inline Dockerfile commands",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path to a file that contains Dockerfile commands",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"token": {
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Remote url to a file that contains docker commands",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"creds": {
+					"env": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Creds refer to credentials required to log into the registry",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryCreds"),
+							Description: "Env refers to the credentials stored in environment variables",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds"),
+						},
+					},
+					"plain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Plain refers to the credentials set inline",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"),
 						},
 					},
 				},
-				Required: []string{"registry", "token", "creds"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryCreds"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_Metadata(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_DockerTLS(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Metadata represents data about a build step",
+				Description: "DockerTLS contains the PEM encoded certificates of a TLS secured docker daemon",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"ca": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the build step",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "CA is the certificate authority the daemon certificate is verified with defaults to the system certificate authorities",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
 						},
 					},
-					"labels": {
+					"cert": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Labels for the step",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Cert is the client certificate, required by daemons which verify clients",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
 						},
 					},
-					"annotations": {
+					"key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations for the step",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Key is the key of the client certificate",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
+						},
+					},
+					"insecureSkipVerify": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InsecureSkipVerify skips verifying the daemon certificate defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"name"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_NodeStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_EnvCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "NodeStatus describes the status for an individual node in the ocibuilder configurations. A single node can represent one configuration.",
+				Description: "EnvCreds refers to credentials stored in env vars.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"id": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ID is a unique identifier of a node within build steps It is a hash of the node name",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"name": {
+					"username": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name is a unique name in the node tree used to generate the node ID",
+							Description: "Username refers to an env var that holds the username",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"displayName": {
+					"password": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DisplayName is the human readable representation of the node",
+							Description: "Password refers to an en var that holds the password",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"phase": {
+				},
+				Required: []string{"username", "password"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_ExportSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExportSpec contains the specification to export a built image to the local filesystem",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"format": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Phase of the node",
+							Description: "Format of the exported image, one of oci, oci-archive or docker-archive",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"startedAt": {
-						SchemaProps: spec.SchemaProps{
-							Description: "StartedAt is the time at which this node started",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
-						},
-					},
-					"message": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Message store data or something to save for configuration",
+							Description: "Path to export the image to, this is a directory for the oci format and a file for archive formats",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"updateTime": {
-						SchemaProps: spec.SchemaProps{
-							Description: "UpdateTime is the time when node(OCIBuilder configuration) was updated",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
-						},
-					},
 				},
-				Required: []string{"id", "name", "displayName", "phase"},
+				Required: []string{"format", "path"},
 			},
 		},
-		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilder(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_FileTest(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OCIBuilder is the definition of a ocibuilder resource",
+				Description: "FileTest is a path which must or must not exist in the image",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Description: "Path of the file or directory",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"shouldExist": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
-							Type:        []string{"string"},
+							Description: "ShouldExist is whether the path must exist in the image",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"name": {
+				},
+				Required: []string{"path", "shouldExist"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_GCSContext(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GCSContext refers to the context stored on GCP Storage",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"credentialsFilePath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name must be unique within a namespace. Is required when creating resources, although some resources may allow a client to request the generation of an appropriate name automatically. Name is primarily intended for creation idempotence and configuration definition. Cannot be updated. More info: http://kubernetes.io/docs/user-guide/identifiers#names",
+							Description: "CredentialsFilePath refers to the credentials file path",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"generateName": {
+					"apiKey": {
 						SchemaProps: spec.SchemaProps{
-							Description: "GenerateName is an optional prefix, used by the server, to generate a unique name ONLY IF the Name field has not been provided. If this field is used, the name returned to the client will be different than the name passed. This value will also be combined with a unique suffix. The provided value has the same validation rules as the Name field, and may be truncated by the length of the suffix required to make the value unique on the server.\n\nIf this field is specified and the generated name exists, the server will NOT return a 409 - instead, it will either return 201 Created or 500 with Reason ServerTimeout indicating a unique name could not be found in the time allotted, and the client should retry (optionally after the time indicated in the Retry-After header).\n\nApplied only if Name is not specified. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#idempotency",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "APIKey for authentication",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
 						},
 					},
-					"namespace": {
+					"authRequired": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Namespace defines the space within each name must be unique. An empty namespace is equivalent to the \"default\" namespace, but \"default\" is the canonical representation. Not all objects are required to be scoped to a namespace - the value of this field for those objects will be empty.\n\nMust be a DNS_LABEL. Cannot be updated. More info: http://kubernetes.io/docs/user-guide/namespaces",
-							Type:        []string{"string"},
+							Description: "AuthRequired checks if authentication is required to connect to GCS",
+							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
-					"selfLink": {
+					"endpoint": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SelfLink is a URL representing this object. Populated by the system. Read-only.",
+							Description: "Endpoint is the storage to connect to",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"uid": {
+					"bucket": {
 						SchemaProps: spec.SchemaProps{
-							Description: "UID is the unique in time and space value for this object. It is typically generated by the server on successful creation of a resource and is not allowed to change on PUT operations.\n\nPopulated by the system. Read-only. More info: http://kubernetes.io/docs/user-guide/identifiers#uids",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Bucket refers to the bucket name on gcs",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"),
 						},
 					},
-					"resourceVersion": {
+					"region": {
 						SchemaProps: spec.SchemaProps{
-							Description: "An opaque value that represents the internal version of this object that can be used by clients to determine when objects have changed. May be used for optimistic concurrency, change detection, and the watch operation on a resource or set of resources. Clients must treat these values as opaque and passed unmodified back to the server. They may only be valid for a particular resource or set of resources.\n\nPopulated by the system. Read-only. Value must be treated as opaque by clients and . More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#concurrency-control-and-consistency",
+							Description: "Region refers to GCS region",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"generation": {
-						SchemaProps: spec.SchemaProps{
-							Description: "A sequence number representing a specific generation of the desired state. Populated by the system. Read-only.",
-							Type:        []string{"integer"},
-							Format:      "int64",
-						},
-					},
-					"creationTimestamp": {
-						SchemaProps: spec.SchemaProps{
-							Description: "CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC.\n\nPopulated by the system. Read-only. Null for lists. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
-						},
-					},
-					"deletionTimestamp": {
+				},
+				Required: []string{"authRequired", "endpoint", "bucket"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_GenerateTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GenerateTemplate is the template for a docker generate",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ImageName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "DeletionTimestamp is RFC 3339 date and time at which this resource will be deleted. This field is set by the server when a graceful deletion is requested by the user, and is not directly settable by a client. The resource is expected to be deleted (no longer visible from resource lists, and not reachable by name) after the time in this field, once the finalizers list is empty. As long as the finalizers list contains items, deletion is blocked. Once the deletionTimestamp is set, this value may not be unset or be set further into the future, although it may be shortened or the resource may be deleted prior to this time. For example, a user may request that a pod is deleted in 30 seconds. The Kubelet will react by sending a graceful termination signal to the containers in the pod. After that 30 seconds, the Kubelet will send a hard termination signal (SIGKILL) to the container and after cleanup, remove the pod from the API. In the presence of network partitions, this object may still exist after this timestamp, until an administrator or automated process can determine the resource is fully terminated. If not set, graceful deletion of the object has not been requested.\n\nPopulated by the system when a graceful deletion is requested. Read-only. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"deletionGracePeriodSeconds": {
+					"Tag": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Number of seconds allowed for this object to gracefully terminate before it will be removed from the system. Only set when deletionTimestamp is also set. May only be shortened. Read-only.",
-							Type:        []string{"integer"},
-							Format:      "int64",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"labels": {
+					"Stages": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Map of string keys and values that can be used to organize and categorize (scope and select) objects. May match selectors of replication controllers and services. More info: http://kubernetes.io/docs/user-guide/labels",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Type:   []string{"string"},
@@ -1246,12 +1441,10 @@ func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilder(ref common.ReferenceCallback
 							},
 						},
 					},
-					"annotations": {
+					"Templates": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata. They are not queryable and should be preserved when modifying objects. More info: http://kubernetes.io/docs/user-guide/annotations",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Type:   []string{"string"},
@@ -1261,534 +1454,1996 @@ func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilder(ref common.ReferenceCallback
 							},
 						},
 					},
-					"ownerReferences": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-patch-merge-key": "uid",
-								"x-kubernetes-patch-strategy":  "merge",
-							},
-						},
+				},
+				Required: []string{"ImageName", "Tag", "Stages", "Templates"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_GitContext(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GitContext contains information about an artifact stored in git",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of objects depended by this object. If ALL objects in the list have been deleted, this object will be garbage collected. If this object is managed by a controller, then an entry in this list will point to this controller, with the controller field set to true. There cannot be more than one managing controller.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.OwnerReference"),
-									},
-								},
-							},
+							Description: "Git URL",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"initializers": {
+					"username": {
 						SchemaProps: spec.SchemaProps{
-							Description: "An initializer is a controller which enforces some system invariant at object creation time. This field is a list of initializers that have not yet acted on this object. If nil or empty, this object has been completely initialized. Otherwise, the object is considered uninitialized and is hidden (in list/watch and get calls) from clients that haven't explicitly asked to observe uninitialized objects.\n\nWhen an object is created, the system will populate this list with the current set of initializers. Only privileged users may set or modify this list. Once it is empty, it may not be modified further by any user.\n\nDEPRECATED - initializers are an alpha field and will be removed in v1.15.",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Initializers"),
+							Description: "Username for authentication",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
 						},
 					},
-					"finalizers": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-patch-strategy": "merge",
-							},
+					"password": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Password for authentication",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
 						},
+					},
+					"sshKeyPath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Must be empty before the object is deleted from the registry. Each entry is an identifier for the responsible component that will remove the entry from the list. If the deletionTimestamp of the object is non-nil, entries in this list can only be removed.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "SSHKeyPath is path to your ssh key path. Use this if you don't want to provide username and password. ssh key path must be mounted in sensor pod.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"clusterName": {
+					"branch": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The name of the cluster which the object belongs to. This is used to distinguish resources with same name and namespace in different clusters. This field is not set anywhere right now and apiserver is going to ignore it if set in create or update request.",
+							Description: "Branch to use to pull trigger resource",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"managedFields": {
+					"tag": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ManagedFields maps workflow-id and version to the set of fields that are managed by that workflow. This is mostly for internal housekeeping, and users typically shouldn't need to set or understand this field. A workflow can be the user's name, a controller's name, or the name of a specific apply path like \"ci-cd\". The set of fields is always in the version that the workflow used when modifying the object.\n\nThis field is alpha and can be changed or removed without notice.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ManagedFieldsEntry"),
-									},
-								},
-							},
+							Description: "Tag to use to pull trigger resource",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"spec": {
+					"ref": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderSpec"),
+							Description: "Ref to use to pull trigger resource. Will result in a shallow clone and fetch.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"status": {
+					"remote": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderStatus"),
+							Description: "Remote to manage set of tracked repositories. Defaults to \"origin\". Refer https://git-scm.com/docs/git-remote",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitRemoteConfig"),
 						},
 					},
 				},
-				Required: []string{"spec", "status"},
+				Required: []string{"url"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Initializers", "k8s.io/apimachinery/pkg/apis/meta/v1.ManagedFieldsEntry", "k8s.io/apimachinery/pkg/apis/meta/v1.OwnerReference", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitRemoteConfig"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_GitRemoteConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OCIBuilderList is the list of OCIBuilder resources.",
+				Description: "GitRemoteConfig contains the configuration of a Git remote",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"apiVersion": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Description: "Name of the remote to fetch from.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
-						},
-					},
-					"items": {
+					"urls": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "map",
+								"x-kubernetes-list-type": "urls",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "URLs the URLs of a remote repository. It must be non-empty. Fetch will always use the first URL, while push will use all of them.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilder"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
 				},
-				Required: []string{"metadata", "items"},
+				Required: []string{"name", "urls"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilder", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_Grafeas(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OCIBuilderSpec represents OCIBuilder specifications.",
+				Description: "Grafeas is the type defining the Grafeas metadata store",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"params": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "map",
-							},
-						},
+					"project": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Envs are the list of environment variables available to components.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Param"),
-									},
-								},
-							},
+							Description: "Project is the name of the project ID to store the occurrence",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"login": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "map",
-							},
-						},
+					"notes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Logins holds information to log into one or more registries",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoginSpec"),
-									},
-								},
-							},
+							Description: "Notes holds the notes for the three occurrence types",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Notes"),
 						},
 					},
-					"build": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Notes"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_ImageBuildArgs(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImageBuildArgs describes the arguments for running a build command",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Build represents the build specifications for images",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildSpec"),
+							Description: "Name is the name of the build",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"push": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "map",
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tag is the tag of the build",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tags": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tags are all the rendered tags of the build, including Tag",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
 							},
 						},
+					},
+					"dockerfile": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Push contains specification to push images to registries",
+							Description: "Dockerfile is the path to the generated Dockerfile",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"purge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Purge the image after it has been pushed defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"buildContextPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildContextPath is the path of the build context for Docker and Buildah defaults to LocalContext in current working directory",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels for the step",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"creator": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Creator is the email of the build creator",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source is the URI of the source code for the build",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"cache": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cache for build Set to false by default",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"storageDriver": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StorageDriver is a buildah flag for storage driver e.g. vfs",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"export": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Export writes the built image to the local filesystem in the specified formats",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PushSpec"),
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ExportSpec"),
 									},
 								},
 							},
 						},
 					},
+					"buildId": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildID is the unique ID labelled on every stage of a purged build, used to prune intermediate images",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"test": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Test contains structure tests which the built image must pass",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageTest"),
+						},
+					},
+					"cacheRepo": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CacheRepo is the repository cached layers are pushed to and pulled from, used for Kaniko builds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
+				Required: []string{"name", "tag", "storageDriver"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoginSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Param", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PushSpec"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ExportSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.ImageTest"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_ImageMetadata(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "OCIBuilderStatus holds the status of a OCIBuilder resource",
+				Description: "ImageMetadata represents data about a build step",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"phase": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Phase is the high-level summary of the OCIBuilder",
+							Description: "Name of the build step",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"startedAt": {
+					"labels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "StartedAt is the time at which this OCIBuilder was initiated",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+							Description: "Labels for the step",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
-					"message": {
+					"annotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Message is a human readable string indicating details about a OCIBuilder in its phase",
+							Description: "Annotations for the step",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"creator": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Creator is the creator of the build",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"nodes": {
+					"source": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Nodes is a mapping between a node ID and the node's status it records the states for the configurations of OCIBuilder.",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "Source is the URI to the source code of the image build",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_ImageTest(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImageTest contains structure tests which are run against a built image",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"commands": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Commands are commands run in a container of the image",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.NodeStatus"),
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.CommandTest"),
+									},
+								},
+							},
+						},
+					},
+					"files": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Files are files which must or must not exist in the image",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.FileTest"),
 									},
 								},
 							},
 						},
 					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Metadata is the expected configuration of the image",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.MetadataTest"),
+						},
+					},
+					"maxSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxSize is the maximum size of the image e.g. 250MB",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.CommandTest", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.FileTest", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.MetadataTest"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_K8sCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "K8sCreds refers to the K8s secret that holds the registry creds.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Username refers to the K8s secret that holds username",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+					"password": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Password refers to the K8s secret that holds password",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"username", "password"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_KubeSecretCredentials(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "KubeSecretCredentials refers to K8s secret that holds the credentials",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"secret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Secret is the K8s secret key selector",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace where the secret is stored",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"secret", "namespace"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_LoadSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LoadSpec contains the specification to load an image from the local filesystem",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"input": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Input is the path of the image to load",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"format": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Format of the image to load, one of oci, oci-archive or docker-archive defaults to detecting the format from the input",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"input"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_LocalContext(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LocalContext stores the path for your local build context, implements the ContextReader interface",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"contextPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ContextPath is the path to your build context",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"contextPath"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_LoginSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LoginSpec holds the information to log into a registry.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry refers to a OCI image registry",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"token": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"creds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Creds refer to credentials required to log into the registry",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryCreds"),
+						},
+					},
+					"overlay": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Overlay is the name which will be referred to by an overlay file",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"registry", "token", "creds", "overlay"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryCreds"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_Metadata(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Metadata is where metadata to store is defined in the ocibuilder specification",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"storeConfig": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StoreType is the metadata store type to push metadata to",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.StoreConfig"),
+						},
+					},
+					"signKey": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SignKey holds the key to sign an image for attestation purposes",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.SignKey"),
+						},
+					},
+					"hostname": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Hostname is the hostname of the metadatastore",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"data": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Data is the types of metadata that you would like to push to your metadatastore",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"creator": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Creator is the email of the build creator",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.SignKey", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.StoreConfig"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_MetadataTest(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MetadataTest is the expected configuration of the image",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env are environment variables which must be set to the specified values",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"entrypoint": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Entrypoint is the expected entrypoint of the image",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"exposedPorts": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "ExposedPorts are ports which must be exposed e.g. 8080/tcp",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Labels are labels which must be set to the specified values",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_NodeStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NodeStatus describes the status for an individual node in the ocibuilder configurations. A single node can represent one configuration.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"id": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ID is a unique identifier of a node within build steps It is a hash of the node name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is a unique name in the node tree used to generate the node ID",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"displayName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisplayName is the human readable representation of the node",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase of the node",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartedAt is the time at which this node started",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message store data or something to save for configuration",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"updateTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpdateTime is the time when node(OCIBuilder configuration) was updated",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"),
+						},
+					},
+				},
+				Required: []string{"id", "name", "displayName", "phase"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.MicroTime"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_Notes(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"build": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BuildNoteName Required. Immutable. The analysis note associated with build occurrence, in the form of `projects/[PROVIDER_ID]/notes/[NOTE_ID]`. This field can be used as a filter in list requests.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"attestation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AttestationNoteName Required. Immutable. The analysis note associated with attestation occurrence, in the form of `projects/[PROVIDER_ID]/notes/[NOTE_ID]`. This field can be used as a filter in list requests.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DerivedImageNoteName Required. Immutable. The analysis note associated with image derived occurrence, in the form of `projects/[PROVIDER_ID]/notes/[NOTE_ID]`. This field can be used as a filter in list requests.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilder(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OCIBuilder is the definition of a ocibuilder resource",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name must be unique within a namespace. Is required when creating resources, although some resources may allow a client to request the generation of an appropriate name automatically. Name is primarily intended for creation idempotence and configuration definition. Cannot be updated. More info: http://kubernetes.io/docs/user-guide/identifiers#names",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"generateName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GenerateName is an optional prefix, used by the server, to generate a unique name ONLY IF the Name field has not been provided. If this field is used, the name returned to the client will be different than the name passed. This value will also be combined with a unique suffix. The provided value has the same validation rules as the Name field, and may be truncated by the length of the suffix required to make the value unique on the server.\n\nIf this field is specified and the generated name exists, the server will NOT return a 409 - instead, it will either return 201 Created or 500 with Reason ServerTimeout indicating a unique name could not be found in the time allotted, and the client should retry (optionally after the time indicated in the Retry-After header).\n\nApplied only if Name is not specified. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#idempotency",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace defines the space within each name must be unique. An empty namespace is equivalent to the \"default\" namespace, but \"default\" is the canonical representation. Not all objects are required to be scoped to a namespace - the value of this field for those objects will be empty.\n\nMust be a DNS_LABEL. Cannot be updated. More info: http://kubernetes.io/docs/user-guide/namespaces",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"selfLink": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SelfLink is a URL representing this object. Populated by the system. Read-only.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"uid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UID is the unique in time and space value for this object. It is typically generated by the server on successful creation of a resource and is not allowed to change on PUT operations.\n\nPopulated by the system. Read-only. More info: http://kubernetes.io/docs/user-guide/identifiers#uids",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resourceVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "An opaque value that represents the internal version of this object that can be used by clients to determine when objects have changed. May be used for optimistic concurrency, change detection, and the watch operation on a resource or set of resources. Clients must treat these values as opaque and passed unmodified back to the server. They may only be valid for a particular resource or set of resources.\n\nPopulated by the system. Read-only. Value must be treated as opaque by clients and . More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#concurrency-control-and-consistency",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"generation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A sequence number representing a specific generation of the desired state. Populated by the system. Read-only.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"creationTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC.\n\nPopulated by the system. Read-only. Null for lists. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"deletionTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletionTimestamp is RFC 3339 date and time at which this resource will be deleted. This field is set by the server when a graceful deletion is requested by the user, and is not directly settable by a client. The resource is expected to be deleted (no longer visible from resource lists, and not reachable by name) after the time in this field, once the finalizers list is empty. As long as the finalizers list contains items, deletion is blocked. Once the deletionTimestamp is set, this value may not be unset or be set further into the future, although it may be shortened or the resource may be deleted prior to this time. For example, a user may request that a pod is deleted in 30 seconds. The Kubelet will react by sending a graceful termination signal to the containers in the pod. After that 30 seconds, the Kubelet will send a hard termination signal (SIGKILL) to the container and after cleanup, remove the pod from the API. In the presence of network partitions, this object may still exist after this timestamp, until an administrator or automated process can determine the resource is fully terminated. If not set, graceful deletion of the object has not been requested.\n\nPopulated by the system when a graceful deletion is requested. Read-only. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"deletionGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of seconds allowed for this object to gracefully terminate before it will be removed from the system. Only set when deletionTimestamp is also set. May only be shortened. Read-only.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"labels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Map of string keys and values that can be used to organize and categorize (scope and select) objects. May match selectors of replication controllers and services. More info: http://kubernetes.io/docs/user-guide/labels",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"annotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Annotations is an unstructured key value map stored with a resource that may be set by external tools to store and retrieve arbitrary metadata. They are not queryable and should be preserved when modifying objects. More info: http://kubernetes.io/docs/user-guide/annotations",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"ownerReferences": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-patch-merge-key": "uid",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "List of objects depended by this object. If ALL objects in the list have been deleted, this object will be garbage collected. If this object is managed by a controller, then an entry in this list will point to this controller, with the controller field set to true. There cannot be more than one managing controller.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.OwnerReference"),
+									},
+								},
+							},
+						},
+					},
+					"initializers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "An initializer is a controller which enforces some system invariant at object creation time. This field is a list of initializers that have not yet acted on this object. If nil or empty, this object has been completely initialized. Otherwise, the object is considered uninitialized and is hidden (in list/watch and get calls) from clients that haven't explicitly asked to observe uninitialized objects.\n\nWhen an object is created, the system will populate this list with the current set of initializers. Only privileged users may set or modify this list. Once it is empty, it may not be modified further by any user.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Initializers"),
+						},
+					},
+					"finalizers": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-patch-strategy": "merge",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Must be empty before the object is deleted from the registry. Each entry is an identifier for the responsible component that will remove the entry from the list. If the deletionTimestamp of the object is non-nil, entries in this list can only be removed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"clusterName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the cluster which the object belongs to. This is used to distinguish resources with same name and namespace in different clusters. This field is not set anywhere right now and apiserver is going to ignore it if set in create or update request.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderStatus"),
+						},
+					},
+				},
+				Required: []string{"spec", "status"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilderStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Initializers", "k8s.io/apimachinery/pkg/apis/meta/v1.OwnerReference", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OCIBuilderList is the list of OCIBuilder resources.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilder"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"metadata", "items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.OCIBuilder", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OCIBuilderSpec represents OCIBuilder specifications.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"params": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Envs are the list of environment variables available to components.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Param"),
+									},
+								},
+							},
+						},
+					},
+					"login": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Logins holds information to log into one or more registries",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoginSpec"),
+									},
+								},
+							},
+						},
+					},
+					"build": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Build represents the build specifications for images",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildSpec"),
+						},
+					},
+					"push": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Push contains specification to push images to registries",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PushSpec"),
+									},
+								},
+							},
+						},
+					},
+					"daemon": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the build framework. Defaults to docker",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Configuration for storing build metadata in an external Metadata store. Defaults to Grafeas as the chosen metadata store",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Metadata"),
+						},
+					},
+					"pull": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Pull contains specification to pull images from registries",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PullSpec"),
+									},
+								},
+							},
+						},
+					},
+					"buildah": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Buildah contains options for running buildah, used when building with the buildah builder",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildahOptions"),
+						},
+					},
+					"docker": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Docker contains the configuration of the docker daemon, used when building with the docker builder defaults to the docker environment variables",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerSpec"),
+						},
+					},
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry contains options for accessing registries, used when pulling and pushing with the registry builder",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryOptions"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildahOptions", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.DockerSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.LoginSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Metadata", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Param", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PullSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PushSpec", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RegistryOptions"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_OCIBuilderStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OCIBuilderStatus holds the status of a OCIBuilder resource",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase is the high-level summary of the OCIBuilder",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"startedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartedAt is the time at which this OCIBuilder was initiated",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable string indicating details about a OCIBuilder in its phase",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"nodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Nodes is a mapping between a node ID and the node's status it records the states for the configurations of OCIBuilder.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.NodeStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"phase", "nodes"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.NodeStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_Package(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Package is a package installed by a packages step",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the package",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version the package is pinned to, in the version format of the package manager",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_PackagesStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PackagesStep installs packages with a package manager in a single RUN instruction, cleaning up the package manager cache in the same layer",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"manager": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Manager is the package manager, one of auto, apt, apk, yum or dnf defaults to auto",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"packages": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Packages to install",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Package"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"packages"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Package"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_Param(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Param represents parameters",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"value": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Value of the environment variable.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"dest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Dest is the destination of the field to replace with the parameter",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"valueFromEnvVariable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ValueFromEnvVar is a variable which is to be replaced by an env var",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"dest"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_PlainCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PlainCreds refers to the credentials set inline",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"password": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"username", "password"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_PullSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PullSpec contains the specification to pull an image from a registry",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image to pull",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tag of the image to pull, only one of tag or digest can be set defaults to latest",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Digest of the image to pull e.g. sha256:...",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry to pull the image from, credentials are taken from the login with a matching registry defaults to the registry of the image name",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"platform": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Platform of the image to pull e.g. linux/amd64",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"policy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Policy for pulling the image, one of Always or IfNotPresent defaults to IfNotPresent",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"image"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_PushResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PushResult is the result of pushing an image to a registry",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry is the registry the image was pushed to",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image is the name of the pushed image",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tag is the tag of the pushed image",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Digest is the manifest digest of the pushed image",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reference": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reference is the digest pinned reference of the pushed image e.g. docker.io/image@sha256:...",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"registry", "image", "tag", "digest", "reference"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_PushSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PushSpec contains the specification to push images to registries",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry is the name of the registry",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"from": {
+						SchemaProps: spec.SchemaProps{
+							Description: "From is the name of the build step which built the image to push. The built image is tagged into the registry before it is pushed, and the image and tags default to those of the build step",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image to push",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the name of kubernetes namespace",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"token": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Token required for the OCI complaint registry authentication",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tag version of the image (e.g: v0.1.1), this can be a go template e.g. {{ .Git.ShortSHA }}",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tags": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Tags are additional tags of the image to push alongside Tag",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"purge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Purge the image after it has been pushed defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"overlay": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Overlay is the name which will be referred to by an overlay file",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"registry", "image", "user", "token", "tag", "overlay"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_RegistryCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RegistryCreds holds the credentials to login into a registry",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"k8s": {
+						SchemaProps: spec.SchemaProps{
+							Description: "K8s refer to the credentials stored in K8s secrets",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.K8sCreds"),
+						},
+					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env refers to the credentials stored in environment variables",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds"),
+						},
+					},
+					"plain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Plain refers to the credentials set inline",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.K8sCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_RegistryOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RegistryOptions contains the options of the registry builder, which pulls and pushes images without a daemon",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"layoutPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LayoutPath is the OCI image layout directory images are pulled into and pushed from defaults to .ocibuilder/images in the home directory",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"insecure": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Insecure allows registries to be accessed over plain http defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_RemoteCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RemoteCreds holds the credentials to pull from a remote url",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env refers to the credentials stored in environment variables",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds"),
+						},
+					},
+					"plain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Plain refers to the credentials set inline",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"),
+						},
+					},
 				},
-				Required: []string{"phase", "nodes"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.NodeStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_Param(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_S3Bucket(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Param represents parameters",
+				Description: "S3Bucket contains information to describe an S3 Bucket",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"value": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Value of the environment variable.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"dest": {
+					"key": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Dest is the destination of the field to replace with the parameter",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"valueFromEnvVariable": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ValueFromEnvVar is a variable which is to be replaced by an env var",
-							Type:        []string{"string"},
-							Format:      "",
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
 				},
-				Required: []string{"dest"},
+				Required: []string{"name"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_PlainCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_S3Context(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PlainCreds refers to the credentials set inline",
+				Description: "S3Context refers to context stored on S3 bucket to build an image",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"username": {
+					"endpoint": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"password": {
+					"bucket": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"),
+						},
+					},
+					"region": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
+					"insecure": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"boolean"},
+							Format: "",
+						},
+					},
+					"accessKey": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
+						},
+					},
+					"secretKey": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
+						},
+					},
 				},
-				Required: []string{"username", "password"},
+				Required: []string{"endpoint", "bucket", "accessKey", "secretKey"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_PushSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_SignKey(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "PushSpec contains the specification to push images to registries",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"registry": {
+					"plainPrivateKey": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Registry is the name of the registry",
+							Description: "PrivateKey is an ascii armored private key used to sign images for image attestation",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"image": {
+					"plainPublicKey": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Image to push",
+							Description: "PublicKey is the ascii armored public key for verification in image attestation",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"user": {
+					"envPrivateKey": {
 						SchemaProps: spec.SchemaProps{
-							Description: "User is the name of kubernetes namespace",
+							Description: "EnvPrivateKey is an env variable that holds an ascii armored private key used to sign images for image attestation",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"token": {
+					"envPublicKey": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Token required for the OCI complaint registry authentication",
+							Description: "EnvPublicKey is an env variable that holds an ascii armored public key used to sign images for image attestation",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"tag": {
+					"passphrase": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tag version of the image (e.g: v0.1.1)",
+							Description: "Passphrase is the passphrase for decrypting the private key",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"purge": {
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Purge the image after it has been pushed defaults to false",
-							Type:        []string{"boolean"},
+							Description: "Url or a filepath to a file that contains an ascii armored private key",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Env refers to the credentials stored in environment variables",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds"),
+						},
+					},
+					"plain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Plain refers to the credentials set inline",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"),
+						},
+					},
 				},
-				Required: []string{"registry", "image", "user", "token", "tag"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_RegistryCreds(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_Stage(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RegistryCreds holds the credentials to login into a registry",
+				Description: "Stage represents a stage within the build",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"k8s": {
+					"base": {
 						SchemaProps: spec.SchemaProps{
-							Description: "K8s refer to the credentials stored in K8s secrets",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.K8sCreds"),
+							Description: "BaseImage refers to parent image for given build stage.",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base"),
 						},
 					},
-					"env": {
+					"template": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Env refers to the credentials stored in environment variables",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds"),
+							Description: "Template refers to one of the build templates, by name or by name with the values of its inputs.",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateRef"),
 						},
 					},
-					"plain": {
+					"cmd": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "map",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
-							Description: "Plain refers to the credentials set inline",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"),
+							Description: "Cmd refers to a template defined in a stage without a template.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep"),
+									},
+								},
+							},
 						},
 					},
 				},
+				Required: []string{"base", "template", "cmd"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.EnvCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.K8sCreds", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.PlainCreds"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.TemplateRef"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_S3Bucket(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_StageGenTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "S3Bucket contains information to describe an S3 Bucket",
+				Description: "StageGenTemplate is the template for a stage in docker generate",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"key": {
+					"Base": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
-					"name": {
+					"BaseTag": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"StageName": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"TemplateName": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
 				},
-				Required: []string{"name"},
+				Required: []string{"Base", "BaseTag", "StageName", "TemplateName"},
 			},
 		},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_S3Context(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_StoreConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "S3Context refers to context stored on S3 bucket to build an image",
+				Description: "StoreConfig is the configuration of the metadata store to push metadata to",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"endpoint": {
+					"grafeas": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Grafeas holds the config for the Grafeas metadata store",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Grafeas"),
 						},
 					},
-					"bucket": {
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Grafeas"},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_TemplateInput(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TemplateInput is an input of a build template",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"),
+							Description: "Name of the input, which must be a valid go template identifier",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"region": {
+					"type": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Description: "Type of the input, one of string, number or boolean defaults to string",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"insecure": {
+					"default": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"boolean"},
-							Format: "",
+							Description: "Default is the value of the input when no value is passed to the template",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"accessKey": {
+					"required": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
+							Description: "Required inputs must be passed a value by each stage using the template",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"secretKey": {
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_ocibuilder_v1alpha1_TemplateRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TemplateRef refers to a build template with the values of its inputs. A template ref is either the name of the template, or an object with the name of the template and the values of its inputs.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials"),
+							Description: "Name of the build template",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"with": {
+						SchemaProps: spec.SchemaProps{
+							Description: "With are the values of the inputs of the template",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
 						},
 					},
 				},
-				Required: []string{"endpoint", "bucket", "accessKey", "secretKey"},
+				Required: []string{"name"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Credentials", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.S3Bucket"},
 	}
 }
 
-func schema_pkg_apis_ocibuilder_v1alpha1_Stage(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_ocibuilder_v1alpha1_TemplateSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Stage represents a stage within the build",
+				Description: "TemplateSource is a source of shared build templates, a YAML file with a list of templates under the templates key, in a git repository or at a url. Imported templates are cached in ~/.ocibuilder/templates, sources pinned to a git tag or ref, or read from a url, are only fetched when they are not cached",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"base": {
+					"git": {
 						SchemaProps: spec.SchemaProps{
-							Description: "BaseImage refers to parent image for given build stage.",
-							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base"),
+							Description: "Git is the git repository of the templates file, the branch, tag or ref of the repository is the version of the templates",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitContext"),
 						},
 					},
-					"template": {
+					"path": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Template refers to one of the build templates.",
+							Description: "Path of the templates file in the git repository defaults to templates.yaml",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"cmd": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "map",
-							},
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Url of the templates file, which should include the version of the templates",
+							Type:        []string{"string"},
+							Format:      "",
 						},
+					},
+					"auth": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Cmd refers to a template defined in a stage without a template.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep"),
-									},
-								},
-							},
+							Description: "Auth for remote access to the url",
+							Ref:         ref("github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RemoteCreds"),
+						},
+					},
+					"refresh": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Refresh fetches the templates on every build instead of reading them from the cache defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"base", "template", "cmd"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.Base", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.BuildTemplateStep"},
+			"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.GitContext", "github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1.RemoteCreds"},
 	}
 }
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalJSON unmarshals a template ref from either the name of a template, or an object with the name of a
+// template and the values of its inputs. Input values may be strings, integers or booleans. Decimal numbers are
+// read as YAML floats, which drops their trailing zeros e.g. 1.10 is read as 1.1 before it is unmarshalled, so
+// decimal numbers must be quoted e.g. "1.10".
+func (t *TemplateRef) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = TemplateRef{Name: name}
+		return nil
+	}
+
+	var ref struct {
+		Name string                     `json:"name"`
+		With map[string]json.RawMessage `json:"with"`
+	}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return err
+	}
+	*t = TemplateRef{Name: ref.Name}
+	if len(ref.With) > 0 {
+		t.With = make(map[string]string, len(ref.With))
+	}
+	for k, raw := range ref.With {
+		value, err := templateValue(raw)
+		if err != nil {
+			return fmt.Errorf("invalid value of input %s of template %s: %v", k, ref.Name, err)
+		}
+		t.With[k] = value
+	}
+	return nil
+}
+
+// MarshalJSON marshals a template ref as the name of the template when no input values are passed to it
+func (t TemplateRef) MarshalJSON() ([]byte, error) {
+	if len(t.With) == 0 {
+		return json.Marshal(t.Name)
+	}
+	type templateRef TemplateRef
+	return json.Marshal(templateRef(t))
+}
+
+// templateValue returns the value of a template input as a string
+func templateValue(raw json.RawMessage) (string, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		// the digits of a decimal number may have been lost when it was read as a float
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "", fmt.Errorf("unquoted decimal number %s may have lost digits, quote the value e.g. \"%s\"", v, v)
+		}
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("%s is not a string, number or boolean", raw)
+}
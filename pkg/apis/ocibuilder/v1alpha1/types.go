@@ -47,6 +47,15 @@ const (
 	DockerFramework Framework = "docker"
 	// BuildahFramework is the type of buildah framework
 	BuildahFramework Framework = "buildah"
+	// PodmanFramework is the type of podman framework
+	PodmanFramework Framework = "podman"
+	// KanikoFramework is the type of kaniko framework
+	KanikoFramework Framework = "kaniko"
+	// BuildKitFramework is the type of buildkit framework
+	BuildKitFramework Framework = "buildkit"
+	// RegistryFramework is the type of registry framework, which pulls and pushes images over the registry
+	// HTTP API without a builder
+	RegistryFramework Framework = "registry"
 )
 
 const (
@@ -58,6 +67,50 @@ const (
 	AnsibleBase string = "/etc/ansible"
 )
 
+// ExportFormat is the format an image is exported to or loaded from
+type ExportFormat string
+
+const (
+	// OCIExportFormat exports an image as an OCI image layout directory
+	OCIExportFormat ExportFormat = "oci"
+	// OCIArchiveExportFormat exports an image as a tarball of an OCI image layout
+	OCIArchiveExportFormat ExportFormat = "oci-archive"
+	// DockerArchiveExportFormat exports an image as a tarball in the docker save format
+	DockerArchiveExportFormat ExportFormat = "docker-archive"
+)
+
+// BuildahIsolation is the isolation buildah runs the RUN instructions of a build with
+type BuildahIsolation string
+
+const (
+	// OCIIsolation runs RUN instructions with an OCI runtime, requires root
+	OCIIsolation BuildahIsolation = "oci"
+	// RootlessIsolation runs RUN instructions with an OCI runtime as an unprivileged user
+	RootlessIsolation BuildahIsolation = "rootless"
+	// ChrootIsolation runs RUN instructions in a chroot, for builds within unprivileged containers
+	ChrootIsolation BuildahIsolation = "chroot"
+)
+
+// ImageFormat is the manifest and config format of a built image
+type ImageFormat string
+
+const (
+	// OCIImageFormat builds images in the OCI image format
+	OCIImageFormat ImageFormat = "oci"
+	// DockerImageFormat builds images in the docker v2s2 image format
+	DockerImageFormat ImageFormat = "docker"
+)
+
+// PullPolicy is the policy for pulling an image into the builder
+type PullPolicy string
+
+const (
+	// PullAlways pulls the image even if it is already present in the builder
+	PullAlways PullPolicy = "Always"
+	// PullIfNotPresent pulls the image only if it is not present in the builder
+	PullIfNotPresent PullPolicy = "IfNotPresent"
+)
+
 // MetadataType is the type of metadata that you want to store
 type MetadataType string
 
@@ -114,6 +167,20 @@ type OCIBuilderSpec struct {
 	// Defaults to Grafeas as the chosen metadata store
 	// +optional
 	Metadata *Metadata `json:"metadata,omitempty" protobuf:"bytes,6,opt,name=metadata"`
+	// Pull contains specification to pull images from registries
+	// +optional
+	// +listType=map
+	Pull []PullSpec `json:"pull,omitempty" protobuf:"bytes,7,opt,name=pull"`
+	// Buildah contains options for running buildah, used when building with the buildah builder
+	// +optional
+	Buildah *BuildahOptions `json:"buildah,omitempty" protobuf:"bytes,8,opt,name=buildah"`
+	// Docker contains the configuration of the docker daemon, used when building with the docker builder
+	// defaults to the docker environment variables
+	// +optional
+	Docker *DockerSpec `json:"docker,omitempty" protobuf:"bytes,9,opt,name=docker"`
+	// Registry contains options for accessing registries, used when pulling and pushing with the registry builder
+	// +optional
+	Registry *RegistryOptions `json:"registry,omitempty" protobuf:"bytes,10,opt,name=registry"`
 }
 
 // OCIBuilderStatus holds the status of a OCIBuilder resource
@@ -151,6 +218,108 @@ type BuildSpec struct {
 	Steps []BuildStep `json:"steps" protobuf:"bytes,2,rep,name=steps"`
 	// StorageDriver is the storage driver flag (default overlay2) see https://docs.docker.com/storage/storagedriver/select-storage-driver/
 	StorageDriver string `json:"storageDriver" protobuf:"bytes,2,rep,name=storageDriver"`
+	// Preload are images loaded from the local filesystem into the builder before any build steps run
+	// +optional
+	// +listType=map
+	Preload []LoadSpec `json:"preload,omitempty" protobuf:"bytes,4,opt,name=preload"`
+	// PullBaseImages pulls the base image of every stage before any build steps run
+	// defaults to false
+	// +optional
+	PullBaseImages bool `json:"pullBaseImages,omitempty" protobuf:"bytes,5,opt,name=pullBaseImages"`
+	// TemplateSources are shared build templates imported from git repositories or urls. Templates of the
+	// spec take precedence over imported templates with the same name, and templates of a source take
+	// precedence over templates with the same name of the sources listed before it
+	// +optional
+	// +listType=map
+	TemplateSources []TemplateSource `json:"templateSources,omitempty" protobuf:"bytes,6,opt,name=templateSources"`
+}
+
+// TemplateSource is a source of shared build templates, a YAML file with a list of templates under the
+// templates key, in a git repository or at a url. Imported templates are cached in ~/.ocibuilder/templates,
+// sources pinned to a git tag or ref, or read from a url, are only fetched when they are not cached
+type TemplateSource struct {
+	// Git is the git repository of the templates file, the branch, tag or ref of the repository
+	// is the version of the templates
+	// +optional
+	Git *GitContext `json:"git,omitempty" protobuf:"bytes,1,opt,name=git"`
+	// Path of the templates file in the git repository
+	// defaults to templates.yaml
+	// +optional
+	Path string `json:"path,omitempty" protobuf:"bytes,2,opt,name=path"`
+	// Url of the templates file, which should include the version of the templates
+	// +optional
+	Url string `json:"url,omitempty" protobuf:"bytes,3,opt,name=url"`
+	// Auth for remote access to the url
+	// +optional
+	Auth RemoteCreds `json:"auth,omitempty" protobuf:"bytes,4,opt,name=auth"`
+	// Refresh fetches the templates on every build instead of reading them from the cache
+	// defaults to false
+	// +optional
+	Refresh bool `json:"refresh,omitempty" protobuf:"varint,5,opt,name=refresh"`
+}
+
+// LoadSpec contains the specification to load an image from the local filesystem
+type LoadSpec struct {
+	// Input is the path of the image to load
+	Input string `json:"input" protobuf:"bytes,1,name=input"`
+	// Format of the image to load, one of oci, oci-archive or docker-archive
+	// defaults to detecting the format from the input
+	// +optional
+	Format ExportFormat `json:"format,omitempty" protobuf:"bytes,2,opt,name=format"`
+}
+
+// BuildahOptions contains the options for running buildah, such as running rootless in CI
+type BuildahOptions struct {
+	// Isolation of RUN instructions, one of oci, rootless or chroot
+	// defaults to the buildah default
+	// +optional
+	Isolation BuildahIsolation `json:"isolation,omitempty" protobuf:"bytes,1,opt,name=isolation"`
+	// UserNS is the user namespace of RUN instructions, e.g. host, auto or the path of a namespace
+	// +optional
+	UserNS string `json:"userns,omitempty" protobuf:"bytes,2,opt,name=userns"`
+	// UIDMap maps container UIDs to host UIDs in a new user namespace, in the form container-uid:host-uid:size
+	// +optional
+	// +listType=map
+	UIDMap []string `json:"uidMap,omitempty" protobuf:"bytes,3,opt,name=uidMap"`
+	// GIDMap maps container GIDs to host GIDs in a new user namespace, in the form container-gid:host-gid:size
+	// +optional
+	// +listType=map
+	GIDMap []string `json:"gidMap,omitempty" protobuf:"bytes,4,opt,name=gidMap"`
+	// StorageRoot is the root directory of buildah storage, used by every buildah command
+	// +optional
+	StorageRoot string `json:"storageRoot,omitempty" protobuf:"bytes,5,opt,name=storageRoot"`
+	// StorageRunRoot is the directory of buildah runtime state, used by every buildah command
+	// +optional
+	StorageRunRoot string `json:"storageRunRoot,omitempty" protobuf:"bytes,6,opt,name=storageRunRoot"`
+	// Layers caches an intermediate image for every instruction of a build
+	// defaults to false
+	// +optional
+	Layers bool `json:"layers,omitempty" protobuf:"bytes,7,opt,name=layers"`
+	// Format of built images, one of oci or docker
+	// defaults to oci
+	// +optional
+	Format ImageFormat `json:"format,omitempty" protobuf:"bytes,8,opt,name=format"`
+	// Network is the network mode of RUN instructions, e.g. host, none or private
+	// +optional
+	Network string `json:"network,omitempty" protobuf:"bytes,9,opt,name=network"`
+	// CgroupParent is the parent cgroup of RUN instructions
+	// +optional
+	CgroupParent string `json:"cgroupParent,omitempty" protobuf:"bytes,10,opt,name=cgroupParent"`
+	// CgroupNS is the cgroup namespace of RUN instructions, one of host or private
+	// +optional
+	CgroupNS string `json:"cgroupns,omitempty" protobuf:"bytes,11,opt,name=cgroupns"`
+}
+
+// RegistryOptions contains the options of the registry builder, which pulls and pushes images without a daemon
+type RegistryOptions struct {
+	// LayoutPath is the OCI image layout directory images are pulled into and pushed from
+	// defaults to .ocibuilder/images in the home directory
+	// +optional
+	LayoutPath string `json:"layoutPath,omitempty" protobuf:"bytes,1,opt,name=layoutPath"`
+	// Insecure allows registries to be accessed over plain http
+	// defaults to false
+	// +optional
+	Insecure bool `json:"insecure,omitempty" protobuf:"bytes,2,opt,name=insecure"`
 }
 
 // BuildTemplate represents the build template that can shared across different builds
@@ -160,6 +329,49 @@ type BuildTemplate struct {
 	// List of cmds in a Dockerfile
 	// +listType=
 	Cmd []BuildTemplateStep `json:"cmd" protobuf:"bytes,2,rep,name=steps"`
+	// Inputs are the inputs of the template, which are substituted into the cmds of the template
+	// as go template values e.g. {{ .goVersion }}
+	// +optional
+	// +listType=
+	Inputs []TemplateInput `json:"inputs,omitempty" protobuf:"bytes,3,rep,name=inputs"`
+}
+
+// TemplateInputType is the type of the value of a template input
+type TemplateInputType string
+
+const (
+	// StringTemplateInput is an input with any value
+	StringTemplateInput TemplateInputType = "string"
+	// NumberTemplateInput is an input with a numeric value
+	NumberTemplateInput TemplateInputType = "number"
+	// BooleanTemplateInput is an input with a value of true or false
+	BooleanTemplateInput TemplateInputType = "boolean"
+)
+
+// TemplateInput is an input of a build template
+type TemplateInput struct {
+	// Name of the input, which must be a valid go template identifier
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// Type of the input, one of string, number or boolean
+	// defaults to string
+	// +optional
+	Type TemplateInputType `json:"type,omitempty" protobuf:"bytes,2,opt,name=type"`
+	// Default is the value of the input when no value is passed to the template
+	// +optional
+	Default string `json:"default,omitempty" protobuf:"bytes,3,opt,name=default"`
+	// Required inputs must be passed a value by each stage using the template
+	// +optional
+	Required bool `json:"required,omitempty" protobuf:"varint,4,opt,name=required"`
+}
+
+// TemplateRef refers to a build template with the values of its inputs. A template ref is either the name
+// of the template, or an object with the name of the template and the values of its inputs.
+type TemplateRef struct {
+	// Name of the build template
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// With are the values of the inputs of the template
+	// +optional
+	With map[string]string `json:"with,omitempty" protobuf:"bytes,2,rep,name=with"`
 }
 
 // BuildTemplateStep represents a step within build template
@@ -168,6 +380,78 @@ type BuildTemplateStep struct {
 	Docker *DockerStep `json:"docker,omitempty" protobuf:"bytes,1,opt,name=docker"`
 	// Ansible represents a ansible step within build template steps
 	Ansible *AnsibleStep `json:"ansible,omitempty" protobuf:"bytes,2,opt,name=ansible"`
+	// Packages represents a package installation step within build template steps
+	Packages *PackagesStep `json:"packages,omitempty" protobuf:"bytes,3,opt,name=packages"`
+}
+
+// PackageManager is the package manager used to install packages
+type PackageManager string
+
+const (
+	// AutoPackageManager detects the package manager from the base image of the stage
+	AutoPackageManager PackageManager = "auto"
+	// AptPackageManager is the package manager of Debian and Ubuntu images
+	AptPackageManager PackageManager = "apt"
+	// ApkPackageManager is the package manager of Alpine images
+	ApkPackageManager PackageManager = "apk"
+	// YumPackageManager is the package manager of CentOS, RHEL and Amazon Linux images
+	YumPackageManager PackageManager = "yum"
+	// DnfPackageManager is the package manager of Fedora images
+	DnfPackageManager PackageManager = "dnf"
+)
+
+// PackagesStep installs packages with a package manager in a single RUN instruction, cleaning
+// up the package manager cache in the same layer
+type PackagesStep struct {
+	// Manager is the package manager, one of auto, apt, apk, yum or dnf
+	// defaults to auto
+	// +optional
+	Manager PackageManager `json:"manager,omitempty" protobuf:"bytes,1,opt,name=manager"`
+	// Packages to install
+	// +listType=map
+	Packages []Package `json:"packages" protobuf:"bytes,2,rep,name=packages"`
+}
+
+// Package is a package installed by a packages step
+type Package struct {
+	// Name of the package
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// Version the package is pinned to, in the version format of the package manager
+	// +optional
+	Version string `json:"version,omitempty" protobuf:"bytes,2,opt,name=version"`
+}
+
+// DockerSpec contains the configuration of the docker daemon, such as a remote daemon on a dedicated build host
+type DockerSpec struct {
+	// Host is the address of the docker daemon e.g. tcp://build-host:2376
+	// defaults to $DOCKER_HOST or the local daemon
+	// +optional
+	Host string `json:"host,omitempty" protobuf:"bytes,1,opt,name=host"`
+	// APIVersion is the version of the docker API
+	// defaults to negotiating the version with the daemon
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty" protobuf:"bytes,2,opt,name=apiVersion"`
+	// TLS secures the connection to the docker daemon
+	// +optional
+	TLS *DockerTLS `json:"tls,omitempty" protobuf:"bytes,3,opt,name=tls"`
+}
+
+// DockerTLS contains the PEM encoded certificates of a TLS secured docker daemon
+type DockerTLS struct {
+	// CA is the certificate authority the daemon certificate is verified with
+	// defaults to the system certificate authorities
+	// +optional
+	CA *Credentials `json:"ca,omitempty" protobuf:"bytes,1,opt,name=ca"`
+	// Cert is the client certificate, required by daemons which verify clients
+	// +optional
+	Cert *Credentials `json:"cert,omitempty" protobuf:"bytes,2,opt,name=cert"`
+	// Key is the key of the client certificate
+	// +optional
+	Key *Credentials `json:"key,omitempty" protobuf:"bytes,3,opt,name=key"`
+	// InsecureSkipVerify skips verifying the daemon certificate
+	// defaults to false
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" protobuf:"bytes,4,opt,name=insecureSkipVerify"`
 }
 
 // DockerStep represents a step within a build that contains docker commands
@@ -208,15 +492,25 @@ type BuildStep struct {
 	// Git url to fetch the project from.
 	// +optional
 	Git string `json:"git,omitempty" protobuf:"bytes,3,opt,name=git"`
-	// Tag the tag of the build
+	// Tag the tag of the build, this can be a go template e.g. {{ .Git.ShortSHA }}
 	// +optional
 	Tag string `json:"tag,omitempty" protobuf:"bytes,4,opt,name=tag"`
+	// Tags are additional tags of the build which are applied alongside Tag
+	// +optional
+	// +listType=map
+	Tags []string `json:"tags,omitempty" protobuf:"bytes,9,opt,name=tags"`
 	// Distroless if set to true generates a distroless image
 	Distroless bool `json:"distroless,omitempty" protobuf:"bytes,5,opt,name=distroless"`
+	// DistrolessConfig configures the final distroless stage when Distroless is set
+	// +optional
+	DistrolessConfig *DistrolessConfig `json:"distrolessConfig,omitempty" protobuf:"bytes,11,opt,name=distrolessConfig"`
 	// Cache for build
 	// Set to false by default
 	// +optional
 	Cache bool `json:"cache,omitempty" protobuf:"bytes,6,opt,name=cache"`
+	// CacheRepo is the repository cached layers are pushed to and pulled from, used for Kaniko builds
+	// +optional
+	CacheRepo string `json:"cacheRepo,omitempty" protobuf:"bytes,13,opt,name=cacheRepo"`
 	// Purge the build
 	// defaults to false
 	// +optional
@@ -225,6 +519,99 @@ type BuildStep struct {
 	// default looks at the current working directory
 	// +optional
 	BuildContext *BuildContext `json:"context,omitempty" protobuf:"bytes,8,opt,name=context"`
+	// Export writes the built image to the local filesystem in the specified formats
+	// +optional
+	// +listType=map
+	Export []ExportSpec `json:"export,omitempty" protobuf:"bytes,10,opt,name=export"`
+	// Test contains structure tests which the built image must pass before it can be pushed
+	// +optional
+	Test *ImageTest `json:"test,omitempty" protobuf:"bytes,12,opt,name=test"`
+}
+
+// ImageTest contains structure tests which are run against a built image
+type ImageTest struct {
+	// Commands are commands run in a container of the image
+	// +optional
+	// +listType=map
+	Commands []CommandTest `json:"commands,omitempty" protobuf:"bytes,1,opt,name=commands"`
+	// Files are files which must or must not exist in the image
+	// +optional
+	// +listType=map
+	Files []FileTest `json:"files,omitempty" protobuf:"bytes,2,opt,name=files"`
+	// Metadata is the expected configuration of the image
+	// +optional
+	Metadata *MetadataTest `json:"metadata,omitempty" protobuf:"bytes,3,opt,name=metadata"`
+	// MaxSize is the maximum size of the image e.g. 250MB
+	// +optional
+	MaxSize string `json:"maxSize,omitempty" protobuf:"bytes,4,opt,name=maxSize"`
+}
+
+// CommandTest is a command run in a container of the image and its expected output
+type CommandTest struct {
+	// Name of the test
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+	// Command to run in the container, replacing the entrypoint of the image
+	// +listType=map
+	Command []string `json:"command" protobuf:"bytes,2,name=command"`
+	// ExitCode is the expected exit code of the command
+	// defaults to 0
+	// +optional
+	ExitCode int `json:"exitCode,omitempty" protobuf:"bytes,3,opt,name=exitCode"`
+	// ExpectedOutput are regexes which must match the stdout of the command
+	// +optional
+	// +listType=map
+	ExpectedOutput []string `json:"expectedOutput,omitempty" protobuf:"bytes,4,opt,name=expectedOutput"`
+	// ExcludedOutput are regexes which must not match the stdout of the command
+	// +optional
+	// +listType=map
+	ExcludedOutput []string `json:"excludedOutput,omitempty" protobuf:"bytes,5,opt,name=excludedOutput"`
+}
+
+// FileTest is a path which must or must not exist in the image
+type FileTest struct {
+	// Path of the file or directory
+	Path string `json:"path" protobuf:"bytes,1,name=path"`
+	// ShouldExist is whether the path must exist in the image
+	ShouldExist bool `json:"shouldExist" protobuf:"bytes,2,name=shouldExist"`
+}
+
+// MetadataTest is the expected configuration of the image
+type MetadataTest struct {
+	// Env are environment variables which must be set to the specified values
+	// +optional
+	Env map[string]string `json:"env,omitempty" protobuf:"bytes,1,opt,name=env"`
+	// Entrypoint is the expected entrypoint of the image
+	// +optional
+	// +listType=map
+	Entrypoint []string `json:"entrypoint,omitempty" protobuf:"bytes,2,opt,name=entrypoint"`
+	// ExposedPorts are ports which must be exposed e.g. 8080/tcp
+	// +optional
+	// +listType=map
+	ExposedPorts []string `json:"exposedPorts,omitempty" protobuf:"bytes,3,opt,name=exposedPorts"`
+	// Labels are labels which must be set to the specified values
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,4,opt,name=labels"`
+}
+
+// ExportSpec contains the specification to export a built image to the local filesystem
+type ExportSpec struct {
+	// Format of the exported image, one of oci, oci-archive or docker-archive
+	Format ExportFormat `json:"format" protobuf:"bytes,1,name=format"`
+	// Path to export the image to, this is a directory for the oci format and a file for archive formats
+	Path string `json:"path" protobuf:"bytes,2,name=path"`
+}
+
+// DistrolessConfig contains the configuration of the distroless stage appended to a build step
+type DistrolessConfig struct {
+	// Base is the base image of the distroless stage, e.g. scratch
+	// defaults to gcr.io/distroless/base
+	// +optional
+	Base Base `json:"base,omitempty" protobuf:"bytes,1,opt,name=base"`
+	// Paths in the last stage to copy into the distroless stage
+	// defaults to the entrypoint binary of the last stage and its dynamic libraries
+	// +optional
+	// +listType=map
+	Paths []string `json:"paths,omitempty" protobuf:"bytes,2,opt,name=paths"`
 }
 
 // Stage represents a stage within the build
@@ -233,8 +620,8 @@ type Stage struct {
 	*ImageMetadata `json:"metadata,inline" protobuf:"bytes,1,name=metadata"`
 	// BaseImage refers to parent image for given build stage.
 	Base Base `json:"base" protobuf:"bytes,2,name=base"`
-	// Template refers to one of the build templates.
-	Template string `json:"template" protobuf:"bytes,3,name=template"`
+	// Template refers to one of the build templates, by name or by name with the values of its inputs.
+	Template TemplateRef `json:"template" protobuf:"bytes,3,name=template"`
 	// Cmd refers to a template defined in a stage without a template.
 	// +listType=map
 	Cmd []BuildTemplateStep `json:"cmd" protobuf:"bytes,4,name=cmd"`
@@ -322,14 +709,22 @@ type PlainCreds struct {
 type PushSpec struct {
 	// Registry is the name of the registry
 	Registry string `json:"registry" protobuf:"bytes,1,name=registry"`
+	// From is the name of the build step which built the image to push. The built image is tagged
+	// into the registry before it is pushed, and the image and tags default to those of the build step
+	// +optional
+	From string `json:"from,omitempty" protobuf:"bytes,9,opt,name=from"`
 	// Image to push
 	Image string `json:"image" protobuf:"bytes,2,name=image"`
 	// User is the name of kubernetes namespace
 	User string `json:"user" protobuf:"bytes,3,name=user"`
 	// Token required for the OCI complaint registry authentication
 	Token string `json:"token" protobuf:"bytes,4,name=token"`
-	// Tag version of the image (e.g: v0.1.1)
+	// Tag version of the image (e.g: v0.1.1), this can be a go template e.g. {{ .Git.ShortSHA }}
 	Tag string `json:"tag" protobuf:"bytes,5,name=tag"`
+	// Tags are additional tags of the image to push alongside Tag
+	// +optional
+	// +listType=map
+	Tags []string `json:"tags,omitempty" protobuf:"bytes,8,opt,name=tags"`
 	// Purge the image after it has been pushed
 	// defaults to false
 	// +optional
@@ -338,6 +733,30 @@ type PushSpec struct {
 	Overlay string `json:"overlay" protobuf:"bytes,7,name=overlay"`
 }
 
+// PullSpec contains the specification to pull an image from a registry
+type PullSpec struct {
+	// Image to pull
+	Image string `json:"image" protobuf:"bytes,1,name=image"`
+	// Tag of the image to pull, only one of tag or digest can be set
+	// defaults to latest
+	// +optional
+	Tag string `json:"tag,omitempty" protobuf:"bytes,2,opt,name=tag"`
+	// Digest of the image to pull e.g. sha256:...
+	// +optional
+	Digest string `json:"digest,omitempty" protobuf:"bytes,3,opt,name=digest"`
+	// Registry to pull the image from, credentials are taken from the login with a matching registry
+	// defaults to the registry of the image name
+	// +optional
+	Registry string `json:"registry,omitempty" protobuf:"bytes,4,opt,name=registry"`
+	// Platform of the image to pull e.g. linux/amd64
+	// +optional
+	Platform string `json:"platform,omitempty" protobuf:"bytes,5,opt,name=platform"`
+	// Policy for pulling the image, one of Always or IfNotPresent
+	// defaults to IfNotPresent
+	// +optional
+	Policy PullPolicy `json:"policy,omitempty" protobuf:"bytes,6,opt,name=policy"`
+}
+
 // NodeStatus describes the status for an individual node in the ocibuilder configurations.
 // A single node can represent one configuration.
 type NodeStatus struct {
@@ -364,6 +783,9 @@ type ImageBuildArgs struct {
 	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
 	// Tag is the tag of the build
 	Tag string `json:"tag" protobuf:"bytes,2,opt,name=tag"`
+	// Tags are all the rendered tags of the build, including Tag
+	// +optional
+	Tags []string `json:"tags,omitempty" protobuf:"bytes,10,opt,name=tags"`
 	// Dockerfile is the path to the generated Dockerfile
 	// +optional
 	Dockerfile string `json:"dockerfile,omitempty" protobuf:"bytes,3,opt,name=dockerfile"`
@@ -388,6 +810,18 @@ type ImageBuildArgs struct {
 	Cache bool `json:"cache,omitempty" protobuf:"bytes,8,opt,name=cache"`
 	// StorageDriver is a buildah flag for storage driver e.g. vfs
 	StorageDriver string `json:"storageDriver" protobuf:"bytes,9,name=storageDriver"`
+	// Export writes the built image to the local filesystem in the specified formats
+	// +optional
+	Export []ExportSpec `json:"export,omitempty" protobuf:"bytes,11,opt,name=export"`
+	// BuildID is the unique ID labelled on every stage of a purged build, used to prune intermediate images
+	// +optional
+	BuildID string `json:"buildId,omitempty" protobuf:"bytes,12,opt,name=buildId"`
+	// Test contains structure tests which the built image must pass
+	// +optional
+	Test *ImageTest `json:"test,omitempty" protobuf:"bytes,13,opt,name=test"`
+	// CacheRepo is the repository cached layers are pushed to and pulled from, used for Kaniko builds
+	// +optional
+	CacheRepo string `json:"cacheRepo,omitempty" protobuf:"bytes,14,opt,name=cacheRepo"`
 }
 
 // BuildContext stores the chosen build context for your build, this can be Local, S3 or Git
@@ -428,6 +862,8 @@ type Credentials struct {
 	Env string `json:"env,omitempty" protobuf:"bytes,2,opt,name=env"`
 	// KubeSecret refers to K8s secret that holds the credentials
 	KubeSecret *KubeSecretCredentials `json:"kubeSecret,omitempty" protobuf:"bytes,3,opt,name=kubeSecret"`
+	// File refers to credentials stored in a file
+	File string `json:"file,omitempty" protobuf:"bytes,4,opt,name=file"`
 }
 
 // S3Bucket contains information to describe an S3 Bucket
@@ -568,7 +1004,31 @@ type BuildProvenance struct {
 	ID string `json:"id,omitempty"`
 }
 
+// PushResult is the result of pushing an image to a registry
+type PushResult struct {
+	// Registry is the registry the image was pushed to
+	Registry string `json:"registry"`
+	// Image is the name of the pushed image
+	Image string `json:"image"`
+	// Tag is the tag of the pushed image
+	Tag string `json:"tag"`
+	// Digest is the manifest digest of the pushed image
+	Digest string `json:"digest"`
+	// Reference is the digest pinned reference of the pushed image e.g. docker.io/image@sha256:...
+	Reference string `json:"reference"`
+}
+
+// BuilderCapabilities are the capabilities of a builder client beyond building images
+type BuilderCapabilities struct {
+	// LocalImages is whether built images are stored locally, where they can be inspected, run and saved
+	// after the build e.g. for structure tests, metadata and exports
+	LocalImages bool
+	// BuildExports is whether exports are written as outputs of the build
+	BuildExports bool
+}
+
 // OCIBuildOptions are the build options for an ocibuilder build
+// +k8s:openapi-gen=false
 type OCIBuildOptions struct {
 	// ImageBuildOptions are standard Docker API image build options
 	types.ImageBuildOptions `json:"imageBuildOptions,inline" protobuf:"bytes,1,name=imageBuildOptions"`
@@ -580,9 +1040,17 @@ type OCIBuildOptions struct {
 	Context io.Reader `json:"context" protobuf:"bytes,4,name=context"`
 	// StorageDriver is a buildah flag for storage driver e.g. vfs
 	StorageDriver string `json:"storageDriver" protobuf:"bytes,5,name=storageDriver"`
+	// CacheRepo is the repository cached layers are pushed to and pulled from, used for Kaniko builds
+	CacheRepo string `json:"cacheRepo" protobuf:"bytes,6,name=cacheRepo"`
+	// Exports are written as outputs of the build by builders which do not store images locally, used for BuildKit builds
+	Exports []ExportSpec `json:"exports" protobuf:"bytes,7,name=exports"`
+	// Destinations are the registry references the image is pushed to as part of the build, used by builders
+	// which do not store images locally
+	Destinations []string `json:"destinations" protobuf:"bytes,8,name=destinations"`
 }
 
 // OCIBuildResponse is the build response from an ocibuilder build
+// +k8s:openapi-gen=false
 type OCIBuildResponse struct {
 	// ImageBuildResponse is standard build response from the Docker API
 	types.ImageBuildResponse `json:"imageBuildResponse,inline" protobuf:"bytes,1,name=imageBuildResponse"`
@@ -595,6 +1063,7 @@ type OCIBuildResponse struct {
 }
 
 // OCIPullOptions are the pull options for an ocibuilder pull
+// +k8s:openapi-gen=false
 type OCIPullOptions struct {
 	// ImagePullOptions are the standard Docker API pull options
 	types.ImagePullOptions `json:"imagePullOptions,inline" protobuf:"bytes,1,name=imagePullOptions"`
@@ -605,6 +1074,7 @@ type OCIPullOptions struct {
 }
 
 // OCIPullResponse is the pull response from an ocibuilder pull
+// +k8s:openapi-gen=false
 type OCIPullResponse struct {
 	// Body is the body of the response from an ocibuilder pull
 	Body io.ReadCloser `json:"body,inline" protobuf:"bytes,1,name=body"`
@@ -615,6 +1085,7 @@ type OCIPullResponse struct {
 }
 
 // OCIPushOptions are the pull options for an ocibuilder push
+// +k8s:openapi-gen=false
 type OCIPushOptions struct {
 	// ImagePushOptions are the standard Docker API push options
 	types.ImagePushOptions `json:"imagePushOptions,inline" protobuf:"bytes,1,name=imagePushOptions"`
@@ -622,9 +1093,12 @@ type OCIPushOptions struct {
 	Ref string `json:"ref,inline" protobuf:"bytes,2,name=ref"`
 	// Ctx is the goroutine context
 	Ctx ctx.Context `json:"ctx,inline" protobuf:"bytes3,name=ctx"`
+	// DigestFile is the path the digest of the pushed image is written to, used for Buildah pushes
+	DigestFile string `json:"digestFile,inline" protobuf:"bytes,4,name=digestFile"`
 }
 
 // OCIPushResponse is the push response from an ocibuilder push
+// +k8s:openapi-gen=false
 type OCIPushResponse struct {
 	// Body is the body of the response from an ocibuilder push
 	Body io.ReadCloser `json:"body,inline" protobuf:"bytes,1,name=body"`
@@ -632,11 +1106,14 @@ type OCIPushResponse struct {
 	Exec *command.Command `json:"exec,inline" protobuf:"bytes,2,name=exec"`
 	// Stderr is the stderr output stream used to stream buildah response
 	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,3,name=stderr"`
+	// Digest is the manifest digest of the pushed image
+	Digest string `json:"digest,inline" protobuf:"bytes,4,name=digest"`
 	// Finished is the flag to determine that the response has finished being read
 	Finished bool
 }
 
 // OCIRemoveOptions are the remove options for an ocibuilder remove
+// +k8s:openapi-gen=false
 type OCIRemoveOptions struct {
 	// ImageRemoveOptions are the standard Docker API remove options
 	types.ImageRemoveOptions `json:"imageRemoveOptions,inline" protobuf:"bytes,1,name=imageRemoveOptions"`
@@ -647,6 +1124,7 @@ type OCIRemoveOptions struct {
 }
 
 // OCIRemoveResponse is the response from an ocibuilder remove
+// +k8s:openapi-gen=false
 type OCIRemoveResponse struct {
 	// Response are the responses from an image delete
 	Response []types.ImageDeleteResponseItem `json:"response,inline" protobuf:"bytes,1,name=response"`
@@ -657,6 +1135,7 @@ type OCIRemoveResponse struct {
 }
 
 // OCILoginOptions are the login options for an ocibuilder login
+// +k8s:openapi-gen=false
 type OCILoginOptions struct {
 	// AuthConfig is the standard auth config for the Docker API
 	types.AuthConfig `json:"authConfig,inline" protobuf:"bytes,1,name=authConfig"`
@@ -665,6 +1144,7 @@ type OCILoginOptions struct {
 }
 
 // OCILoginResponse is the login response from an ocibuilder login
+// +k8s:openapi-gen=false
 type OCILoginResponse struct {
 	// AuthenticateOKBody is the standar login response from the Docker API
 	registry.AuthenticateOKBody
@@ -674,6 +1154,116 @@ type OCILoginResponse struct {
 	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,3,name=stderr"`
 }
 
+// OCIPruneOptions are the options for an ocibuilder prune of dangling images
+// +k8s:openapi-gen=false
+type OCIPruneOptions struct {
+	// Labels are the labels in format key=value which images must have to be pruned
+	Labels []string `json:"labels,inline" protobuf:"bytes,1,name=labels"`
+	// Ctx is the goroutine context
+	Ctx ctx.Context `json:"ctx,inline" protobuf:"bytes,2,name=ctx"`
+}
+
+// OCIPruneResponse is the response from an ocibuilder prune of dangling images
+// +k8s:openapi-gen=false
+type OCIPruneResponse struct {
+	// ImagesPruneReport is the standard prune report from the Docker API
+	types.ImagesPruneReport `json:"imagesPruneReport,inline" protobuf:"bytes,1,name=imagesPruneReport"`
+	// Exec is part of the response for Buildah command executions
+	Exec *command.Command `json:"exec,inline" protobuf:"bytes,2,name=exec"`
+	// Stderr is the stderr output stream used to stream buildah response
+	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,3,name=stderr"`
+}
+
+// OCITagOptions are the options for an ocibuilder image tag
+// +k8s:openapi-gen=false
+type OCITagOptions struct {
+	// Source is the name of the image to tag
+	Source string `json:"source,inline" protobuf:"bytes,1,name=source"`
+	// Target is the new name of the image
+	Target string `json:"target,inline" protobuf:"bytes,2,name=target"`
+	// Ctx is the goroutine context
+	Ctx ctx.Context `json:"ctx,inline" protobuf:"bytes,3,name=ctx"`
+}
+
+// OCITagResponse is the response from an ocibuilder image tag
+// +k8s:openapi-gen=false
+type OCITagResponse struct {
+	// Body is the body of the response from an ocibuilder tag
+	Body io.ReadCloser `json:"body,inline" protobuf:"bytes,1,name=body"`
+	// Exec is part of the response for Buildah command executions
+	Exec *command.Command `json:"exec,inline" protobuf:"bytes,2,name=exec"`
+	// Stderr is the stderr output stream used to stream buildah response
+	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,3,name=stderr"`
+}
+
+// OCISaveOptions are the options for an ocibuilder image export
+// +k8s:openapi-gen=false
+type OCISaveOptions struct {
+	// Image is the name of the image to export
+	Image string `json:"image,inline" protobuf:"bytes,1,name=image"`
+	// Format is the format to export the image to
+	Format ExportFormat `json:"format,inline" protobuf:"bytes,2,name=format"`
+	// Dest is the path to export the image to
+	Dest string `json:"dest,inline" protobuf:"bytes,3,name=dest"`
+	// Ctx is the goroutine context
+	Ctx ctx.Context `json:"ctx,inline" protobuf:"bytes,4,name=ctx"`
+}
+
+// OCISaveResponse is the response from an ocibuilder image export
+// +k8s:openapi-gen=false
+type OCISaveResponse struct {
+	// Body is the body of the response from an ocibuilder export
+	Body io.ReadCloser `json:"body,inline" protobuf:"bytes,1,name=body"`
+	// Exec is part of the response for Buildah command executions
+	Exec *command.Command `json:"exec,inline" protobuf:"bytes,2,name=exec"`
+	// Stderr is the stderr output stream used to stream buildah response
+	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,3,name=stderr"`
+}
+
+// OCIRunOptions are the options for running a command in a container of an image
+// +k8s:openapi-gen=false
+type OCIRunOptions struct {
+	// Image is the name of the image to run
+	Image string `json:"image,inline" protobuf:"bytes,1,name=image"`
+	// Cmd is the command to run, replacing the entrypoint of the image
+	Cmd []string `json:"cmd,inline" protobuf:"bytes,2,name=cmd"`
+	// Ctx is the goroutine context
+	Ctx ctx.Context `json:"ctx,inline" protobuf:"bytes,3,name=ctx"`
+}
+
+// OCIRunResponse is the response from running a command in a container of an image
+// +k8s:openapi-gen=false
+type OCIRunResponse struct {
+	// Body is the stdout of the command
+	Body io.ReadCloser `json:"body,inline" protobuf:"bytes,1,name=body"`
+	// Stderr is the stderr of the command
+	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,2,name=stderr"`
+	// ExitCode is the exit code of the command
+	ExitCode int `json:"exitCode,inline" protobuf:"bytes,3,name=exitCode"`
+}
+
+// OCILoadOptions are the options for an ocibuilder image load
+// +k8s:openapi-gen=false
+type OCILoadOptions struct {
+	// Input is the path of the image to load
+	Input string `json:"input,inline" protobuf:"bytes,1,name=input"`
+	// Format is the format of the image to load
+	Format ExportFormat `json:"format,inline" protobuf:"bytes,2,name=format"`
+	// Ctx is the goroutine context
+	Ctx ctx.Context `json:"ctx,inline" protobuf:"bytes,3,name=ctx"`
+}
+
+// OCILoadResponse is the response from an ocibuilder image load
+// +k8s:openapi-gen=false
+type OCILoadResponse struct {
+	// Body is the body of the response from an ocibuilder load
+	Body io.ReadCloser `json:"body,inline" protobuf:"bytes,1,name=body"`
+	// Exec is part of the response for Buildah command executions
+	Exec *command.Command `json:"exec,inline" protobuf:"bytes,2,name=exec"`
+	// Stderr is the stderr output stream used to stream buildah response
+	Stderr io.ReadCloser `json:"stderr,inline" protobuf:"bytes,3,name=stderr"`
+}
+
 // GenerateTemplate is the template for a docker generate
 type GenerateTemplate struct {
 	ImageName string
@@ -185,6 +185,32 @@ func (in *BuildGenTemplate) DeepCopy() *BuildGenTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildahOptions) DeepCopyInto(out *BuildahOptions) {
+	*out = *in
+	if in.UIDMap != nil {
+		in, out := &in.UIDMap, &out.UIDMap
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GIDMap != nil {
+		in, out := &in.GIDMap, &out.GIDMap
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildahOptions.
+func (in *BuildahOptions) DeepCopy() *BuildahOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildahOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildSpec) DeepCopyInto(out *BuildSpec) {
 	*out = *in
@@ -202,6 +228,18 @@ func (in *BuildSpec) DeepCopyInto(out *BuildSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Preload != nil {
+		in, out := &in.Preload, &out.Preload
+		*out = make([]LoadSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.TemplateSources != nil {
+		in, out := &in.TemplateSources, &out.TemplateSources
+		*out = make([]TemplateSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -230,11 +268,31 @@ func (in *BuildStep) DeepCopyInto(out *BuildStep) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DistrolessConfig != nil {
+		in, out := &in.DistrolessConfig, &out.DistrolessConfig
+		*out = new(DistrolessConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BuildContext != nil {
 		in, out := &in.BuildContext, &out.BuildContext
 		*out = new(BuildContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = make([]ExportSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Test != nil {
+		in, out := &in.Test, &out.Test
+		*out = new(ImageTest)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -258,6 +316,11 @@ func (in *BuildTemplate) DeepCopyInto(out *BuildTemplate) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]TemplateInput, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -284,6 +347,11 @@ func (in *BuildTemplateStep) DeepCopyInto(out *BuildTemplateStep) {
 		*out = new(AnsibleStep)
 		**out = **in
 	}
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = new(PackagesStep)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -323,6 +391,37 @@ func (in *Command) DeepCopy() *Command {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommandTest) DeepCopyInto(out *CommandTest) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedOutput != nil {
+		in, out := &in.ExpectedOutput, &out.ExpectedOutput
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedOutput != nil {
+		in, out := &in.ExcludedOutput, &out.ExcludedOutput
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandTest.
+func (in *CommandTest) DeepCopy() *CommandTest {
+	if in == nil {
+		return nil
+	}
+	out := new(CommandTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Credentials) DeepCopyInto(out *Credentials) {
 	*out = *in
@@ -344,6 +443,80 @@ func (in *Credentials) DeepCopy() *Credentials {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistrolessConfig) DeepCopyInto(out *DistrolessConfig) {
+	*out = *in
+	out.Base = in.Base
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistrolessConfig.
+func (in *DistrolessConfig) DeepCopy() *DistrolessConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DistrolessConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DockerSpec) DeepCopyInto(out *DockerSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(DockerTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerSpec.
+func (in *DockerSpec) DeepCopy() *DockerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DockerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DockerTLS) DeepCopyInto(out *DockerTLS) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(Credentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = new(Credentials)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(Credentials)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerTLS.
+func (in *DockerTLS) DeepCopy() *DockerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(DockerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DockerStep) DeepCopyInto(out *DockerStep) {
 	*out = *in
@@ -382,6 +555,38 @@ func (in *EnvCreds) DeepCopy() *EnvCreds {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportSpec) DeepCopyInto(out *ExportSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportSpec.
+func (in *ExportSpec) DeepCopy() *ExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileTest) DeepCopyInto(out *FileTest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileTest.
+func (in *FileTest) DeepCopy() *FileTest {
+	if in == nil {
+		return nil
+	}
+	out := new(FileTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GCSContext) DeepCopyInto(out *GCSContext) {
 	*out = *in
@@ -506,6 +711,11 @@ func (in *Grafeas) DeepCopy() *Grafeas {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageBuildArgs) DeepCopyInto(out *ImageBuildArgs) {
 	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make(map[string]string, len(*in))
@@ -513,6 +723,16 @@ func (in *ImageBuildArgs) DeepCopyInto(out *ImageBuildArgs) {
 			(*out)[key] = val
 		}
 	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = make([]ExportSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Test != nil {
+		in, out := &in.Test, &out.Test
+		*out = new(ImageTest)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -556,6 +776,39 @@ func (in *ImageMetadata) DeepCopy() *ImageMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageTest) DeepCopyInto(out *ImageTest) {
+	*out = *in
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make([]CommandTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]FileTest, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(MetadataTest)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageTest.
+func (in *ImageTest) DeepCopy() *ImageTest {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *K8sCreds) DeepCopyInto(out *K8sCreds) {
 	*out = *in
@@ -619,6 +872,22 @@ func (in *LocalContext) DeepCopy() *LocalContext {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadSpec) DeepCopyInto(out *LoadSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadSpec.
+func (in *LoadSpec) DeepCopy() *LoadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoginSpec) DeepCopyInto(out *LoginSpec) {
 	*out = *in
@@ -667,6 +936,46 @@ func (in *Metadata) DeepCopy() *Metadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataTest) DeepCopyInto(out *MetadataTest) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Entrypoint != nil {
+		in, out := &in.Entrypoint, &out.Entrypoint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExposedPorts != nil {
+		in, out := &in.ExposedPorts, &out.ExposedPorts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataTest.
+func (in *MetadataTest) DeepCopy() *MetadataTest {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
 	*out = *in
@@ -785,13 +1094,35 @@ func (in *OCIBuilderSpec) DeepCopyInto(out *OCIBuilderSpec) {
 	if in.Push != nil {
 		in, out := &in.Push, &out.Push
 		*out = make([]PushSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Metadata != nil {
 		in, out := &in.Metadata, &out.Metadata
 		*out = new(Metadata)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Pull != nil {
+		in, out := &in.Pull, &out.Pull
+		*out = make([]PullSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Buildah != nil {
+		in, out := &in.Buildah, &out.Buildah
+		*out = new(BuildahOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Docker != nil {
+		in, out := &in.Docker, &out.Docker
+		*out = new(DockerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(RegistryOptions)
+		**out = **in
+	}
 	return
 }
 
@@ -837,6 +1168,43 @@ func (in *OCIBuilderStatus) DeepCopy() *OCIBuilderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Package) DeepCopyInto(out *Package) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Package.
+func (in *Package) DeepCopy() *Package {
+	if in == nil {
+		return nil
+	}
+	out := new(Package)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackagesStep) DeepCopyInto(out *PackagesStep) {
+	*out = *in
+	if in.Packages != nil {
+		in, out := &in.Packages, &out.Packages
+		*out = make([]Package, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackagesStep.
+func (in *PackagesStep) DeepCopy() *PackagesStep {
+	if in == nil {
+		return nil
+	}
+	out := new(PackagesStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Param) DeepCopyInto(out *Param) {
 	*out = *in
@@ -869,9 +1237,30 @@ func (in *PlainCreds) DeepCopy() *PlainCreds {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullSpec) DeepCopyInto(out *PullSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullSpec.
+func (in *PullSpec) DeepCopy() *PullSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PullSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PushSpec) DeepCopyInto(out *PushSpec) {
 	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -904,6 +1293,22 @@ func (in *RegistryCreds) DeepCopy() *RegistryCreds {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryOptions) DeepCopyInto(out *RegistryOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryOptions.
+func (in *RegistryOptions) DeepCopy() *RegistryOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteCreds) DeepCopyInto(out *RemoteCreds) {
 	*out = *in
@@ -995,6 +1400,7 @@ func (in *Stage) DeepCopyInto(out *Stage) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.Base = in.Base
+	in.Template.DeepCopyInto(&out.Template)
 	if in.Cmd != nil {
 		in, out := &in.Cmd, &out.Cmd
 		*out = make([]BuildTemplateStep, len(*in))
@@ -1051,3 +1457,64 @@ func (in *StoreConfig) DeepCopy() *StoreConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateInput) DeepCopyInto(out *TemplateInput) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateInput.
+func (in *TemplateInput) DeepCopy() *TemplateInput {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateRef) DeepCopyInto(out *TemplateRef) {
+	*out = *in
+	if in.With != nil {
+		in, out := &in.With, &out.With
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateRef.
+func (in *TemplateRef) DeepCopy() *TemplateRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSource) DeepCopyInto(out *TemplateSource) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitContext)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Auth = in.Auth
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSource.
+func (in *TemplateSource) DeepCopy() *TemplateSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSource)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,42 +17,91 @@ limitations under the License.
 package buildah
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/registry"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/ocibuilder/ocibuilder/pkg/validate"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 // Client is the client used for building with Buildah using the ocibuilder
 type Client struct {
 	Logger *logrus.Logger
+	// Options are the options for running buildah, such as the isolation and storage of rootless builds
+	Options *v1alpha1.BuildahOptions
 }
 
-// ImageBuild conducts an image build with Buildah using the ocibuilder
+// buildahImage is the output of buildah inspect of an image
+type buildahImage struct {
+	FromImage       string `json:"FromImage"`
+	FromImageID     string `json:"FromImageID"`
+	FromImageDigest string `json:"FromImageDigest"`
+	OCIv1           struct {
+		Created      *time.Time `json:"created"`
+		Author       string     `json:"author"`
+		Architecture string     `json:"architecture"`
+		OS           string     `json:"os"`
+		// the fields of an OCI image config share their json names with a docker container config
+		Config *container.Config `json:"config"`
+		RootFS struct {
+			Type    string   `json:"type"`
+			DiffIDs []string `json:"diff_ids"`
+		} `json:"rootfs"`
+		History []struct {
+			Created   *time.Time `json:"created"`
+			CreatedBy string     `json:"created_by"`
+			Comment   string     `json:"comment"`
+		} `json:"history"`
+	} `json:"OCIv1"`
+}
+
+// ImageBuild conducts an image build with Buildah using the ocibuilder. The body of the response is the buildah
+// output converted to a stream of docker json messages as it arrives.
 func (cli Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
 
 	buildFlags := []command.Flag{
 		{Name: "f", Value: options.Dockerfile, Short: true, OmitEmpty: true},
 		{Name: "storage-driver", Value: options.StorageDriver, Short: false, OmitEmpty: true},
-		{Name: "t", Value: options.Tags[0], Short: true, OmitEmpty: true},
+	}
+
+	for _, t := range options.Tags {
+		buildFlags = append(buildFlags, command.Flag{Name: "t", Value: t, Short: true, OmitEmpty: true})
 	}
 
 	if options.NoCache {
-		buildFlags = append(buildFlags, command.Flag{Name: "no-cache", Value: "", Short: false, OmitEmpty: true})
+		buildFlags = append(buildFlags, command.Flag{Name: "no-cache", Value: "", Short: false, OmitEmpty: false})
 	}
 
-	for _, l := range options.Labels {
-		buildFlags = append(buildFlags, command.Flag{Name: "label", Value: l, Short: false, OmitEmpty: true})
+	if cli.Options != nil {
+		if err := validate.ValidateBuildahOptions(*cli.Options); err != nil {
+			return v1alpha1.OCIBuildResponse{}, err
+		}
+		buildFlags = append(buildFlags, buildahFlags(*cli.Options)...)
 	}
 
-	cmd := command.Builder("buildah").Command("bud").Flags(buildFlags...).Args(options.ContextPath).Build()
+	var labelKeys []string
+	for k := range options.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		buildFlags = append(buildFlags, command.Flag{Name: "label", Value: fmt.Sprintf("%s=%s", k, options.Labels[k]), Short: false, OmitEmpty: true})
+	}
+
+	cmd := cli.builder().Command("bud").Flags(buildFlags...).Args(options.ContextPath).Build()
 	cli.Logger.WithField("cmd", cmd).Debugln("executing build with command")
 
 	stdout, stderr, err := execute(&cmd)
@@ -62,22 +111,26 @@ func (cli Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuil
 	}
 	return v1alpha1.OCIBuildResponse{
 		ImageBuildResponse: types.ImageBuildResponse{
-			Body: stdout,
+			Body: ProgressReader(stdout, stderr),
 		},
-		Exec:   &cmd,
-		Stderr: stderr,
+		Exec: &cmd,
 	}, nil
 }
 
 // ImagePull conducts an image pull with Buildah using the ocibuilder
 func (cli Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullResponse, error) {
 
+	authFile, tempFiles, err := writeAuthFile(options.Ref, options.RegistryAuth)
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+
 	pullFlags := []command.Flag{
-		// Buildah registry auth in format username[:password]
-		{Name: "creds", Value: options.RegistryAuth, Short: false, OmitEmpty: true},
+		{Name: "authfile", Value: authFile, Short: false, OmitEmpty: true},
+		{Name: "platform", Value: options.Platform, Short: false, OmitEmpty: true},
 	}
 
-	cmd := command.Builder("buildah").Command("pull").Flags(pullFlags...).Args(options.Ref).Build()
+	cmd := cli.builder().Command("pull").Flags(pullFlags...).Args(options.Ref).TempFiles(tempFiles...).Build()
 	cli.Logger.WithField("cmd", cmd).Debugln("executing pull with command")
 
 	stdout, stderr, err := execute(&cmd)
@@ -95,12 +148,17 @@ func (cli Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullRe
 // ImagePush conducts an image push with Buildah using the ocibuilder
 func (cli Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
 
+	authFile, tempFiles, err := writeAuthFile(options.Ref, options.RegistryAuth)
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+
 	pushFlags := []command.Flag{
-		// Buildah registry auth in format username[:password]
-		{Name: "creds", Value: options.RegistryAuth, Short: false, OmitEmpty: true},
+		{Name: "authfile", Value: authFile, Short: false, OmitEmpty: true},
+		{Name: "digestfile", Value: options.DigestFile, Short: false, OmitEmpty: true},
 	}
 
-	cmd := command.Builder("buildah").Command("push").Flags(pushFlags...).Args(options.Ref).Build()
+	cmd := cli.builder().Command("push").Flags(pushFlags...).Args(options.Ref).TempFiles(tempFiles...).Build()
 	cli.Logger.WithField("cmd", cmd).Debugln("executing push with command")
 
 	stdout, stderr, err := execute(&cmd)
@@ -118,10 +176,10 @@ func (cli Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushRe
 // ImageRemove conducts an image remove with Buildah using the ocibuilder
 func (cli Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
 
-	cmd := command.Builder("buildah").Command("rmi").Args(options.Image).Build()
+	cmd := cli.builder().Command("rmi").Args(options.Image).Build()
 	cli.Logger.WithField("cmd", cmd).Debugln("executing remove with command")
 
-	_, _, err := execute(&cmd)
+	_, stderr, err := execute(&cmd)
 	if err != nil {
 		cli.Logger.WithError(err).Errorln("error building image...")
 		return v1alpha1.OCIRemoveResponse{}, err
@@ -132,43 +190,204 @@ func (cli Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRe
 				Deleted: options.Image,
 			},
 		},
-		Exec: &cmd,
+		Exec:   &cmd,
+		Stderr: stderr,
 	}, nil
 }
 
-// @TBC
-// ImageInspect conducts an inspect of a build image with Buildah using the ocibuilder
-//
-// The type currently returned by running buildah inspect varies greatly to docker image inspect
-// issues have been created to manage this and have a more representative mapping between buildah and docker
-func (cli Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+// ImagesPrune removes images with all the passed in labels with Buildah using the ocibuilder
+func (cli Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
 
-	inspectFlag := command.Flag{
-		Name: "type", Value: "image", Short: false, OmitEmpty: false,
+	imagesFlags := []command.Flag{
+		{Name: "quiet", Value: "", Short: false, OmitEmpty: false},
+	}
+	for _, l := range options.Labels {
+		imagesFlags = append(imagesFlags, command.Flag{Name: "filter", Value: "label=" + l, Short: false, OmitEmpty: true})
 	}
 
-	cmd := command.Builder("buildah").Command("inspect").Flags(inspectFlag).Args(imageId).Build()
-	cli.Logger.WithField("cmd", cmd).Debugln("executing remove with command")
+	imagesCmd := cli.builder().Command("images").Flags(imagesFlags...).Build()
+	cli.Logger.WithField("cmd", imagesCmd).Debugln("executing images with command")
 
-	stdout, _, err := execute(&cmd)
+	stdout, stderr, err := execute(&imagesCmd)
 	if err != nil {
-		cli.Logger.WithError(err).Errorln("error building image...")
-		return types.ImageInspect{}, err
+		cli.Logger.WithError(err).Errorln("error listing images...")
+		return v1alpha1.OCIPruneResponse{}, err
+	}
+	output, errOutput := command.ReadOutputs(stdout, stderr)
+	if err := wait(&imagesCmd); err != nil {
+		return v1alpha1.OCIPruneResponse{}, errors.Wrapf(err, "failed to list images to prune: %s", strings.TrimSpace(string(errOutput)))
+	}
+
+	// an image with several tags is listed once for each tag, but is removed once
+	var imageIds []string
+	listed := make(map[string]bool)
+	for _, id := range strings.Fields(string(output)) {
+		if !listed[id] {
+			listed[id] = true
+			imageIds = append(imageIds, id)
+		}
+	}
+	if len(imageIds) == 0 {
+		return v1alpha1.OCIPruneResponse{}, nil
+	}
+
+	cmd := cli.builder().Command("rmi").Args(imageIds...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing prune with command")
+
+	_, stderr, err = execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error pruning images...")
+		return v1alpha1.OCIPruneResponse{}, err
 	}
-	var imageInspect types.ImageInspect
-	res, err := ioutil.ReadAll(stdout)
+
+	var deleted []types.ImageDeleteResponseItem
+	for _, id := range imageIds {
+		deleted = append(deleted, types.ImageDeleteResponseItem{Deleted: id})
+	}
+	return v1alpha1.OCIPruneResponse{
+		ImagesPruneReport: types.ImagesPruneReport{
+			ImagesDeleted: deleted,
+		},
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImageTag tags an image with Buildah using the ocibuilder
+func (cli Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+
+	cmd := cli.builder().Command("tag").Args(options.Source, options.Target).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing tag with command")
+
+	stdout, stderr, err := execute(&cmd)
 	if err != nil {
-		return types.ImageInspect{}, err
+		cli.Logger.WithError(err).Errorln("error tagging image...")
+		return v1alpha1.OCITagResponse{}, err
 	}
-	if err := json.Unmarshal(res, &imageInspect); err != nil {
+	return v1alpha1.OCITagResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImageInspect conducts an inspect of an image with Buildah using the ocibuilder, mapping the OCI image config
+// of buildah inspect to a docker image inspect
+func (cli Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+	img, err := cli.inspect(imageId)
+	if err != nil {
 		return types.ImageInspect{}, err
 	}
-	return types.ImageInspect{}, nil
+
+	imageInspect := types.ImageInspect{
+		ID:           imageID(img.FromImageID),
+		Author:       img.OCIv1.Author,
+		Architecture: img.OCIv1.Architecture,
+		Os:           img.OCIv1.OS,
+		Config:       img.OCIv1.Config,
+		RootFS: types.RootFS{
+			Type:   img.OCIv1.RootFS.Type,
+			Layers: img.OCIv1.RootFS.DiffIDs,
+		},
+	}
+	if img.OCIv1.Created != nil {
+		imageInspect.Created = img.OCIv1.Created.Format(time.RFC3339Nano)
+	}
+	if img.FromImage != "" {
+		imageInspect.RepoTags = []string{img.FromImage}
+		if img.FromImageDigest != "" {
+			imageInspect.RepoDigests = []string{fmt.Sprintf("%s@%s", repository(img.FromImage), img.FromImageDigest)}
+		}
+	}
+	return imageInspect, nil
 }
 
-// ImageHistory is TBC for buildah client
+// ImageHistory conducts an image history call of an image with Buildah using the ocibuilder, mapping the history
+// of the OCI image config to a docker image history. As with docker, the history is ordered from the newest
+// entry and only the newest entry carries the image ID.
 func (cli Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
-	return nil, nil
+	img, err := cli.inspect(imageId)
+	if err != nil {
+		return nil, err
+	}
+
+	history := img.OCIv1.History
+	var historyResponse []image.HistoryResponseItem
+	for i := len(history) - 1; i >= 0; i-- {
+		item := image.HistoryResponseItem{
+			ID:        "<missing>",
+			CreatedBy: history[i].CreatedBy,
+			Comment:   history[i].Comment,
+		}
+		if history[i].Created != nil {
+			item.Created = history[i].Created.Unix()
+		}
+		if i == len(history)-1 {
+			item.ID = imageID(img.FromImageID)
+			if img.FromImage != "" {
+				item.Tags = []string{img.FromImage}
+			}
+		}
+		historyResponse = append(historyResponse, item)
+	}
+	if len(historyResponse) == 0 {
+		return nil, errors.Errorf("no history found for image %s", imageId)
+	}
+	return historyResponse, nil
+}
+
+// ImageSave exports an image to the local filesystem with Buildah using the ocibuilder
+func (cli Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+
+	switch options.Format {
+	case v1alpha1.OCIExportFormat, v1alpha1.OCIArchiveExportFormat, v1alpha1.DockerArchiveExportFormat:
+	default:
+		return v1alpha1.OCISaveResponse{}, errors.Errorf("unsupported export format %s", options.Format)
+	}
+
+	// Buildah push destination in format transport:path:reference
+	dest := fmt.Sprintf("%s:%s:%s", options.Format, options.Dest, options.Image)
+
+	cmd := cli.builder().Command("push").Args(options.Image, dest).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing export with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error exporting image...")
+		return v1alpha1.OCISaveResponse{}, err
+	}
+	return v1alpha1.OCISaveResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImageLoad loads an image from the local filesystem into Buildah storage using the ocibuilder
+func (cli Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+
+	switch options.Format {
+	case v1alpha1.OCIExportFormat, v1alpha1.OCIArchiveExportFormat, v1alpha1.DockerArchiveExportFormat:
+	default:
+		return v1alpha1.OCILoadResponse{}, errors.Errorf("unsupported load format %s", options.Format)
+	}
+
+	// Buildah pull source in format transport:path
+	src := fmt.Sprintf("%s:%s", options.Format, options.Input)
+
+	cmd := cli.builder().Command("pull").Args(src).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing load with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error loading image...")
+		return v1alpha1.OCILoadResponse{}, err
+	}
+	return v1alpha1.OCILoadResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
 }
 
 // RegistryLogin conducts a registry login with Buildah using the ocibuilder
@@ -176,10 +395,11 @@ func (cli Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCIL
 
 	loginFlags := []command.Flag{
 		{Name: "u", Value: options.Username, Short: true, OmitEmpty: true},
-		{Name: "p", Value: options.Password, Short: true, OmitEmpty: true},
+		{Name: "password-stdin", Value: "", Short: false, OmitEmpty: false},
 	}
 
-	cmd := command.Builder("buildah").Command("login").Flags(loginFlags...).Args(options.ServerAddress).Build()
+	// the password is passed on stdin to keep it out of process listings
+	cmd := cli.builder().Command("login").Flags(loginFlags...).Args(options.ServerAddress).Stdin(strings.NewReader(options.Password)).Build()
 	cli.Logger.WithField("cmd", cmd).Debugln("executing login with command")
 
 	_, _, err := execute(&cmd)
@@ -196,12 +416,186 @@ func (cli Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCIL
 	}, nil
 }
 
+// ContainerRun runs a command in a working container of an image with Buildah using the ocibuilder, removing the
+// working container once the command has exited
+func (cli Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	if len(options.Cmd) == 0 {
+		return v1alpha1.OCIRunResponse{}, errors.New("no command specified to run")
+	}
+
+	fromFlags := []command.Flag{{Name: "quiet", Value: "", Short: false, OmitEmpty: false}}
+	var runFlags []command.Flag
+	if cli.Options != nil {
+		if err := validate.ValidateBuildahOptions(*cli.Options); err != nil {
+			return v1alpha1.OCIRunResponse{}, err
+		}
+		// the id mappings of the user namespace are set when the working container is created, and the
+		// command is run with the same isolation and user namespace as the RUN instructions of builds
+		for _, mapping := range cli.Options.UIDMap {
+			fromFlags = append(fromFlags, command.Flag{Name: "userns-uid-map", Value: mapping, Short: false, OmitEmpty: true})
+		}
+		for _, mapping := range cli.Options.GIDMap {
+			fromFlags = append(fromFlags, command.Flag{Name: "userns-gid-map", Value: mapping, Short: false, OmitEmpty: true})
+		}
+		runFlags = []command.Flag{
+			{Name: "isolation", Value: string(cli.Options.Isolation), Short: false, OmitEmpty: true},
+			{Name: "userns", Value: cli.Options.UserNS, Short: false, OmitEmpty: true},
+		}
+	}
+
+	fromCmd := cli.builder().Command("from").Flags(fromFlags...).Args(options.Image).Build()
+	cli.Logger.WithField("cmd", fromCmd).Debugln("executing from with command")
+
+	stdout, stderr, err := execute(&fromCmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error creating working container...")
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	containerOutput, _ := command.ReadOutputs(stdout, stderr)
+	if err := wait(&fromCmd); err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	workingContainer := strings.TrimSpace(string(containerOutput))
+	if workingContainer == "" {
+		return v1alpha1.OCIRunResponse{}, errors.Errorf("no working container created from image %s", options.Image)
+	}
+
+	defer func() {
+		rmCmd := cli.builder().Command("rm").Args(workingContainer).Build()
+		cli.Logger.WithField("cmd", rmCmd).Debugln("executing rm with command")
+		if _, _, err := execute(&rmCmd); err != nil {
+			cli.Logger.WithError(err).Warnln("error removing working container")
+			return
+		}
+		if err := wait(&rmCmd); err != nil {
+			cli.Logger.WithError(err).Warnln("error removing working container")
+		}
+	}()
+
+	runBuilder := cli.builder().Command("run")
+	if len(runFlags) > 0 {
+		runBuilder = runBuilder.Flags(runFlags...)
+	}
+	runCmd := runBuilder.Args(append([]string{workingContainer, "--"}, options.Cmd...)...).Build()
+	cli.Logger.WithField("cmd", runCmd).Debugln("executing run with command")
+
+	stdout, stderr, err = execute(&runCmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error running command...")
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	runOutput, runErrOutput := command.ReadOutputs(stdout, stderr)
+
+	exitCode := 0
+	if err := wait(&runCmd); err != nil {
+		// an exit code is only available if the command ran to completion
+		exitErr, ok := err.(*command.ExitError)
+		if !ok || exitErr.ExitCode < 0 {
+			return v1alpha1.OCIRunResponse{}, err
+		}
+		exitCode = exitErr.ExitCode
+	}
+	return v1alpha1.OCIRunResponse{
+		Body:     ioutil.NopCloser(bytes.NewReader(runOutput)),
+		Stderr:   ioutil.NopCloser(bytes.NewReader(runErrOutput)),
+		ExitCode: exitCode,
+	}, nil
+}
+
+// inspect conducts an inspect of an image with Buildah
+func (cli Client) inspect(imageId string) (buildahImage, error) {
+
+	inspectFlag := command.Flag{
+		Name: "type", Value: "image", Short: false, OmitEmpty: false,
+	}
+
+	cmd := cli.builder().Command("inspect").Flags(inspectFlag).Args(imageId).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing inspect with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error inspecting image...")
+		return buildahImage{}, err
+	}
+	output, errOutput := command.ReadOutputs(stdout, stderr)
+	if err := wait(&cmd); err != nil {
+		return buildahImage{}, errors.Wrapf(err, "failed to inspect image %s: %s", imageId, strings.TrimSpace(string(errOutput)))
+	}
+
+	var img buildahImage
+	if err := json.Unmarshal(output, &img); err != nil {
+		return buildahImage{}, err
+	}
+	return img, nil
+}
+
 // GenerateAuthRegistryString generates the auth registry string for pushing and pulling images targeting Buildah
 func (cli Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
 	return fmt.Sprintf("%s:%s", auth.Username, auth.Password)
 }
 
-// Execute executes the buildah command. This function is mocked in buildah client tests.
-var execute = func(cmd *command.Command) (stdout io.ReadCloser, stderr io.ReadCloser, err error) {
-	return cmd.Exec()
+// Capabilities returns the capabilities of Buildah, which stores built images locally
+func (cli Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{LocalImages: true}
+}
+
+// builder returns a buildah command builder using the storage of the client options
+func (cli Client) builder() *command.CommandBuilder {
+	var globalFlags []command.Flag
+	if cli.Options != nil {
+		globalFlags = []command.Flag{
+			{Name: "root", Value: cli.Options.StorageRoot, Short: false, OmitEmpty: true},
+			{Name: "runroot", Value: cli.Options.StorageRunRoot, Short: false, OmitEmpty: true},
+		}
+	}
+	return command.Builder("buildah").GlobalFlags(globalFlags...)
+}
+
+// buildahFlags returns the build flags of buildah options
+func buildahFlags(options v1alpha1.BuildahOptions) []command.Flag {
+	flags := []command.Flag{
+		{Name: "isolation", Value: string(options.Isolation), Short: false, OmitEmpty: true},
+		{Name: "userns", Value: options.UserNS, Short: false, OmitEmpty: true},
+	}
+	for _, mapping := range options.UIDMap {
+		flags = append(flags, command.Flag{Name: "userns-uid-map", Value: mapping, Short: false, OmitEmpty: true})
+	}
+	for _, mapping := range options.GIDMap {
+		flags = append(flags, command.Flag{Name: "userns-gid-map", Value: mapping, Short: false, OmitEmpty: true})
+	}
+	if options.Layers {
+		flags = append(flags, command.Flag{Name: "layers", Value: "", Short: false, OmitEmpty: false})
+	}
+	return append(flags,
+		command.Flag{Name: "format", Value: string(options.Format), Short: false, OmitEmpty: true},
+		command.Flag{Name: "network", Value: options.Network, Short: false, OmitEmpty: true},
+		command.Flag{Name: "cgroup-parent", Value: options.CgroupParent, Short: false, OmitEmpty: true},
+		command.Flag{Name: "cgroupns", Value: options.CgroupNS, Short: false, OmitEmpty: true},
+	)
+}
+
+// execute executes the buildah command. This function is mocked in buildah client tests.
+var execute = (*command.Command).Exec
+
+// writeAuthFile writes registry auth for the registry of an image to a temporary auth file. This function is
+// mocked in buildah client tests.
+var writeAuthFile = util.WriteCommandAuthFile
+
+// wait waits on an executed buildah command. This function is mocked in buildah client tests.
+var wait = (*command.Command).Wait
+
+// imageID returns an image ID in the docker form of algorithm:hex, buildah reports image IDs as hex only
+func imageID(id string) string {
+	if id == "" || strings.Contains(id, ":") {
+		return id
+	}
+	return "sha256:" + id
+}
+
+// repository returns the repository of an image name, removing any tag
+func repository(name string) string {
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i]
+	}
+	return name
 }
@@ -3,12 +3,15 @@ package buildah
 import (
 	"context"
 	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/docker/docker/api/types"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/command"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,7 +24,68 @@ func TestClient_ImageBuild(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestClient_ImageBuild_MultipleTags(t *testing.T) {
+	options := ociBuildOptions
+	options.Tags = []string{"image-name:v0.1.0", "image-name:latest"}
+
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedMultipleTagsBuildCommand, cmd)
+		return nil, nil, nil
+	}
+	_, err := cli.ImageBuild(options)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageBuild_BuildahOptions(t *testing.T) {
+	optionsCli := Client{
+		Logger:  util.GetLogger(true),
+		Options: &buildahOptions,
+	}
+
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedBuildahOptionsBuildCommand, cmd)
+		return nil, nil, nil
+	}
+	_, err := optionsCli.ImageBuild(ociBuildOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageBuild_InvalidBuildahOptions(t *testing.T) {
+	invalidOptions := []v1alpha1.BuildahOptions{
+		{Isolation: "vm"},
+		{Format: "v2s1"},
+		{CgroupNS: "shared"},
+		{UIDMap: []string{"0:1000"}},
+		{UserNS: "host", GIDMap: []string{"0:1000:65536"}},
+	}
+	for _, options := range invalidOptions {
+		options := options
+		optionsCli := Client{
+			Logger:  util.GetLogger(true),
+			Options: &options,
+		}
+		_, err := optionsCli.ImageBuild(ociBuildOptions)
+		assert.Error(t, err)
+	}
+}
+
+func TestClient_ImagePush_BuildahOptions(t *testing.T) {
+	writeAuthFile = fakeWriteAuthFile
+	optionsCli := Client{
+		Logger:  util.GetLogger(true),
+		Options: &buildahOptions,
+	}
+
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedBuildahOptionsPushCommand, cmd)
+		return nil, nil, nil
+	}
+	_, err := optionsCli.ImagePush(ociPushOptions)
+	assert.Equal(t, nil, err)
+}
+
 func TestClient_ImagePull(t *testing.T) {
+	writeAuthFile = fakeWriteAuthFile
 	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
 		assert.Equal(t, &expectedPullCommand, cmd)
 		return nil, nil, nil
@@ -32,6 +96,7 @@ func TestClient_ImagePull(t *testing.T) {
 }
 
 func TestClient_ImagePush(t *testing.T) {
+	writeAuthFile = fakeWriteAuthFile
 	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
 		assert.Equal(t, &expectedPushCommand, cmd)
 		return nil, nil, nil
@@ -51,6 +116,192 @@ func TestClient_ImageRemove(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestClient_ImagesPrune(t *testing.T) {
+	var executed, waited []command.Command
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		executed = append(executed, *cmd)
+		return ioutil.NopCloser(strings.NewReader("1a2b3c4d\n5e6f7a8b\n1a2b3c4d\n")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		waited = append(waited, *cmd)
+		return nil
+	}
+
+	res, err := cli.ImagesPrune(ociPruneOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []command.Command{expectedPruneImagesCommand, expectedPruneCommand}, executed)
+	assert.Equal(t, []command.Command{expectedPruneImagesCommand}, waited)
+	assert.Equal(t, 2, len(res.ImagesDeleted))
+	assert.Equal(t, &expectedPruneCommand, res.Exec)
+}
+
+func TestClient_ImagesPrune_NoImages(t *testing.T) {
+	var executed []command.Command
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		executed = append(executed, *cmd)
+		return ioutil.NopCloser(strings.NewReader("\n")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ImagesPrune(ociPruneOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []command.Command{expectedPruneImagesCommand}, executed)
+	assert.Equal(t, 0, len(res.ImagesDeleted))
+	assert.Nil(t, res.Exec)
+}
+
+func TestClient_ImagesPrune_ListFailed(t *testing.T) {
+	var executed []command.Command
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		executed = append(executed, *cmd)
+		return nil, ioutil.NopCloser(strings.NewReader("error: no storage\n")), nil
+	}
+	wait = func(cmd *command.Command) error {
+		return errors.New("exit status 125")
+	}
+
+	_, err := cli.ImagesPrune(ociPruneOptions)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error: no storage")
+	assert.Equal(t, []command.Command{expectedPruneImagesCommand}, executed)
+}
+
+func TestClient_ImageTag(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedTagCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageTag(ociTagOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageInspect(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedInspectCommand, cmd)
+		return ioutil.NopCloser(strings.NewReader(inspectOutput)), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ImageInspect("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha256:1a2b3c4d", res.ID)
+	assert.Equal(t, []string{"localhost/image-name:v0.1.0"}, res.RepoTags)
+	assert.Equal(t, []string{"localhost/image-name@sha256:5e6f7a8b"}, res.RepoDigests)
+	assert.Equal(t, "2019-10-01T12:00:00Z", res.Created)
+	assert.Equal(t, "amd64", res.Architecture)
+	assert.Equal(t, "linux", res.Os)
+	assert.Equal(t, "/app", res.Config.WorkingDir)
+	assert.Equal(t, []string{"PATH=/usr/bin"}, res.Config.Env)
+	assert.Equal(t, []string{"sha256:aaaa", "sha256:bbbb"}, res.RootFS.Layers)
+}
+
+func TestClient_ImageHistory(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedInspectCommand, cmd)
+		return ioutil.NopCloser(strings.NewReader(inspectOutput)), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ImageHistory("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(res))
+	assert.Equal(t, "sha256:1a2b3c4d", res[0].ID)
+	assert.Equal(t, []string{"localhost/image-name:v0.1.0"}, res[0].Tags)
+	assert.Equal(t, "/bin/sh -c #(nop) WORKDIR /app", res[0].CreatedBy)
+	assert.Equal(t, int64(1569931200), res[0].Created)
+	assert.Equal(t, "<missing>", res[1].ID)
+	assert.Equal(t, "/bin/sh -c #(nop) ADD file:1234 in /", res[1].CreatedBy)
+}
+
+func TestClient_ImageSave(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedSaveCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageSave(ociSaveOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageSave_InvalidFormat(t *testing.T) {
+	options := ociSaveOptions
+	options.Format = "invalid"
+
+	_, err := cli.ImageSave(options)
+	assert.Error(t, err)
+}
+
+func TestClient_ImageLoad(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedLoadCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageLoad(ociLoadOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ContainerRun(t *testing.T) {
+	var executed []command.Command
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		executed = append(executed, *cmd)
+		return ioutil.NopCloser(strings.NewReader("image-name-working-container\n")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ContainerRun(ociRunOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, res.ExitCode)
+	assert.Equal(t, []command.Command{expectedFromCommand, expectedRunCommand, expectedContainerRemoveCommand}, executed)
+}
+
+func TestClient_ContainerRun_BuildahOptions(t *testing.T) {
+	optionsCli := Client{
+		Logger:  util.GetLogger(true),
+		Options: &buildahOptions,
+	}
+
+	var executed []command.Command
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		executed = append(executed, *cmd)
+		return ioutil.NopCloser(strings.NewReader("image-name-working-container\n")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	_, err := optionsCli.ContainerRun(ociRunOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(executed))
+	assert.Equal(t, expectedBuildahOptionsFromCommand, executed[0])
+	assert.Equal(t, expectedBuildahOptionsRunCommand, executed[1])
+}
+
+func TestClient_ContainerRun_exitCode(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("image-name-working-container\n")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		if cmd.String() == expectedRunCommand.String() {
+			return &command.ExitError{Command: cmd.String(), ExitCode: 2}
+		}
+		return nil
+	}
+
+	res, err := cli.ContainerRun(ociRunOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, res.ExitCode)
+}
+
 func TestClient_RegistryLogin(t *testing.T) {
 	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
 		assert.Equal(t, &expectedLoginCommand, cmd)
@@ -86,17 +337,69 @@ var expectedBuildCommand = command.Builder("buildah").Command("bud").Flags([]com
 	{Name: "t", Value: "image-name:v0.1.0", Short: true, OmitEmpty: true},
 }...).Args(".").Build()
 
+var buildahOptions = v1alpha1.BuildahOptions{
+	Isolation:      v1alpha1.ChrootIsolation,
+	UserNS:         "auto",
+	UIDMap:         []string{"0:1000:1", "1:100000:65536"},
+	GIDMap:         []string{"0:1000:1"},
+	StorageRoot:    "/home/build/.local/share/containers/storage",
+	StorageRunRoot: "/tmp/run-1000/containers",
+	Layers:         true,
+	Format:         v1alpha1.DockerImageFormat,
+	Network:        "host",
+	CgroupNS:       "private",
+}
+
+var expectedBuildahOptionsBuildCommand = command.Builder("buildah").GlobalFlags([]command.Flag{
+	{Name: "root", Value: "/home/build/.local/share/containers/storage", Short: false, OmitEmpty: true},
+	{Name: "runroot", Value: "/tmp/run-1000/containers", Short: false, OmitEmpty: true},
+}...).Command("bud").Flags([]command.Flag{
+	{Name: "f", Value: "./Dockerfile", Short: true, OmitEmpty: true},
+	{Name: "storage-driver", Value: "vfs", Short: false, OmitEmpty: true},
+	{Name: "t", Value: "image-name:v0.1.0", Short: true, OmitEmpty: true},
+	{Name: "isolation", Value: "chroot", Short: false, OmitEmpty: true},
+	{Name: "userns", Value: "auto", Short: false, OmitEmpty: true},
+	{Name: "userns-uid-map", Value: "0:1000:1", Short: false, OmitEmpty: true},
+	{Name: "userns-uid-map", Value: "1:100000:65536", Short: false, OmitEmpty: true},
+	{Name: "userns-gid-map", Value: "0:1000:1", Short: false, OmitEmpty: true},
+	{Name: "layers", Value: "", Short: false, OmitEmpty: false},
+	{Name: "format", Value: "docker", Short: false, OmitEmpty: true},
+	{Name: "network", Value: "host", Short: false, OmitEmpty: true},
+	{Name: "cgroupns", Value: "private", Short: false, OmitEmpty: true},
+}...).Args(".").Build()
+
+var expectedMultipleTagsBuildCommand = command.Builder("buildah").Command("bud").Flags([]command.Flag{
+	{Name: "f", Value: "./Dockerfile", Short: true, OmitEmpty: true},
+	{Name: "storage-driver", Value: "vfs", Short: false, OmitEmpty: true},
+	{Name: "t", Value: "image-name:v0.1.0", Short: true, OmitEmpty: true},
+	{Name: "t", Value: "image-name:latest", Short: true, OmitEmpty: true},
+}...).Args(".").Build()
+
+var fakeWriteAuthFile = func(image string, registryAuth string) (string, []string, error) {
+	return "/tmp/ocib-auth/config.json", []string{"/tmp/ocib-auth"}, nil
+}
+
 var ociPullOptions = v1alpha1.OCIPullOptions{
 	Ctx: context.Background(),
 	Ref: "image-name",
 	ImagePullOptions: types.ImagePullOptions{
 		RegistryAuth: "this-is-my-auth",
+		Platform:     "linux/arm64",
 	},
 }
 
 var expectedPullCommand = command.Builder("buildah").Command("pull").Flags([]command.Flag{
-	{Name: "creds", Value: "this-is-my-auth", Short: false, OmitEmpty: true},
-}...).Args("image-name").Build()
+	{Name: "authfile", Value: "/tmp/ocib-auth/config.json", Short: false, OmitEmpty: true},
+	{Name: "platform", Value: "linux/arm64", Short: false, OmitEmpty: true},
+}...).Args("image-name").TempFiles("/tmp/ocib-auth").Build()
+
+var expectedBuildahOptionsPushCommand = command.Builder("buildah").GlobalFlags([]command.Flag{
+	{Name: "root", Value: "/home/build/.local/share/containers/storage", Short: false, OmitEmpty: true},
+	{Name: "runroot", Value: "/tmp/run-1000/containers", Short: false, OmitEmpty: true},
+}...).Command("push").Flags([]command.Flag{
+	{Name: "authfile", Value: "/tmp/ocib-auth/config.json", Short: false, OmitEmpty: true},
+	{Name: "digestfile", Value: "/tmp/digest", Short: false, OmitEmpty: true},
+}...).Args("image-name").TempFiles("/tmp/ocib-auth").Build()
 
 var ociPushOptions = v1alpha1.OCIPushOptions{
 	Ctx: context.Background(),
@@ -104,11 +407,13 @@ var ociPushOptions = v1alpha1.OCIPushOptions{
 	ImagePushOptions: types.ImagePushOptions{
 		RegistryAuth: "this-is-my-auth",
 	},
+	DigestFile: "/tmp/digest",
 }
 
 var expectedPushCommand = command.Builder("buildah").Command("push").Flags([]command.Flag{
-	{Name: "creds", Value: "this-is-my-auth", Short: false, OmitEmpty: true},
-}...).Args("image-name").Build()
+	{Name: "authfile", Value: "/tmp/ocib-auth/config.json", Short: false, OmitEmpty: true},
+	{Name: "digestfile", Value: "/tmp/digest", Short: false, OmitEmpty: true},
+}...).Args("image-name").TempFiles("/tmp/ocib-auth").Build()
 
 var ociRemoveOptions = v1alpha1.OCIRemoveOptions{
 	Image:              "image-name",
@@ -118,6 +423,97 @@ var ociRemoveOptions = v1alpha1.OCIRemoveOptions{
 
 var expectedRemoveCommand = command.Builder("buildah").Command("rmi").Args("image-name").Build()
 
+var ociPruneOptions = v1alpha1.OCIPruneOptions{
+	Labels: []string{"build-id=1234"},
+	Ctx:    context.Background(),
+}
+
+var expectedPruneImagesCommand = command.Builder("buildah").Command("images").Flags([]command.Flag{
+	{Name: "quiet", Value: "", Short: false, OmitEmpty: false},
+	{Name: "filter", Value: "label=build-id=1234", Short: false, OmitEmpty: true},
+}...).Build()
+
+var expectedPruneCommand = command.Builder("buildah").Command("rmi").Args("1a2b3c4d", "5e6f7a8b").Build()
+
+var ociTagOptions = v1alpha1.OCITagOptions{
+	Source: "image-name:v0.1.0",
+	Target: "example-registry/image-name:v0.1.0",
+	Ctx:    context.Background(),
+}
+
+var expectedTagCommand = command.Builder("buildah").Command("tag").Args("image-name:v0.1.0", "example-registry/image-name:v0.1.0").Build()
+
+var expectedInspectCommand = command.Builder("buildah").Command("inspect").Flags(command.Flag{
+	Name: "type", Value: "image", Short: false, OmitEmpty: false,
+}).Args("image-name:v0.1.0").Build()
+
+var inspectOutput = `{
+	"Type": "buildah 0.0.1",
+	"FromImage": "localhost/image-name:v0.1.0",
+	"FromImageID": "1a2b3c4d",
+	"FromImageDigest": "sha256:5e6f7a8b",
+	"OCIv1": {
+		"created": "2019-10-01T12:00:00Z",
+		"architecture": "amd64",
+		"os": "linux",
+		"config": {"Env": ["PATH=/usr/bin"], "WorkingDir": "/app"},
+		"rootfs": {"type": "layers", "diff_ids": ["sha256:aaaa", "sha256:bbbb"]},
+		"history": [
+			{"created": "2019-09-01T12:00:00Z", "created_by": "/bin/sh -c #(nop) ADD file:1234 in /"},
+			{"created": "2019-10-01T12:00:00Z", "created_by": "/bin/sh -c #(nop) WORKDIR /app", "empty_layer": true}
+		]
+	}
+}`
+
+var ociSaveOptions = v1alpha1.OCISaveOptions{
+	Image:  "image-name:v0.1.0",
+	Format: v1alpha1.OCIArchiveExportFormat,
+	Dest:   "/tmp/image.tar",
+	Ctx:    context.Background(),
+}
+
+var expectedSaveCommand = command.Builder("buildah").Command("push").Args("image-name:v0.1.0", "oci-archive:/tmp/image.tar:image-name:v0.1.0").Build()
+
+var ociLoadOptions = v1alpha1.OCILoadOptions{
+	Input:  "/tmp/image.tar",
+	Format: v1alpha1.DockerArchiveExportFormat,
+	Ctx:    context.Background(),
+}
+
+var expectedLoadCommand = command.Builder("buildah").Command("pull").Args("docker-archive:/tmp/image.tar").Build()
+
+var ociRunOptions = v1alpha1.OCIRunOptions{
+	Image: "image-name:v0.1.0",
+	Cmd:   []string{"echo", "hello"},
+	Ctx:   context.Background(),
+}
+
+var expectedFromCommand = command.Builder("buildah").Command("from").Flags(command.Flag{
+	Name: "quiet", Value: "", Short: false, OmitEmpty: false,
+}).Args("image-name:v0.1.0").Build()
+
+var expectedRunCommand = command.Builder("buildah").Command("run").Args("image-name-working-container", "--", "echo", "hello").Build()
+
+var expectedBuildahOptionsFromCommand = command.Builder("buildah").GlobalFlags([]command.Flag{
+	{Name: "root", Value: "/home/build/.local/share/containers/storage", Short: false, OmitEmpty: true},
+	{Name: "runroot", Value: "/tmp/run-1000/containers", Short: false, OmitEmpty: true},
+}...).Command("from").Flags([]command.Flag{
+	{Name: "quiet", Value: "", Short: false, OmitEmpty: false},
+	{Name: "userns-uid-map", Value: "0:1000:1", Short: false, OmitEmpty: true},
+	{Name: "userns-uid-map", Value: "1:100000:65536", Short: false, OmitEmpty: true},
+	{Name: "userns-gid-map", Value: "0:1000:1", Short: false, OmitEmpty: true},
+}...).Args("image-name:v0.1.0").Build()
+
+var expectedBuildahOptionsRunCommand = command.Builder("buildah").GlobalFlags([]command.Flag{
+	{Name: "root", Value: "/home/build/.local/share/containers/storage", Short: false, OmitEmpty: true},
+	{Name: "runroot", Value: "/tmp/run-1000/containers", Short: false, OmitEmpty: true},
+}...).Command("run").Flags([]command.Flag{
+	{Name: "isolation", Value: "chroot", Short: false, OmitEmpty: true},
+	{Name: "userns", Value: "auto", Short: false, OmitEmpty: true},
+}...).Args("image-name-working-container", "--", "echo", "hello").Build()
+
+var expectedContainerRemoveCommand = command.Builder("buildah").Command("rm").Args("image-name-working-container").Build()
+
 var ociLoginOptions = v1alpha1.OCILoginOptions{
 	Ctx:        context.Background(),
 	AuthConfig: authConfig,
@@ -125,8 +521,8 @@ var ociLoginOptions = v1alpha1.OCILoginOptions{
 
 var expectedLoginCommand = command.Builder("buildah").Command("login").Flags([]command.Flag{
 	{Name: "u", Value: "user", Short: true, OmitEmpty: true},
-	{Name: "p", Value: "pass", Short: true, OmitEmpty: true},
-}...).Args("arts-test-registry").Build()
+	{Name: "password-stdin", Value: "", Short: false, OmitEmpty: false},
+}...).Args("arts-test-registry").Stdin(strings.NewReader("pass")).Build()
 
 var authConfig = types.AuthConfig{
 	Username:      "user",
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildah
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// stepLine matches the start of a Dockerfile step, e.g. STEP 2/3: RUN echo hello, optionally prefixed by the
+// stage of a multi-stage build, e.g. [1/2] STEP 2/3: RUN echo hello. Older buildah versions omit the step count.
+var stepLine = regexp.MustCompile(`^(?:\[\d+/\d+\] )?STEP (\d+(?:/\d+)?): (.*)$`)
+
+// cacheLine matches a step which used the build cache, e.g. --> Using cache 1a2b3c4d...
+var cacheLine = regexp.MustCompile(`^--> Using cache [0-9a-f]+$`)
+
+// commitLine matches the ID of the image committed by a step, e.g. --> 1a2b3c4d
+var commitLine = regexp.MustCompile(`^--> ([0-9a-f]+)$`)
+
+// imageIDLine matches the full ID of the built image, which buildah outputs once the build is complete
+var imageIDLine = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// errorLine matches the error of a failed build on stderr, e.g. Error: building at STEP "RUN false": exit status 1
+var errorLine = regexp.MustCompile(`^Error: (.*)$`)
+
+// maxLineSize is the maximum size of a line of output, which can be long for the output of a step
+const maxLineSize = 1024 * 1024
+
+// ProgressReader converts the output of buildah bud into a stream of docker json messages as it arrives, with a
+// message for each Dockerfile step, cache hit and committed image in the same form as a docker build. The built
+// image ID is sent as an auxiliary message, as with docker.
+func ProgressReader(stdout io.ReadCloser, stderr io.ReadCloser) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		var mu sync.Mutex
+		encoder := json.NewEncoder(w)
+		encode := func(msg *jsonmessage.JSONMessage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return encoder.Encode(msg)
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i, output := range []io.ReadCloser{stdout, stderr} {
+			if output == nil {
+				continue
+			}
+			wg.Add(1)
+			go func(i int, output io.ReadCloser) {
+				defer wg.Done()
+				defer output.Close()
+				// errors of a failed build are written to stderr, the output of steps to stdout
+				errs[i] = convertProgress(output, i == 1, encode)
+			}(i, output)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+		w.Close()
+	}()
+	return r
+}
+
+// convertProgress converts each line of buildah output to a docker json message
+func convertProgress(output io.Reader, isStderr bool, encode func(*jsonmessage.JSONMessage) error) error {
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		if err := encode(progressMessage(scanner.Text(), isStderr)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// progressMessage returns the docker json message of a line of buildah output
+func progressMessage(line string, isStderr bool) *jsonmessage.JSONMessage {
+	if match := stepLine.FindStringSubmatch(line); match != nil {
+		return &jsonmessage.JSONMessage{Stream: fmt.Sprintf("Step %s : %s\n", match[1], match[2])}
+	}
+	if cacheLine.MatchString(line) {
+		return &jsonmessage.JSONMessage{Stream: " ---> Using cache\n"}
+	}
+	if match := commitLine.FindStringSubmatch(line); match != nil {
+		return &jsonmessage.JSONMessage{Stream: fmt.Sprintf(" ---> %s\n", match[1])}
+	}
+	if imageIDLine.MatchString(line) {
+		aux := json.RawMessage(fmt.Sprintf(`{"ID":"sha256:%s"}`, line))
+		return &jsonmessage.JSONMessage{Aux: &aux}
+	}
+	if match := errorLine.FindStringSubmatch(line); isStderr && match != nil {
+		errMsg := strings.TrimSpace(match[1])
+		return &jsonmessage.JSONMessage{
+			Error:        &jsonmessage.JSONError{Message: errMsg},
+			ErrorMessage: errMsg,
+		}
+	}
+	return &jsonmessage.JSONMessage{Stream: line + "\n"}
+}
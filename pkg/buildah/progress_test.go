@@ -0,0 +1,81 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildah
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReader(t *testing.T) {
+	out := &bytes.Buffer{}
+	var aux []string
+	auxCallback := func(msg jsonmessage.JSONMessage) {
+		aux = append(aux, string(*msg.Aux))
+	}
+	err := jsonmessage.DisplayJSONMessagesStream(ProgressReader(ioutil.NopCloser(strings.NewReader(progress)), nil), out, 0, false, auxCallback)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedOutput, out.String())
+	assert.Equal(t, []string{`{"ID":"sha256:1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b"}`}, aux)
+}
+
+func TestProgressReader_Error(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := jsonmessage.DisplayJSONMessagesStream(ProgressReader(nil, ioutil.NopCloser(strings.NewReader(errorProgress))), out, 0, false, nil)
+	assert.Error(t, err)
+	assert.Equal(t, `building at STEP "RUN exit 1": while running runtime: exit status 1`, err.Error())
+	assert.Equal(t, "time=\"2019-10-01T12:00:00Z\" level=warning msg=\"missing build arg\"\n", out.String())
+}
+
+func TestProgressReader_Output(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := jsonmessage.DisplayJSONMessagesStream(ProgressReader(ioutil.NopCloser(strings.NewReader("Error: not an error\n")), nil), out, 0, false, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Error: not an error\n", out.String())
+}
+
+const progress = `STEP 1/3: FROM docker.io/library/alpine:3.10
+STEP 2/3: RUN echo hello
+--> Using cache 5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b1a2b3c4d
+--> 5e6f7a8b9c0
+[2/2] STEP 3/3: RUN echo done
+done
+COMMIT image-name:v0.1.0
+--> 1a2b3c4d5e6
+Successfully tagged localhost/image-name:v0.1.0
+1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b
+`
+
+const expectedOutput = `Step 1/3 : FROM docker.io/library/alpine:3.10
+Step 2/3 : RUN echo hello
+ ---> Using cache
+ ---> 5e6f7a8b9c0
+Step 3/3 : RUN echo done
+done
+COMMIT image-name:v0.1.0
+ ---> 1a2b3c4d5e6
+Successfully tagged localhost/image-name:v0.1.0
+`
+
+const errorProgress = `time="2019-10-01T12:00:00Z" level=warning msg="missing build arg"
+Error: building at STEP "RUN exit 1": while running runtime: exit status 1
+`
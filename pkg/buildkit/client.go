@@ -0,0 +1,266 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/ocibuilder/ocibuilder/pkg/validate"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultFrontend is the BuildKit frontend used to build Dockerfiles
+const DefaultFrontend = "dockerfile.v0"
+
+// Client is the client used for building with BuildKit using the ocibuilder. Builds are solved by a buildkitd
+// instance through buildctl, which pushes images and writes exports as outputs of the build, so images are
+// never stored locally.
+type Client struct {
+	Logger *logrus.Logger
+	// Addr is the address of the buildkitd instance, defaults to the buildctl default
+	Addr string
+	// Frontend is the BuildKit frontend, defaults to dockerfile.v0
+	Frontend string
+	// DockerConfigDir is the directory of the docker config buildctl reads registry auth from,
+	// defaults to $DOCKER_CONFIG or ~/.docker
+	DockerConfigDir string
+}
+
+// exportOutputs maps export formats to BuildKit output types
+var exportOutputs = map[v1alpha1.ExportFormat]string{
+	v1alpha1.OCIExportFormat:           "type=oci,tar=false",
+	v1alpha1.OCIArchiveExportFormat:    "type=oci",
+	v1alpha1.DockerArchiveExportFormat: "type=docker",
+}
+
+// ImageBuild conducts an image build with BuildKit using the ocibuilder, pushing the built image to every
+// destination and writing every export as an output of the build. The body of the response is the BuildKit progress
+// converted to a stream of docker json messages.
+func (cli Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
+
+	frontend := cli.Frontend
+	if frontend == "" {
+		frontend = DefaultFrontend
+	}
+
+	buildFlags := []command.Flag{
+		{Name: "frontend", Value: frontend, Short: false, OmitEmpty: true},
+		{Name: "local", Value: "context=" + options.ContextPath, Short: false, OmitEmpty: true},
+		{Name: "local", Value: "dockerfile=" + options.ContextPath, Short: false, OmitEmpty: true},
+		{Name: "progress", Value: "plain", Short: false, OmitEmpty: true},
+	}
+
+	var frontendOpts []string
+	if options.Dockerfile != "" {
+		frontendOpts = append(frontendOpts, "filename="+options.Dockerfile)
+	}
+	if options.Target != "" {
+		frontendOpts = append(frontendOpts, "target="+options.Target)
+	}
+	if options.Platform != "" {
+		frontendOpts = append(frontendOpts, "platform="+options.Platform)
+	}
+	if options.NoCache {
+		frontendOpts = append(frontendOpts, "no-cache=")
+	}
+	var buildArgKeys []string
+	for k := range options.BuildArgs {
+		buildArgKeys = append(buildArgKeys, k)
+	}
+	sort.Strings(buildArgKeys)
+	for _, k := range buildArgKeys {
+		buildArg := "build-arg:" + k
+		if v := options.BuildArgs[k]; v != nil {
+			buildArg = fmt.Sprintf("%s=%s", buildArg, *v)
+		}
+		frontendOpts = append(frontendOpts, buildArg)
+	}
+	var labelKeys []string
+	for k := range options.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		frontendOpts = append(frontendOpts, fmt.Sprintf("label:%s=%s", k, options.Labels[k]))
+	}
+	for _, opt := range frontendOpts {
+		// build args often hold secrets such as tokens, so are redacted from logs
+		sensitive := strings.HasPrefix(opt, "build-arg:")
+		buildFlags = append(buildFlags, command.Flag{Name: "opt", Value: opt, Short: false, OmitEmpty: true, Sensitive: sensitive})
+	}
+
+	// images are only pushed to the destinations of push specs, as built images are not stored locally
+	if len(options.Destinations) > 0 {
+		// image names are comma separated, so the name attribute is quoted as csv
+		output := fmt.Sprintf("type=image,\"name=%s\",push=true", strings.Join(options.Destinations, ","))
+		buildFlags = append(buildFlags, command.Flag{Name: "output", Value: output, Short: false, OmitEmpty: true})
+	}
+	for _, export := range options.Exports {
+		if err := validate.ValidateExportSpec(export); err != nil {
+			return v1alpha1.OCIBuildResponse{}, err
+		}
+		if err := os.MkdirAll(filepath.Dir(export.Path), 0755); err != nil {
+			return v1alpha1.OCIBuildResponse{}, err
+		}
+		output := fmt.Sprintf("%s,dest=%s", exportOutputs[export.Format], export.Path)
+		if len(options.Tags) > 0 {
+			output = fmt.Sprintf("%s,name=%s", output, options.Tags[0])
+		}
+		buildFlags = append(buildFlags, command.Flag{Name: "output", Value: output, Short: false, OmitEmpty: true})
+	}
+
+	if !options.NoCache && options.CacheRepo != "" {
+		buildFlags = append(buildFlags,
+			command.Flag{Name: "export-cache", Value: fmt.Sprintf("type=registry,ref=%s,mode=max", options.CacheRepo), Short: false, OmitEmpty: true},
+			command.Flag{Name: "import-cache", Value: fmt.Sprintf("type=registry,ref=%s", options.CacheRepo), Short: false, OmitEmpty: true},
+		)
+	}
+
+	cmd := cli.builder().Command("build").Flags(buildFlags...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing build with command")
+
+	_, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error building image...")
+		return v1alpha1.OCIBuildResponse{}, err
+	}
+	return v1alpha1.OCIBuildResponse{
+		ImageBuildResponse: types.ImageBuildResponse{
+			// buildctl writes its progress to stderr
+			Body: ProgressReader(stderr),
+		},
+		Exec: &cmd,
+	}, nil
+}
+
+// ImagePull is not supported by BuildKit, base images are pulled as part of the build
+func (cli Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullResponse, error) {
+	return v1alpha1.OCIPullResponse{}, unsupported("image pull")
+}
+
+// ImagePush is not supported by BuildKit, images are pushed to their tags as part of the build
+func (cli Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
+	return v1alpha1.OCIPushResponse{}, unsupported("image push")
+}
+
+// ImageRemove is a no-op with BuildKit as built images are not stored locally
+func (cli Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
+	cli.Logger.WithField("image", options.Image).Debugln("buildkit does not store images locally, nothing to remove")
+	return v1alpha1.OCIRemoveResponse{}, nil
+}
+
+// ImagesPrune is a no-op with BuildKit as built images are not stored locally
+func (cli Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	cli.Logger.WithField("labels", options.Labels).Debugln("buildkit does not store images locally, nothing to prune")
+	return v1alpha1.OCIPruneResponse{}, nil
+}
+
+// ImageTag is not supported by BuildKit, tags are pushed as outputs of the build
+func (cli Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	return v1alpha1.OCITagResponse{}, unsupported("image tag")
+}
+
+// ImageInspect is not supported by BuildKit as built images are not stored locally
+func (cli Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+	return types.ImageInspect{}, unsupported("image inspect")
+}
+
+// ImageHistory is not supported by BuildKit as built images are not stored locally
+func (cli Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
+	return nil, unsupported("image history")
+}
+
+// ImageSave is not supported by BuildKit, exports are written as outputs of the build
+func (cli Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	return v1alpha1.OCISaveResponse{}, unsupported("image export outside of a build")
+}
+
+// ImageLoad is not supported by BuildKit as images are not stored locally
+func (cli Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	return v1alpha1.OCILoadResponse{}, unsupported("image load")
+}
+
+// ContainerRun is not supported by BuildKit as it cannot run containers of built images
+func (cli Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	return v1alpha1.OCIRunResponse{}, unsupported("container run")
+}
+
+// RegistryLogin writes the registry auth to the docker config buildctl reads from when pushing
+func (cli Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
+	configDir := cli.DockerConfigDir
+	if configDir == "" {
+		configDir = os.Getenv("DOCKER_CONFIG")
+	}
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return v1alpha1.OCILoginResponse{}, err
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+	cli.Logger.WithField("path", configDir).Debugln("writing registry auth to docker config")
+
+	if err := util.WriteDockerConfigAuth(configDir, options.AuthConfig); err != nil {
+		cli.Logger.WithError(err).Errorln("error writing docker config...")
+		return v1alpha1.OCILoginResponse{}, err
+	}
+
+	return v1alpha1.OCILoginResponse{
+		AuthenticateOKBody: registry.AuthenticateOKBody{
+			Status: "login completed",
+		},
+	}, nil
+}
+
+// GenerateAuthRegistryString generates the auth of a registry in the docker config format of base64 encoded
+// username:password
+func (cli Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
+	return util.EncodeDockerConfigAuth(auth)
+}
+
+// Capabilities returns the capabilities of BuildKit, which writes exports as outputs of the build as built
+// images are not stored locally
+func (cli Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{BuildExports: true}
+}
+
+// builder returns a buildctl command builder targeting the buildkitd instance of the client
+func (cli Client) builder() *command.CommandBuilder {
+	return command.Builder("buildctl").GlobalFlags(command.Flag{Name: "addr", Value: cli.Addr, Short: false, OmitEmpty: true})
+}
+
+// unsupported returns the error for an operation BuildKit cannot conduct
+func unsupported(operation string) error {
+	return errors.Errorf("%s is not supported by the buildkit builder", operation)
+}
+
+// execute executes the buildctl command. This function is mocked in buildkit client tests.
+var execute = func(cmd *command.Command) (stdout io.ReadCloser, stderr io.ReadCloser, err error) {
+	return cmd.Exec()
+}
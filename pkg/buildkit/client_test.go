@@ -0,0 +1,147 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ImageBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-buildkit")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	options := ociBuildOptions
+	options.Exports = []v1alpha1.ExportSpec{
+		{Format: v1alpha1.OCIArchiveExportFormat, Path: filepath.Join(dir, "image.tar")},
+	}
+	expectedCommand := command.Builder("buildctl").GlobalFlags(command.Flag{
+		Name: "addr", Value: "tcp://buildkitd:1234", Short: false, OmitEmpty: true,
+	}).Command("build").Flags(append(expectedBuildFlags,
+		command.Flag{Name: "output", Value: "type=oci,dest=" + filepath.Join(dir, "image.tar") + ",name=image-name:v0.1.0", Short: false, OmitEmpty: true},
+		command.Flag{Name: "export-cache", Value: "type=registry,ref=example-registry/image-name/cache,mode=max", Short: false, OmitEmpty: true},
+		command.Flag{Name: "import-cache", Value: "type=registry,ref=example-registry/image-name/cache", Short: false, OmitEmpty: true},
+	)...).Build()
+
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedCommand, cmd)
+		return nil, ioutil.NopCloser(strings.NewReader("#1 [1/1] FROM docker.io/library/alpine:3.10\n")), nil
+	}
+	res, err := cli.ImageBuild(options)
+	assert.Equal(t, nil, err)
+
+	body, err := ioutil.ReadAll(res.Body)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "{\"stream\":\"Step 1/1 : FROM docker.io/library/alpine:3.10\\n\"}\n", string(body))
+}
+
+func TestClient_ImageBuild_NoDestination(t *testing.T) {
+	options := ociBuildOptions
+	options.Destinations = nil
+
+	// images are not pushed without a push spec referencing the step
+	noPushFlags := append([]command.Flag{}, expectedBuildFlags[:len(expectedBuildFlags)-1]...)
+	expectedCommand := command.Builder("buildctl").GlobalFlags(command.Flag{
+		Name: "addr", Value: "tcp://buildkitd:1234", Short: false, OmitEmpty: true,
+	}).Command("build").Flags(append(noPushFlags,
+		command.Flag{Name: "export-cache", Value: "type=registry,ref=example-registry/image-name/cache,mode=max", Short: false, OmitEmpty: true},
+		command.Flag{Name: "import-cache", Value: "type=registry,ref=example-registry/image-name/cache", Short: false, OmitEmpty: true},
+	)...).Build()
+
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedCommand, cmd)
+		return nil, ioutil.NopCloser(strings.NewReader("")), nil
+	}
+	_, err := cli.ImageBuild(options)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageBuild_InvalidExport(t *testing.T) {
+	options := ociBuildOptions
+	options.Exports = []v1alpha1.ExportSpec{{Format: "invalid", Path: "/tmp/image.tar"}}
+
+	_, err := cli.ImageBuild(options)
+	assert.Error(t, err)
+}
+
+func TestClient_ImageSave(t *testing.T) {
+	_, err := cli.ImageSave(v1alpha1.OCISaveOptions{Image: "image-name:v0.1.0"})
+	assert.Error(t, err)
+}
+
+func TestClient_RegistryLogin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-buildkit")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	loginCli := cli
+	loginCli.DockerConfigDir = dir
+	_, err = loginCli.RegistryLogin(v1alpha1.OCILoginOptions{
+		Ctx:        context.Background(),
+		AuthConfig: types.AuthConfig{Username: "user", Password: "pass", ServerAddress: "arts-test-registry"},
+	})
+	assert.Equal(t, nil, err)
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	assert.Equal(t, nil, err)
+	assert.Contains(t, string(contents), `"auth": "dXNlcjpwYXNz"`)
+}
+
+var cli = Client{
+	Logger: util.GetLogger(true),
+	Addr:   "tcp://buildkitd:1234",
+}
+
+var buildArg = "1.13"
+
+var ociBuildOptions = v1alpha1.OCIBuildOptions{
+	Ctx:          context.Background(),
+	ContextPath:  "/ocib/context/",
+	CacheRepo:    "example-registry/image-name/cache",
+	Destinations: []string{"example-registry/image-name:v0.1.0", "example-registry/image-name:latest"},
+	ImageBuildOptions: types.ImageBuildOptions{
+		Dockerfile: "Dockerfile",
+		Target:     "release",
+		Tags:       []string{"image-name:v0.1.0", "image-name:latest"},
+		Labels:     map[string]string{"build-id": "1234"},
+		BuildArgs:  map[string]*string{"GO_VERSION": &buildArg},
+	},
+}
+
+var expectedBuildFlags = []command.Flag{
+	{Name: "frontend", Value: "dockerfile.v0", Short: false, OmitEmpty: true},
+	{Name: "local", Value: "context=/ocib/context/", Short: false, OmitEmpty: true},
+	{Name: "local", Value: "dockerfile=/ocib/context/", Short: false, OmitEmpty: true},
+	{Name: "progress", Value: "plain", Short: false, OmitEmpty: true},
+	{Name: "opt", Value: "filename=Dockerfile", Short: false, OmitEmpty: true},
+	{Name: "opt", Value: "target=release", Short: false, OmitEmpty: true},
+	{Name: "opt", Value: "build-arg:GO_VERSION=1.13", Short: false, OmitEmpty: true, Sensitive: true},
+	{Name: "opt", Value: "label:build-id=1234", Short: false, OmitEmpty: true},
+	{Name: "output", Value: "type=image,\"name=example-registry/image-name:v0.1.0,example-registry/image-name:latest\",push=true", Short: false, OmitEmpty: true},
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// vertexLine matches a line of plain BuildKit progress, e.g. #3 [2/2] RUN echo hello
+var vertexLine = regexp.MustCompile(`^#(\d+) (.*)$`)
+
+// stepName matches the name of a vertex which is a Dockerfile step, e.g. [stage-1 2/2] RUN echo hello
+var stepName = regexp.MustCompile(`^\[([^\]]+)\] (.*)$`)
+
+// logLine matches the output of a running step, prefixed by the seconds since the step started e.g. 0.345 hello
+var logLine = regexp.MustCompile(`^\d+\.\d+ (.*)$`)
+
+// maxLineSize is the maximum size of a line of progress, which can be long for the output of a step
+const maxLineSize = 1024 * 1024
+
+// ProgressReader converts the plain progress output of buildctl into a stream of docker json messages, with a
+// message for each Dockerfile step and its output in the same form as a docker build
+func ProgressReader(progress io.ReadCloser) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		defer progress.Close()
+		w.CloseWithError(convertProgress(progress, w))
+	}()
+	return r
+}
+
+// convertProgress writes the json messages of plain BuildKit progress. The first line of each vertex is its name,
+// only vertices named as Dockerfile steps are output, and errors are output for every vertex.
+func convertProgress(progress io.Reader, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	steps := make(map[string]bool)
+	named := make(map[string]bool)
+
+	scanner := bufio.NewScanner(progress)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		match := vertexLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		vertex, content := match[1], match[2]
+
+		var msg *jsonmessage.JSONMessage
+		switch {
+		case !named[vertex]:
+			named[vertex] = true
+			step := stepName.FindStringSubmatch(content)
+			if step == nil || step[1] == "internal" || step[1] == "auth" {
+				continue
+			}
+			steps[vertex] = true
+			msg = &jsonmessage.JSONMessage{Stream: fmt.Sprintf("Step %s : %s\n", step[1], step[2])}
+
+		case strings.HasPrefix(content, "ERROR"):
+			errMsg := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(content, "ERROR"), ":"))
+			msg = &jsonmessage.JSONMessage{
+				Error:        &jsonmessage.JSONError{Message: errMsg},
+				ErrorMessage: errMsg,
+			}
+
+		case !steps[vertex]:
+			continue
+
+		case content == "CACHED":
+			msg = &jsonmessage.JSONMessage{Stream: " ---> Using cache\n"}
+
+		default:
+			output := logLine.FindStringSubmatch(content)
+			if output == nil {
+				continue
+			}
+			msg = &jsonmessage.JSONMessage{Stream: output[1] + "\n"}
+		}
+
+		if err := encoder.Encode(msg); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
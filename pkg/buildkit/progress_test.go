@@ -0,0 +1,87 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReader(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := jsonmessage.DisplayJSONMessagesStream(ProgressReader(ioutil.NopCloser(strings.NewReader(progress))), out, 0, false, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedOutput, out.String())
+}
+
+func TestProgressReader_Error(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := jsonmessage.DisplayJSONMessagesStream(ProgressReader(ioutil.NopCloser(strings.NewReader(errorProgress))), out, 0, false, nil)
+	assert.Error(t, err)
+	assert.Equal(t, `process "/bin/sh -c exit 1" did not complete successfully: exit code: 1`, err.Error())
+	assert.Equal(t, "Step 1/2 : FROM docker.io/library/alpine:3.10\nStep 2/2 : RUN exit 1\n", out.String())
+}
+
+const progress = `#1 [internal] load build definition from Dockerfile
+#1 transferring dockerfile: 84B done
+#1 DONE 0.0s
+
+#2 [internal] load metadata for docker.io/library/alpine:3.10
+#2 DONE 0.8s
+
+#3 [1/3] FROM docker.io/library/alpine:3.10@sha256:451eee8bedcb2f029756dc3e9d73bab0e7943c1ac55cff3a4861c52a0fdd3e65
+#3 resolve docker.io/library/alpine:3.10@sha256:451eee8bedcb2f029756dc3e9d73bab0e7943c1ac55cff3a4861c52a0fdd3e65 done
+#3 CACHED
+
+#4 [2/3] RUN echo hello
+#4 0.245 hello
+#4 0.247 world
+#4 DONE 0.3s
+
+#5 [3/3] RUN echo done
+#5 0.130 done
+#5 DONE 0.2s
+
+#6 exporting to image
+#6 exporting layers 0.1s done
+#6 DONE 0.1s
+`
+
+const expectedOutput = `Step 1/3 : FROM docker.io/library/alpine:3.10@sha256:451eee8bedcb2f029756dc3e9d73bab0e7943c1ac55cff3a4861c52a0fdd3e65
+ ---> Using cache
+Step 2/3 : RUN echo hello
+hello
+world
+Step 3/3 : RUN echo done
+done
+`
+
+const errorProgress = `#1 [1/2] FROM docker.io/library/alpine:3.10
+#1 DONE 0.1s
+
+#2 [2/2] RUN exit 1
+#2 ERROR: process "/bin/sh -c exit 1" did not complete successfully: exit code: 1
+------
+ > [2/2] RUN exit 1:
+------
+error: failed to solve: process "/bin/sh -c exit 1" did not complete successfully: exit code: 1
+`
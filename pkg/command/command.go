@@ -1,32 +1,61 @@
 package command
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
 )
 
-var executor = exec.Command
+var executor = exec.CommandContext
 
 // Command is a single executable command
 type Command struct {
-	name    string
-	command string
-	flags   []Flag
-	args    []string
+	name        string
+	globalFlags []Flag
+	command     string
+	flags       []Flag
+	args        []string
+	env         []string
+	dir         string
+	stdin       io.Reader
+	stdout      []io.Writer
+	stderr      []io.Writer
+	ctx         context.Context
+	timeout     time.Duration
+	tempFiles   []string
 
-	execCmd *exec.Cmd
+	execCmd    *exec.Cmd
+	execCtx    context.Context
+	cancel     context.CancelFunc
+	stderrTail *tailBuffer
+	stderrDone chan struct{}
 }
 
 // CommandBuilder is a builder for a Command
 type CommandBuilder struct {
-	name    string
-	command string
-	flags   []Flag
-	args    []string
+	name        string
+	globalFlags []Flag
+	command     string
+	flags       []Flag
+	args        []string
+	env         []string
+	dir         string
+	stdin       io.Reader
+	stdout      []io.Writer
+	stderr      []io.Writer
+	ctx         context.Context
+	timeout     time.Duration
+	tempFiles   []string
 }
 
+// redacted replaces the values of sensitive flags when a command is printed
+const redacted = "*****"
+
 // Flag is a command flag
 type Flag struct {
 	// Name is the name of the flag
@@ -35,17 +64,28 @@ type Flag struct {
 	Value string
 	// Short determines whether the flag used is a short variation or not
 	Short bool
-	// OmitEmpty omits the flag if the value is empty
+	// OmitEmpty omits the flag if the value is empty, otherwise a flag with an empty value is passed as a boolean flag
 	OmitEmpty bool
+	// Sensitive redacts the value of the flag when the command is printed, e.g. in logs
+	Sensitive bool
 }
 
 // Build builds a command from a CommandBuilder
 func (builder *CommandBuilder) Build() Command {
 	return Command{
-		name:    builder.name,
-		command: builder.command,
-		flags:   builder.flags,
-		args:    builder.args,
+		name:        builder.name,
+		globalFlags: builder.globalFlags,
+		command:     builder.command,
+		flags:       builder.flags,
+		args:        builder.args,
+		env:         builder.env,
+		dir:         builder.dir,
+		stdin:       builder.stdin,
+		stdout:      builder.stdout,
+		stderr:      builder.stderr,
+		ctx:         builder.ctx,
+		timeout:     builder.timeout,
+		tempFiles:   builder.tempFiles,
 	}
 }
 
@@ -55,8 +95,20 @@ func (builder *CommandBuilder) Command(command string) *CommandBuilder {
 	return builder
 }
 
+// GlobalFlags specifies the flags to exec for the builder which are passed before the command
+func (builder *CommandBuilder) GlobalFlags(flags ...Flag) *CommandBuilder {
+	builder.globalFlags = omitEmpty(flags)
+	return builder
+}
+
 // Flags specifies the flags to exec for the builder
 func (builder *CommandBuilder) Flags(flags ...Flag) *CommandBuilder {
+	builder.flags = omitEmpty(flags)
+	return builder
+}
+
+// omitEmpty removes the flags with an empty value which are set to be omitted
+func omitEmpty(flags []Flag) []Flag {
 	var builderFlags []Flag
 	for _, f := range flags {
 		if !f.OmitEmpty {
@@ -65,9 +117,7 @@ func (builder *CommandBuilder) Flags(flags ...Flag) *CommandBuilder {
 			builderFlags = append(builderFlags, f)
 		}
 	}
-
-	builder.flags = builderFlags
-	return builder
+	return builderFlags
 }
 
 // Args specifies the args to exec for the builder
@@ -76,6 +126,56 @@ func (builder *CommandBuilder) Args(args ...string) *CommandBuilder {
 	return builder
 }
 
+// Env specifies environment variables in the form key=value, which are added to the environment of the
+// current process for the command
+func (builder *CommandBuilder) Env(env ...string) *CommandBuilder {
+	builder.env = append(builder.env, env...)
+	return builder
+}
+
+// Dir specifies the working directory of the command, by default the working directory of the current process
+func (builder *CommandBuilder) Dir(dir string) *CommandBuilder {
+	builder.dir = dir
+	return builder
+}
+
+// Stdin specifies the input of the command, used to pass secrets without exposing them as arguments
+func (builder *CommandBuilder) Stdin(stdin io.Reader) *CommandBuilder {
+	builder.stdin = stdin
+	return builder
+}
+
+// Stdout specifies writers which the stdout of the command is copied to as it is read
+func (builder *CommandBuilder) Stdout(writers ...io.Writer) *CommandBuilder {
+	builder.stdout = append(builder.stdout, writers...)
+	return builder
+}
+
+// Stderr specifies writers which the stderr of the command is copied to as it is read
+func (builder *CommandBuilder) Stderr(writers ...io.Writer) *CommandBuilder {
+	builder.stderr = append(builder.stderr, writers...)
+	return builder
+}
+
+// Context specifies a context which kills the command if it is done before the command exits
+func (builder *CommandBuilder) Context(ctx context.Context) *CommandBuilder {
+	builder.ctx = ctx
+	return builder
+}
+
+// Timeout specifies a duration after which the command is killed if it has not exited
+func (builder *CommandBuilder) Timeout(timeout time.Duration) *CommandBuilder {
+	builder.timeout = timeout
+	return builder
+}
+
+// TempFiles specifies temporary files read by the command, such as auth files, which are removed once the
+// command has been waited on
+func (builder *CommandBuilder) TempFiles(paths ...string) *CommandBuilder {
+	builder.tempFiles = paths
+	return builder
+}
+
 // Builder initializes the CommandBuilder with default values
 func Builder(name string) *CommandBuilder {
 	cmdBuilder := new(CommandBuilder)
@@ -88,43 +188,159 @@ func Builder(name string) *CommandBuilder {
 
 // Exec executes a command, returning readers for both stdout and stderr pipes
 func (c *Command) Exec() (stdout io.ReadCloser, stderr io.ReadCloser, err error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cancel := func() {}
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
 	command := c.constructCommand()
-	cmd := executor(c.name, command...)
+	cmd := executor(ctx, c.name, command...)
+	if len(c.env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, c.env...)
+	}
+	cmd.Dir = c.dir
+	cmd.Stdin = c.stdin
 	stdout, _ = cmd.StdoutPipe()
-	stderr, _ = cmd.StderrPipe()
+	stderrPipe, _ := cmd.StderrPipe()
 	if err := cmd.Start(); err != nil {
+		cancel()
+		c.removeTempFiles()
 		return nil, nil, err
 	}
 	c.execCmd = cmd
-	return stdout, stderr, nil
+	c.execCtx = ctx
+	c.cancel = cancel
+	c.stderrTail = newTailBuffer(stderrTailSize)
+
+	// stderr is copied to its tail and tees as the command writes it, so they are complete whether or not
+	// the returned stderr is read. Stderr is buffered for its reader until the reader is closed.
+	stderrBuffer := newOutputBuffer()
+	c.stderrDone = make(chan struct{})
+	writers := append(append([]io.Writer{c.stderrTail}, c.stderr...), stderrBuffer)
+	go func(done chan<- struct{}) {
+		defer close(done)
+		_, err := io.Copy(io.MultiWriter(writers...), stderrPipe)
+		stderrBuffer.CloseWithError(err)
+	}(c.stderrDone)
+
+	if len(c.stdout) > 0 {
+		stdout = teeReadCloser(stdout, c.stdout...)
+	}
+	return stdout, stderrBuffer, nil
 }
 
-// Wait calls wait on a started exec command
-func (c Command) Wait() error {
-	if err := c.execCmd.Wait(); err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			fmt.Printf("Exit code is %d\n", exitError.ExitCode())
-			errorString := fmt.Sprintf("error in executing cmd, exited with code %d", exitError.ExitCode())
-			return errors.New(errorString)
+// ReadOutputs reads the stdout and stderr of an executed command concurrently, so that neither pipe blocks the
+// command from exiting
+func ReadOutputs(stdout io.ReadCloser, stderr io.ReadCloser) ([]byte, []byte) {
+	read := func(r io.ReadCloser) []byte {
+		if r == nil {
+			return nil
 		}
+		out, _ := ioutil.ReadAll(r)
+		return out
+	}
+
+	errOutput := make(chan []byte)
+	go func() {
+		errOutput <- read(stderr)
+	}()
+	output := read(stdout)
+	return output, <-errOutput
+}
+
+// Wait calls wait on a started exec command. A command which exits with a non-zero exit code or is killed
+// returns an ExitError.
+func (c Command) Wait() error {
+	defer c.removeTempFiles()
+	if c.cancel != nil {
+		defer c.cancel()
+	}
+	// stderr is copied to the end before waiting, as waiting closes the stderr pipe
+	if c.stderrDone != nil {
+		<-c.stderrDone
+	}
+	err := c.execCmd.Wait()
+	if err == nil {
+		return nil
+	}
+	exitError, ok := err.(*exec.ExitError)
+	if !ok {
+		return err
+	}
+	exitErr := &ExitError{
+		Command:  c.String(),
+		ExitCode: exitError.ExitCode(),
+		Err:      exitError,
+	}
+	if c.stderrTail != nil {
+		exitErr.Stderr = c.stderrTail.String()
+	}
+	// a command killed by its context reports the context error, such as the deadline being exceeded
+	if c.execCtx != nil && c.execCtx.Err() != nil {
+		exitErr.Err = c.execCtx.Err()
+	}
+	return exitErr
+}
+
+// ExitCode returns the exit code of a command which has been waited on, or -1 if the command has not exited
+func (c Command) ExitCode() int {
+	if c.execCmd == nil || c.execCmd.ProcessState == nil {
+		return -1
+	}
+	return c.execCmd.ProcessState.ExitCode()
+}
+
+// String returns the command line of the command with the values of sensitive flags redacted
+func (c Command) String() string {
+	return strings.Join(append([]string{c.name}, c.constructCommandVector(true)...), " ")
+}
+
+// removeTempFiles removes the temporary files read by the command
+func (c Command) removeTempFiles() {
+	for _, path := range c.tempFiles {
+		_ = os.RemoveAll(path)
 	}
-	return nil
 }
 
 func (c Command) constructCommand() []string {
-	var commandVector = []string{}
+	return c.constructCommandVector(false)
+}
+
+func (c Command) constructCommandVector(redact bool) []string {
+	var commandVector = constructFlags(c.globalFlags, redact)
 
 	if c.command != "" {
 		commandVector = append(commandVector, c.command)
 	}
 
-	for _, flag := range c.flags {
+	commandVector = append(commandVector, constructFlags(c.flags, redact)...)
+	return append(commandVector, c.args...)
+}
+
+func constructFlags(flags []Flag, redact bool) []string {
+	var flagVector = []string{}
+	for _, flag := range flags {
+		name := fmt.Sprintf("--%s", flag.Name)
 		if flag.Short {
-			commandVector = append(commandVector, fmt.Sprintf("-%s", flag.Name), flag.Value)
-		} else {
-			commandVector = append(commandVector, fmt.Sprintf("--%s", flag.Name), flag.Value)
+			name = fmt.Sprintf("-%s", flag.Name)
 		}
+		// flags without a value are boolean flags
+		if flag.Value == "" {
+			flagVector = append(flagVector, name)
+			continue
+		}
+		value := flag.Value
+		if redact && flag.Sensitive {
+			value = redacted
+		}
+		flagVector = append(flagVector, name, value)
 	}
-
-	return append(commandVector, c.args...)
+	return flagVector
 }
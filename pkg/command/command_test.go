@@ -1,9 +1,16 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,7 +25,7 @@ func TestCommand_constructCommand(t *testing.T) {
 
 func TestCommand_Exec(t *testing.T) {
 	executor = fakeExecCommand
-	defer func() { executor = exec.Command }()
+	defer func() { executor = exec.CommandContext }()
 
 	_, _, err := cmd.Exec()
 	assert.Equal(t, nil, err)
@@ -27,9 +34,9 @@ func TestCommand_Exec(t *testing.T) {
 func TestCommandBuilder_Flags(t *testing.T) {
 
 	flags := []Flag{
-		{"f", "Dockerfile", true, true},
-		{"storage-driver", "", false, true},
-		{"t", "image:tag", true, true},
+		{"f", "Dockerfile", true, true, false},
+		{"storage-driver", "", false, true, false},
+		{"t", "image:tag", true, true, false},
 	}
 
 	builder := Builder("test").Flags(flags...)
@@ -42,8 +49,8 @@ func TestCommandBuilder_Flags(t *testing.T) {
 }
 
 var expectedFlags = []Flag{
-	{"f", "Dockerfile", true, true},
-	{"t", "image:tag", true, true},
+	{"f", "Dockerfile", true, true, false},
+	{"t", "image:tag", true, true, false},
 }
 
 var cmd = builder.Command("build").Flags([]Flag{
@@ -60,24 +67,46 @@ var cmd = builder.Command("build").Flags([]Flag{
 }...).Args(".", "one", "two").Build()
 
 // enabling the mocking of exec commands as in https://npf.io/2015/06/testing-exec-command/
-func fakeExecCommand(command string, args ...string) *exec.Cmd {
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
 	cs := []string{"-test.run=TestHelperProcess", "--", command}
 	cs = append(cs, args...)
-	cmd := exec.Command(os.Args[0], cs...)
+	cmd := exec.CommandContext(ctx, os.Args[0], cs...)
 	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
 	return cmd
 }
 
+// TestHelperProcess is the process of fake exec commands, which behaves according to the command passed to it
 func TestHelperProcess(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
 	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 3 {
+		os.Exit(0)
+	}
+	switch args[2] {
+	case "fail":
+		fmt.Fprintln(os.Stderr, "Error: failed to build image")
+		os.Exit(3)
+	case "env":
+		dir, _ := os.Getwd()
+		fmt.Println(os.Getenv("OCIB_TEST"), dir)
+	case "sleep":
+		time.Sleep(10 * time.Second)
+	case "late-fail":
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprintln(os.Stderr, "Error: failed to build image")
+		os.Exit(3)
+	}
 	os.Exit(0)
 }
 
 func TestCommand_constructCommand_emptyCommand(t *testing.T) {
 	flags := []Flag{
-		{"testFlag", "flagValue", false, false},
+		{"testFlag", "flagValue", false, false, false},
 	}
 	command := Builder("test").Flags(flags...).Args("testArg").Build()
 	commandVector := command.constructCommand()
@@ -85,3 +114,174 @@ func TestCommand_constructCommand_emptyCommand(t *testing.T) {
 	expectedCommandVector := []string{"--testFlag", "flagValue", "testArg"}
 	assert.Equal(t, expectedCommandVector, commandVector)
 }
+
+func TestCommand_constructCommand_booleanFlag(t *testing.T) {
+	flags := []Flag{
+		{"quiet", "", false, false, false},
+		{"filter", "label=key=value", false, true, false},
+	}
+	command := Builder("test").Command("images").Flags(flags...).Build()
+	commandVector := command.constructCommand()
+
+	expectedCommandVector := []string{"images", "--quiet", "--filter", "label=key=value"}
+	assert.Equal(t, expectedCommandVector, commandVector)
+}
+
+func TestCommand_constructCommand_globalFlags(t *testing.T) {
+	command := Builder("test").GlobalFlags(Flag{"addr", "tcp://buildkitd:1234", false, true, false}).Command("build").Flags([]Flag{
+		{"frontend", "dockerfile.v0", false, true, false},
+		{"progress", "", false, true, false},
+	}...).Args("testArg").Build()
+	commandVector := command.constructCommand()
+
+	expectedCommandVector := []string{"--addr", "tcp://buildkitd:1234", "build", "--frontend", "dockerfile.v0", "testArg"}
+	assert.Equal(t, expectedCommandVector, commandVector)
+}
+
+func TestCommand_String(t *testing.T) {
+	sensitiveCmd := Builder("buildah").Command("push").Flags([]Flag{
+		{Name: "creds", Value: "user:pass", Short: false, OmitEmpty: true, Sensitive: true},
+		{Name: "digestfile", Value: "/tmp/digest", Short: false, OmitEmpty: true},
+	}...).Args("image-name").Build()
+
+	assert.Equal(t, "buildah push --creds ***** --digestfile /tmp/digest image-name", sensitiveCmd.String())
+	assert.Equal(t, []string{"push", "--creds", "user:pass", "--digestfile", "/tmp/digest", "image-name"}, sensitiveCmd.constructCommand())
+}
+
+func TestCommand_Wait_tempFiles(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	dir, err := ioutil.TempDir("", "ocib-command")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	tempCmd := Builder("buildah").Command("pull").Stdin(strings.NewReader("secret")).TempFiles(dir).Build()
+	_, _, err = tempCmd.Exec()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, tempCmd.Wait())
+
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCommand_Exec_envAndDir(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	dir, err := ioutil.TempDir("", "ocib-command")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	var tee bytes.Buffer
+	envCmd := Builder("test").Command("env").Env("OCIB_TEST=value").Dir(dir).Stdout(&tee).Build()
+	stdout, _, err := envCmd.Exec()
+	assert.Equal(t, nil, err)
+	output, err := ioutil.ReadAll(stdout)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, envCmd.Wait())
+
+	wd, err := os.Getwd()
+	assert.Equal(t, nil, err)
+	assert.True(t, strings.HasPrefix(string(output), "value "))
+	assert.NotEqual(t, wd, strings.TrimSpace(strings.TrimPrefix(string(output), "value ")))
+	assert.Equal(t, string(output), tee.String())
+}
+
+func TestCommand_Wait_exitError(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	failCmd := Builder("test").Command("fail").Flags(Flag{Name: "creds", Value: "user:pass", OmitEmpty: true, Sensitive: true}).Build()
+	_, stderr, err := failCmd.Exec()
+	assert.Equal(t, nil, err)
+	_, err = ioutil.ReadAll(stderr)
+	assert.Equal(t, nil, err)
+
+	err = failCmd.Wait()
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode)
+	assert.Equal(t, "Error: failed to build image", exitErr.Stderr)
+	assert.Equal(t, "error in executing test fail --creds *****, exited with code 3: Error: failed to build image", err.Error())
+}
+
+func TestCommand_Wait_exitErrorUnreadStderr(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	var tee bytes.Buffer
+	failCmd := Builder("test").Command("fail").Stderr(&tee).Build()
+	_, stderr, err := failCmd.Exec()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, stderr.Close())
+
+	err = failCmd.Wait()
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, "Error: failed to build image", exitErr.Stderr)
+	assert.Equal(t, "Error: failed to build image\n", tee.String())
+}
+
+func TestCommand_Wait_concurrentStderrReader(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	var tee bytes.Buffer
+	lateCmd := Builder("test").Command("late-fail").Stderr(&tee).Build()
+	_, stderr, err := lateCmd.Exec()
+	assert.Equal(t, nil, err)
+
+	type readResult struct {
+		output []byte
+		err    error
+	}
+	read := make(chan readResult)
+	go func() {
+		output, err := ioutil.ReadAll(stderr)
+		read <- readResult{output, err}
+	}()
+
+	err = lateCmd.Wait()
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, "Error: failed to build image", exitErr.Stderr)
+
+	result := <-read
+	assert.Equal(t, nil, result.err)
+	assert.Equal(t, "Error: failed to build image\n", string(result.output))
+	assert.Equal(t, "Error: failed to build image\n", tee.String())
+}
+
+func TestCommand_Wait_timeout(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	sleepCmd := Builder("test").Command("sleep").Timeout(100 * time.Millisecond).Build()
+	_, _, err := sleepCmd.Exec()
+	assert.Equal(t, nil, err)
+
+	err = sleepCmd.Wait()
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, -1, exitErr.ExitCode)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestReadOutputs(t *testing.T) {
+	executor = fakeExecCommand
+	defer func() { executor = exec.CommandContext }()
+
+	failCmd := Builder("test").Command("fail").Build()
+	stdout, stderr, err := failCmd.Exec()
+	assert.Equal(t, nil, err)
+
+	output, errOutput := ReadOutputs(stdout, stderr)
+	assert.Equal(t, "", string(output))
+	assert.Equal(t, "Error: failed to build image\n", string(errOutput))
+	assert.NotEqual(t, nil, failCmd.Wait())
+
+	output, errOutput = ReadOutputs(nil, nil)
+	assert.Equal(t, 0, len(output))
+	assert.Equal(t, 0, len(errOutput))
+}
@@ -0,0 +1,150 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// stderrTailSize is the number of bytes at the end of the stderr of a command kept for its ExitError
+const stderrTailSize = 4096
+
+// ExitError is the error of a command which exited with a non-zero exit code or was killed
+type ExitError struct {
+	// Command is the command line of the command, with the values of sensitive flags redacted
+	Command string
+	// ExitCode is the exit code of the command, or -1 if the command was killed by a signal
+	ExitCode int
+	// Stderr is the tail of the stderr of the command read before it was waited on
+	Stderr string
+	// Err is the underlying error, the context error if the command was killed by its context
+	Err error
+}
+
+// Error returns the error message of an ExitError
+func (e *ExitError) Error() string {
+	var msg string
+	if e.ExitCode < 0 {
+		msg = fmt.Sprintf("error in executing %s: %v", e.Command, e.Err)
+	} else {
+		msg = fmt.Sprintf("error in executing %s, exited with code %d", e.Command, e.ExitCode)
+	}
+	if e.Stderr != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Stderr)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error of an ExitError
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// tailBuffer is a writer which keeps the last bytes written to it, up to its size
+type tailBuffer struct {
+	mu   sync.Mutex
+	buf  []byte
+	size int
+}
+
+func newTailBuffer(size int) *tailBuffer {
+	return &tailBuffer{size: size}
+}
+
+// Write appends bytes to the tail buffer, discarding the oldest bytes beyond its size
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.size {
+		t.buf = append([]byte(nil), t.buf[len(t.buf)-t.size:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the bytes kept by the tail buffer, trimmed of surrounding whitespace
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.TrimSpace(string(t.buf))
+}
+
+// teeReader copies the output of a command to writers as it is read
+type teeReader struct {
+	io.Reader
+	io.Closer
+}
+
+func teeReadCloser(output io.ReadCloser, writers ...io.Writer) io.ReadCloser {
+	return teeReader{
+		Reader: io.TeeReader(output, io.MultiWriter(writers...)),
+		Closer: output,
+	}
+}
+
+// outputBuffer is a pipe which buffers the output written to it until it is read, so that writes never block
+// on the reader. Output written after the reader is closed is discarded.
+type outputBuffer struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	err    error
+	closed bool
+}
+
+func newOutputBuffer() *outputBuffer {
+	b := &outputBuffer{}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Write buffers output for the reader, or discards it if the reader has been closed
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.buf.Write(p)
+		b.cond.Broadcast()
+	}
+	return len(p), nil
+}
+
+// CloseWithError ends the output, reads return the error once the buffered output has been read, or io.EOF if
+// the error is nil
+func (b *outputBuffer) CloseWithError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		err = io.EOF
+	}
+	b.err = err
+	b.cond.Broadcast()
+}
+
+// Read reads buffered output, blocking until there is output or the output has ended
+func (b *outputBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.buf.Len() == 0 && b.err == nil && !b.closed {
+		b.cond.Wait()
+	}
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if b.buf.Len() > 0 {
+		return b.buf.Read(p)
+	}
+	return 0, b.err
+}
+
+// Close closes the reader, discarding buffered and later output
+func (b *outputBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.buf.Reset()
+	b.cond.Broadcast()
+	return nil
+}
@@ -35,6 +35,8 @@ const (
 	LabelKeyComplete = ocibuilder.FullName + "/complete"
 	// LabelOCIBuilderName is the label to indicate the name of an ocibuilder object
 	LabelOCIBuilderName = "ocibuilder-name"
+	// LabelBuildID is the label applied to every stage of a purged build step, used to prune intermediate images
+	LabelBuildID = ocibuilder.FullName + "/build-id"
 )
 
 // Miscellaneous constants for controller
@@ -64,6 +66,40 @@ const (
 	DefaultImageRegistry = "docker.io"
 )
 
+// DefaultDistrolessImage is the default base image of a distroless stage
+const (
+	DefaultDistrolessImage = "gcr.io/distroless/base"
+)
+
+// DefaultPushParallelism is the default number of images pushed concurrently
+const (
+	DefaultPushParallelism = 4
+)
+
+// Kaniko constants
+const (
+	// KanikoExecutor is the path of the kaniko executor binary in the kaniko image
+	KanikoExecutor = "/kaniko/executor"
+	// KanikoDockerConfigDir is the directory of the docker config kaniko reads registry auth from
+	KanikoDockerConfigDir = "/kaniko/.docker"
+	// KanikoImage is the kaniko executor image used by builder jobs, the debug variant includes a shell
+	KanikoImage = "gcr.io/kaniko-project/executor:debug"
+)
+
+// Builder job constants
+const (
+	// OCICtlImage is the image containing the ocictl binary which builder jobs run
+	OCICtlImage = "ocibuilder/ocictl"
+	// BuilderJobBinDirectory is the directory ocictl is copied to when the builder job runs in another image
+	BuilderJobBinDirectory = "/ocib/bin"
+	// BuilderJobSpecDirectory is the directory the ocibuilder.yaml of the resource is mounted at in builder jobs
+	BuilderJobSpecDirectory = "/ocib/spec"
+	// BuilderJobSpecKey is the key of the ocibuilder.yaml in the configmap mounted into builder jobs
+	BuilderJobSpecKey = "ocibuilder.yaml"
+	// DockerSocketPath is the path of the docker daemon socket mounted into docker builder jobs
+	DockerSocketPath = "/var/run/docker.sock"
+)
+
 // Build context constants
 const (
 	// ContextDirectory holds the ocibuilder context
@@ -78,6 +114,23 @@ const (
 	RemoteTempDirectory = "/ocib/temp/"
 )
 
+// Template source constants
+const (
+	// TemplateCacheDirectory is the directory in the home directory that imported build templates are cached in
+	TemplateCacheDirectory = ".ocibuilder/templates"
+	// ImageLayoutDirectory is the directory in the home directory of the OCI image layout the registry builder
+	// pulls images into and pushes images from
+	ImageLayoutDirectory = ".ocibuilder/images"
+	// DefaultTemplatesPath is the default path of the templates file in a git template source
+	DefaultTemplatesPath = "templates.yaml"
+)
+
+// TagRecordPath is the path of the record of the tags rendered for each build step, which push reads so that
+// images are pushed with the tags they were built with
+const (
+	TagRecordPath = "./ocib_tags.json"
+)
+
 // Remote paths
 const (
 	OverlayPath    = "./overlay_DOWNLOAD.yaml"
@@ -38,13 +38,9 @@ type BuildContextReader interface {
 
 // GetBuildContextReader returns a build context based on the store
 func GetBuildContextReader(buildContext *v1alpha1.BuildContext, k8sConfigPath string) (BuildContextReader, error) {
-	var k8sClient kubernetes.Interface
-	kubeConfig, err := util.GetClientConfig(k8sConfigPath)
-	if err == nil {
-		k8sClient, err = kubernetes.NewForConfig(kubeConfig)
-		if err != nil {
-			return nil, err
-		}
+	k8sClient, err := NewK8sClient(k8sConfigPath)
+	if err != nil {
+		return nil, err
 	}
 	if buildContext.AliyunOSSContext != nil {
 		return NewAliyunOSSBuildContextReader(buildContext.AliyunOSSContext, k8sClient), nil
@@ -67,6 +63,20 @@ func GetBuildContextReader(buildContext *v1alpha1.BuildContext, k8sConfigPath st
 	return nil, errors.New("unknown build context")
 }
 
+// NewK8sClient returns the kubernetes client used to read the credentials of build contexts, which is nil
+// when no kubernetes config is found
+func NewK8sClient(k8sConfigPath string) (kubernetes.Interface, error) {
+	kubeConfig, err := util.GetClientConfig(k8sConfigPath)
+	if err != nil {
+		return nil, nil
+	}
+	k8sClient, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return k8sClient, nil
+}
+
 // InjectDockerfile embeds the generated ocibuilder dockerfile into your build context tar
 // looking in /ocib/context/context.tar.gz
 func InjectDockerfile(contextPath string, dockerfilePath string) error {
@@ -107,7 +107,8 @@ func (contextReader *GitBuildContextReader) pullFromRepository(r *git.Repository
 			Name: contextReader.buildContext.Remote.Name,
 			URLs: contextReader.buildContext.Remote.URLS,
 		})
-		if err != nil {
+		// the remote of a repository opened again was created when it was first pulled
+		if err != nil && err != git.ErrRemoteExists {
 			return errors.Errorf("failed to create remote. err: %+v", err)
 		}
 
@@ -185,36 +186,61 @@ func (contextReader *GitBuildContextReader) getBranchOrTag() *git.CheckoutOption
 	return opts
 }
 
-func (contextReader *GitBuildContextReader) Read() (string, error) {
-	r, err := git.PlainOpen(common.ContextDirectory)
+// clone clones the repository of the build context into a directory
+func (contextReader *GitBuildContextReader) clone(dir string) (*git.Repository, error) {
+	cloneOpt := &git.CloneOptions{
+		URL:               contextReader.buildContext.URL,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+	}
+
+	auth, err := contextReader.getGitAuth()
 	if err != nil {
-		if err != git.ErrRepositoryNotExists {
-			return "", errors.Errorf("failed to open repository. err: %+v", err)
-		}
+		return nil, err
+	}
+	if auth != nil {
+		cloneOpt.Auth = auth
+	}
 
-		cloneOpt := &git.CloneOptions{
-			URL:               contextReader.buildContext.URL,
-			RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
-		}
+	// In the case of a specific given ref, it isn't necessary to have branch
+	// histories
+	if contextReader.buildContext.Ref != "" {
+		cloneOpt.Depth = 1
+	}
 
-		auth, err := contextReader.getGitAuth()
-		if err != nil {
-			return "", err
+	r, err := git.PlainClone(dir, false, cloneOpt)
+	if err != nil {
+		return nil, errors.Errorf("failed to clone repository. err: %+v", err)
+	}
+	return r, nil
+}
+
+// Checkout checks out the branch, tag or ref of the build context in a directory, cloning the
+// repository into the directory if it has not been cloned before
+func (contextReader *GitBuildContextReader) Checkout(dir string) error {
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		if err != git.ErrRepositoryNotExists {
+			return errors.Errorf("failed to open repository. err: %+v", err)
 		}
-		if auth != nil {
-			cloneOpt.Auth = auth
+		if r, err = contextReader.clone(dir); err != nil {
+			return err
 		}
+	}
+	if err := contextReader.pullFromRepository(r); err != nil {
+		return errors.Errorf("failed to pull latest changes from the repository. err: %+v", err)
+	}
+	return nil
+}
 
-		// In the case of a specific given ref, it isn't necessary to have branch
-		// histories
-		if contextReader.buildContext.Ref != "" {
-			cloneOpt.Depth = 1
+func (contextReader *GitBuildContextReader) Read() (string, error) {
+	r, err := git.PlainOpen(common.ContextDirectory)
+	if err != nil {
+		if err != git.ErrRepositoryNotExists {
+			return "", errors.Errorf("failed to open repository. err: %+v", err)
 		}
-
 		util.Logger.WithField("url", contextReader.buildContext.URL).Infoln("reading build context from git")
-		r, err = git.PlainClone(common.RemoteLocalDirectory+common.RemoteTempDirectory, false, cloneOpt)
-		if err != nil {
-			return "", errors.Errorf("failed to clone repository. err: %+v", err)
+		if r, err = contextReader.clone(common.RemoteLocalDirectory + common.RemoteTempDirectory); err != nil {
+			return "", err
 		}
 	}
 	if err := contextReader.pullFromRepository(r); err != nil {
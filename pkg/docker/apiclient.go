@@ -0,0 +1,108 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/docker/docker/client"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewAPIClient returns a docker API client for the daemon of the docker spec. The docker environment variables
+// configure the client, overridden by any host, API version and TLS in the spec.
+func NewAPIClient(spec *v1alpha1.DockerSpec, k8sClient kubernetes.Interface) (*client.Client, error) {
+	opts := []client.Opt{client.FromEnv}
+	if spec == nil {
+		return client.NewClientWithOpts(append(opts, client.WithAPIVersionNegotiation())...)
+	}
+
+	if spec.TLS != nil {
+		tlsConfig, err := newTLSConfig(spec.TLS, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+		// the http client is set before the host, which configures the transport of the client for the host
+		opts = append(opts, client.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}))
+	}
+
+	host := spec.Host
+	if host == "" && spec.TLS != nil {
+		// the host of the environment was configured on the transport replaced by the http client, so is
+		// set again to configure the new transport
+		if host = os.Getenv("DOCKER_HOST"); host == "" {
+			host = client.DefaultDockerHost
+		}
+	}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	if spec.APIVersion != "" {
+		opts = append(opts, client.WithVersion(spec.APIVersion))
+	} else {
+		opts = append(opts, client.WithAPIVersionNegotiation())
+	}
+	return client.NewClientWithOpts(opts...)
+}
+
+// newTLSConfig returns the TLS configuration of the PEM encoded certificates of a docker TLS spec
+func newTLSConfig(spec *v1alpha1.DockerTLS, k8sClient kubernetes.Interface) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+
+	ca, err := util.ReadCredentials(k8sClient, spec.CA)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the docker tls ca")
+	}
+	if ca != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(ca)) {
+			return nil, errors.New("failed to parse the docker tls ca")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	cert, err := util.ReadCredentials(k8sClient, spec.Cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the docker tls cert")
+	}
+	key, err := util.ReadCredentials(k8sClient, spec.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the docker tls key")
+	}
+	if (cert == "") != (key == "") {
+		return nil, errors.New("both a docker tls cert and key must be specified")
+	}
+	if cert != "" {
+		keyPair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse the docker tls cert and key")
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIClient(t *testing.T) {
+	apiClient, err := NewAPIClient(&v1alpha1.DockerSpec{
+		Host:       "tcp://build-host:2375",
+		APIVersion: "1.40",
+	}, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tcp://build-host:2375", apiClient.DaemonHost())
+	assert.Equal(t, "1.40", apiClient.ClientVersion())
+}
+
+func TestNewAPIClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.40")
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	apiClient, err := NewAPIClient(&v1alpha1.DockerSpec{
+		Host:       "tcp://" + strings.TrimPrefix(server.URL, "https://"),
+		APIVersion: "1.40",
+		TLS: &v1alpha1.DockerTLS{
+			CA: &v1alpha1.Credentials{Plain: string(ca)},
+		},
+	}, nil)
+	assert.Equal(t, nil, err)
+
+	ping, err := apiClient.Ping(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1.40", ping.APIVersion)
+}
+
+func TestNewAPIClient_TLSHostFromEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-docker")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "docker.sock"))
+	assert.Equal(t, nil, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.40")
+		_, _ = w.Write([]byte("OK"))
+	}))
+	server.Listener = listener
+	server.StartTLS()
+	defer server.Close()
+
+	defer os.Setenv("DOCKER_HOST", os.Getenv("DOCKER_HOST"))
+	os.Setenv("DOCKER_HOST", "unix://"+filepath.Join(dir, "docker.sock"))
+
+	apiClient, err := NewAPIClient(&v1alpha1.DockerSpec{
+		APIVersion: "1.40",
+		TLS: &v1alpha1.DockerTLS{
+			InsecureSkipVerify: true,
+		},
+	}, nil)
+	assert.Equal(t, nil, err)
+
+	ping, err := apiClient.Ping(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1.40", ping.APIVersion)
+}
+
+func TestNewAPIClient_InvalidTLS(t *testing.T) {
+	_, err := NewAPIClient(&v1alpha1.DockerSpec{
+		TLS: &v1alpha1.DockerTLS{
+			CA: &v1alpha1.Credentials{Plain: "not a certificate"},
+		},
+	}, nil)
+	assert.Error(t, err)
+
+	_, err = NewAPIClient(&v1alpha1.DockerSpec{
+		TLS: &v1alpha1.DockerTLS{
+			Cert: &v1alpha1.Credentials{Plain: "cert"},
+		},
+	}, nil)
+	assert.Error(t, err)
+}
@@ -17,16 +17,25 @@ limitations under the License.
 package docker
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
 
 	"github.com/docker/docker/api/types/image"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/layout"
 )
 
 // Client is the client used for building with Docker using the ocibuilder
@@ -83,6 +92,31 @@ func (cli Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRe
 	}, nil
 }
 
+// ImagesPrune prunes dangling images with Docker using the ocibuilder, only images with all the passed in labels are pruned
+func (cli Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	apiCli := cli.APIClient
+	pruneFilters := filters.NewArgs(filters.Arg("dangling", "true"))
+	for _, l := range options.Labels {
+		pruneFilters.Add("label", l)
+	}
+	res, err := apiCli.ImagesPrune(options.Ctx, pruneFilters)
+	if err != nil {
+		return v1alpha1.OCIPruneResponse{}, err
+	}
+	return v1alpha1.OCIPruneResponse{
+		ImagesPruneReport: res,
+	}, nil
+}
+
+// ImageTag tags an image with Docker using the ocibuilder
+func (cli Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	apiCli := cli.APIClient
+	if err := apiCli.ImageTag(options.Ctx, options.Source, options.Target); err != nil {
+		return v1alpha1.OCITagResponse{}, err
+	}
+	return v1alpha1.OCITagResponse{}, nil
+}
+
 // ImageInspect conducts an inspect of a built image with Docker using the ocibuilder
 func (cli Client) ImageInspect(imageId string) (types.ImageInspect, error) {
 	apiCli := cli.APIClient
@@ -103,6 +137,126 @@ func (cli Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, err
 	return res, nil
 }
 
+// ImageSave exports an image from the Docker daemon using the ocibuilder. Docker saves images as a docker-archive,
+// which is converted into an OCI image layout for the oci and oci-archive formats.
+func (cli Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	apiCli := cli.APIClient
+	res, err := apiCli.ImageSave(options.Ctx, []string{options.Image})
+	if err != nil {
+		return v1alpha1.OCISaveResponse{}, err
+	}
+	defer res.Close()
+
+	switch options.Format {
+	case v1alpha1.DockerArchiveExportFormat:
+		err = writeFile(options.Dest, res)
+	case v1alpha1.OCIExportFormat:
+		err = layout.FromDockerArchive(res, options.Dest)
+	case v1alpha1.OCIArchiveExportFormat:
+		err = saveOCIArchive(res, options.Dest)
+	default:
+		err = errors.Errorf("unsupported export format %s", options.Format)
+	}
+	if err != nil {
+		return v1alpha1.OCISaveResponse{}, err
+	}
+	return v1alpha1.OCISaveResponse{}, nil
+}
+
+// ImageLoad loads an image into the Docker daemon using the ocibuilder. Images in the oci and oci-archive formats
+// are converted into a docker-archive as they are streamed to the daemon.
+func (cli Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	apiCli := cli.APIClient
+
+	var input io.ReadCloser
+	switch options.Format {
+	case v1alpha1.DockerArchiveExportFormat:
+		// the input is closed by the api client once the request has been sent
+		f, err := os.Open(options.Input)
+		if err != nil {
+			return v1alpha1.OCILoadResponse{}, err
+		}
+		input = f
+	case v1alpha1.OCIExportFormat:
+		input = streamDockerArchive(options.Input, "")
+	case v1alpha1.OCIArchiveExportFormat:
+		dir, err := ioutil.TempDir("", "ocib-load")
+		if err != nil {
+			return v1alpha1.OCILoadResponse{}, err
+		}
+		if err := extractFile(options.Input, dir); err != nil {
+			os.RemoveAll(dir)
+			return v1alpha1.OCILoadResponse{}, err
+		}
+		input = streamDockerArchive(dir, dir)
+	default:
+		return v1alpha1.OCILoadResponse{}, errors.Errorf("unsupported load format %s", options.Format)
+	}
+
+	res, err := apiCli.ImageLoad(options.Ctx, input, false)
+	if err != nil {
+		// closing the input unblocks the goroutine streaming an archive, which then removes any extracted archive
+		input.Close()
+		return v1alpha1.OCILoadResponse{}, err
+	}
+	return v1alpha1.OCILoadResponse{
+		Body: res.Body,
+	}, nil
+}
+
+// ContainerRun runs a command in a container of an image with Docker using the ocibuilder, removing the
+// container once the command has exited
+func (cli Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	apiCli := cli.APIClient
+	if len(options.Cmd) == 0 {
+		return v1alpha1.OCIRunResponse{}, errors.New("no command specified to run")
+	}
+
+	config := &container.Config{
+		Image:      options.Image,
+		Entrypoint: options.Cmd[:1],
+		Cmd:        options.Cmd[1:],
+	}
+	created, err := apiCli.ContainerCreate(options.Ctx, config, nil, nil, "")
+	if err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	defer func() {
+		if err := apiCli.ContainerRemove(options.Ctx, created.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			cli.Logger.WithError(err).Warnln("error removing container")
+		}
+	}()
+
+	if err := apiCli.ContainerStart(options.Ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+
+	var exitCode int
+	statusChan, errChan := apiCli.ContainerWait(options.Ctx, created.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errChan:
+		return v1alpha1.OCIRunResponse{}, err
+	case status := <-statusChan:
+		exitCode = int(status.StatusCode)
+	}
+
+	logs, err := apiCli.ContainerLogs(options.Ctx, created.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	defer logs.Close()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(stdout, stderr, logs); err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	return v1alpha1.OCIRunResponse{
+		Body:     ioutil.NopCloser(stdout),
+		Stderr:   ioutil.NopCloser(stderr),
+		ExitCode: exitCode,
+	}, nil
+}
+
 // RegistryLogin conducts a registry login with Docker using the ocibuilder
 func (cli Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
 	apiCli := cli.APIClient
@@ -123,3 +277,65 @@ func (cli Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
 	}
 	return base64.URLEncoding.EncodeToString(encodedJSON)
 }
+
+// Capabilities returns the capabilities of Docker, which stores built images locally
+func (cli Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{LocalImages: true}
+}
+
+// saveOCIArchive converts a docker-archive into an OCI image layout and writes it as a tarball to dest
+func saveOCIArchive(archive io.Reader, dest string) error {
+	dir, err := ioutil.TempDir("", "ocib-export")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := layout.FromDockerArchive(archive, dir); err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		pw.CloseWithError(layout.Archive(dir, pw))
+	}()
+	return writeFile(dest, pr)
+}
+
+// streamDockerArchive streams the OCI image layout at src as a docker-archive, removing the cleanup
+// directory once the layout has been fully read
+func streamDockerArchive(src string, cleanup string) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		err := layout.ToDockerArchive(src, pw)
+		if cleanup != "" {
+			os.RemoveAll(cleanup)
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// extractFile extracts the archive at path into the directory dest
+func extractFile(path string, dest string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return layout.Extract(f, dest)
+}
+
+// writeFile writes the contents of r to a file at path
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", path)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return f.Close()
+}
@@ -1,15 +1,30 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/layout"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,6 +48,131 @@ func TestClient_ImageRemove(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestClient_ImagesPrune(t *testing.T) {
+	res, err := cli.ImagesPrune(v1alpha1.OCIPruneOptions{
+		Labels: []string{"build-id=1234"},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.ImageDeleteResponseItem{{Deleted: "dangling=true,label=build-id=1234"}}, res.ImagesDeleted)
+}
+
+func TestClient_ImageTag(t *testing.T) {
+	_, err := cli.ImageTag(v1alpha1.OCITagOptions{})
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-export")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	dest := filepath.Join(dir, "image.tar")
+	_, err = cli.ImageSave(v1alpha1.OCISaveOptions{
+		Image:  "image-name:v0.1.0",
+		Format: v1alpha1.DockerArchiveExportFormat,
+		Dest:   dest,
+	})
+	assert.Equal(t, nil, err)
+
+	archive, err := ioutil.ReadFile(dest)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "image archive", string(archive))
+}
+
+func TestClient_ImageSave_InvalidFormat(t *testing.T) {
+	_, err := cli.ImageSave(v1alpha1.OCISaveOptions{
+		Image:  "image-name:v0.1.0",
+		Format: "invalid",
+	})
+	assert.Error(t, err)
+}
+
+func TestClient_ImageLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-load")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "image.tar")
+	assert.Equal(t, nil, ioutil.WriteFile(input, []byte("image archive"), 0644))
+
+	res, err := cli.ImageLoad(v1alpha1.OCILoadOptions{
+		Input:  input,
+		Format: v1alpha1.DockerArchiveExportFormat,
+	})
+	assert.Equal(t, nil, err)
+
+	body, err := ioutil.ReadAll(res.Body)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "image archive", string(body))
+}
+
+func TestClient_ImageLoad_Failed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-load-failed")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	layoutDir := filepath.Join(dir, "layout")
+	assert.Equal(t, nil, layout.FromDockerArchive(bytes.NewReader(dockerArchive(t)), layoutDir))
+	archive := &bytes.Buffer{}
+	assert.Equal(t, nil, layout.Archive(layoutDir, archive))
+	input := filepath.Join(dir, "image.tar")
+	assert.Equal(t, nil, ioutil.WriteFile(input, archive.Bytes(), 0644))
+
+	// the archive is extracted to a temp dir in the test dir, which is removed once the load fails
+	tmpDir := filepath.Join(dir, "tmp")
+	assert.Equal(t, nil, os.Mkdir(tmpDir, 0755))
+	defer os.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	os.Setenv("TMPDIR", tmpDir)
+
+	failingCli := Client{
+		Logger:    util.GetLogger(true),
+		APIClient: testFailingLoadClient{},
+	}
+	_, err = failingCli.ImageLoad(v1alpha1.OCILoadOptions{
+		Input:  input,
+		Format: v1alpha1.OCIArchiveExportFormat,
+	})
+	assert.Error(t, err)
+
+	var remaining []os.FileInfo
+	for i := 0; i < 100; i++ {
+		if remaining, err = ioutil.ReadDir(tmpDir); err != nil || len(remaining) == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(remaining))
+}
+
+func TestClient_ImageLoad_InvalidFormat(t *testing.T) {
+	_, err := cli.ImageLoad(v1alpha1.OCILoadOptions{
+		Input:  "image.tar",
+		Format: "invalid",
+	})
+	assert.Error(t, err)
+}
+
+func TestClient_ContainerRun(t *testing.T) {
+	res, err := cli.ContainerRun(v1alpha1.OCIRunOptions{
+		Image: "image-name:v0.1.0",
+		Cmd:   []string{"echo", "hello"},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, res.ExitCode)
+
+	stdout, err := ioutil.ReadAll(res.Body)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello", string(stdout))
+
+	stderr, err := ioutil.ReadAll(res.Stderr)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "image-name:v0.1.0 [echo]", string(stderr))
+
+	_, err = cli.ContainerRun(v1alpha1.OCIRunOptions{Image: "image-name:v0.1.0"})
+	assert.Error(t, err)
+}
+
 func TestClient_RegistryLogin(t *testing.T) {
 	_, err := cli.RegistryLogin(v1alpha1.OCILoginOptions{})
 	assert.Equal(t, nil, err)
@@ -73,6 +213,32 @@ func (t testClient) ImageRemove(ctx context.Context, image string, options types
 	return nil, nil
 }
 
+func (t testClient) ImagesPrune(ctx context.Context, pruneFilters filters.Args) (types.ImagesPruneReport, error) {
+	var applied []string
+	for _, key := range []string{"dangling", "label"} {
+		for _, value := range pruneFilters.Get(key) {
+			applied = append(applied, key+"="+value)
+		}
+	}
+	return types.ImagesPruneReport{
+		ImagesDeleted: []types.ImageDeleteResponseItem{{Deleted: strings.Join(applied, ",")}},
+	}, nil
+}
+
+func (t testClient) ImageTag(ctx context.Context, source, target string) error {
+	return nil
+}
+
+func (t testClient) ImageSave(ctx context.Context, images []string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader("image archive")), nil
+}
+
+func (t testClient) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	return types.ImageLoadResponse{
+		Body: ioutil.NopCloser(input),
+	}, nil
+}
+
 func (t testClient) RegistryLogin(ctx context.Context, auth types.AuthConfig) (registry.AuthenticateOKBody, error) {
 	return registry.AuthenticateOKBody{
 		IdentityToken: "",
@@ -80,6 +246,67 @@ func (t testClient) RegistryLogin(ctx context.Context, auth types.AuthConfig) (r
 	}, nil
 }
 
+func (t testClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	return container.ContainerCreateCreatedBody{
+		ID: fmt.Sprintf("%s %v", config.Image, config.Entrypoint),
+	}, nil
+}
+
+func (t testClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	return nil
+}
+
+func (t testClient) ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error) {
+	statusChan := make(chan container.ContainerWaitOKBody, 1)
+	statusChan <- container.ContainerWaitOKBody{StatusCode: 3}
+	return statusChan, make(chan error)
+}
+
+// ContainerLogs returns a multiplexed log stream with hello on stdout and the container id on stderr
+func (t testClient) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	buf := &bytes.Buffer{}
+	if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte("hello")); err != nil {
+		return nil, err
+	}
+	if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stderr).Write([]byte(container)); err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(buf), nil
+}
+
+func (t testClient) ContainerRemove(ctx context.Context, container string, options types.ContainerRemoveOptions) error {
+	return nil
+}
+
 type testClient struct {
 	client.APIClient
 }
+
+// testFailingLoadClient fails image loads without reading the input
+type testFailingLoadClient struct {
+	testClient
+}
+
+func (t testFailingLoadClient) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	return types.ImageLoadResponse{}, errors.New("load failed")
+}
+
+// dockerArchive returns a docker-archive of a single image with a single layer
+func dockerArchive(t *testing.T) []byte {
+	config := `{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`
+	configName := digest.FromString(config).Hex() + ".json"
+	files := map[string]string{
+		configName:      config,
+		"abc/layer.tar": "layer contents",
+		"manifest.json": `[{"Config":"` + configName + `","RepoTags":["image-name:v0.1.0"],"Layers":["abc/layer.tar"]}]`,
+	}
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, contents := range files {
+		assert.Equal(t, nil, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(contents))
+		assert.Equal(t, nil, err)
+	}
+	assert.Equal(t, nil, tw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,592 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Operation is an operation of a builder client
+type Operation string
+
+// Operations of a builder client, named after the method of the operation
+const (
+	BuildOperation        Operation = "ImageBuild"
+	PullOperation         Operation = "ImagePull"
+	PushOperation         Operation = "ImagePush"
+	RemoveOperation       Operation = "ImageRemove"
+	TagOperation          Operation = "ImageTag"
+	PruneOperation        Operation = "ImagesPrune"
+	InspectOperation      Operation = "ImageInspect"
+	HistoryOperation      Operation = "ImageHistory"
+	SaveOperation         Operation = "ImageSave"
+	LoadOperation         Operation = "ImageLoad"
+	RunOperation          Operation = "ContainerRun"
+	LoginOperation        Operation = "RegistryLogin"
+	AuthRegistryOperation Operation = "GenerateAuthRegistryString"
+)
+
+// Call is a call made to the fake client
+type Call struct {
+	// Operation is the operation called
+	Operation Operation
+	// Options are the options passed to the operation, the image ID for inspects and histories and the auth
+	// config for auth registry strings
+	Options interface{}
+}
+
+// Client is an in-memory builder client, which simulates the image storage of a daemon and a registry without
+// either. Every call is recorded with its options and failures can be injected for each operation. Responses
+// are streams of docker json messages as returned by the docker daemon.
+type Client struct {
+	Logger *logrus.Logger
+
+	mu       sync.Mutex
+	calls    []Call
+	failures map[Operation]error
+	// images are the images stored locally, keyed by image ID
+	images map[string]*Image
+	// names are the image IDs of each image name stored locally
+	names map[string]string
+	// registry are the images pushed, keyed by image name
+	registry map[string]*Image
+	// saved are the image IDs saved to each destination, which can be loaded again
+	saved map[string]string
+	// builds is the number of builds run, which makes the ID of each build unique
+	builds int
+}
+
+// NewClient returns a fake client without any images
+func NewClient(logger *logrus.Logger) *Client {
+	return &Client{
+		Logger: logger,
+	}
+}
+
+// Fail injects an error which is returned by every call of an operation. A nil error clears the failure.
+func (c *Client) Fail(op Operation, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	if err == nil {
+		delete(c.failures, op)
+		return
+	}
+	c.failures[op] = err
+}
+
+// Calls returns the calls made to the client, in order
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// Images returns the images stored locally, ordered by ID
+func (c *Client) Images() []Image {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var images []Image
+	for _, img := range c.images {
+		images = append(images, *img.copy())
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].ID < images[j].ID })
+	return images
+}
+
+// ImageBuild builds an image from the steps of the Dockerfile in the build context, tagging it with each tag
+func (c *Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(BuildOperation, options); err != nil {
+		return v1alpha1.OCIBuildResponse{}, err
+	}
+
+	context := options.Context
+	if context == nil {
+		context = options.ImageBuildOptions.Context
+	}
+	steps, err := readSteps(context, options.Dockerfile)
+	if err != nil {
+		return v1alpha1.OCIBuildResponse{}, errors.Wrap(err, "failed to read the build context")
+	}
+
+	c.builds++
+	img := &Image{}
+	var out stream
+	parent := ""
+	for i, step := range steps {
+		out.output(fmt.Sprintf("Step %d/%d : %s", i+1, len(steps), step))
+		instruction := strings.ToUpper(strings.Fields(step)[0])
+		if instruction == "FROM" {
+			img = c.baseImage(fromImage(step))
+		} else {
+			createdBy, size := "/bin/sh -c #(nop)  "+step, int64(0)
+			if instruction == "RUN" || instruction == "COPY" || instruction == "ADD" {
+				createdBy = "/bin/sh -c " + strings.TrimSpace(step[len(instruction):])
+				img.Layers = append(img.Layers, digestOf(parent, step))
+				size = layerSize
+			}
+			img.History = append([]image.HistoryResponseItem{{
+				ID:        "<missing>",
+				Created:   time.Now().Unix(),
+				CreatedBy: createdBy,
+				Size:      size,
+			}}, img.History...)
+		}
+		parent = digestOf(parent, step, fmt.Sprint(c.builds))
+		out.output(" ---> " + shortID(parent))
+	}
+
+	img.ID = digestOf(append([]string{parent, fmt.Sprint(c.builds)}, img.Layers...)...)
+	img.Names, img.Digests = nil, nil
+	img.Created = time.Now()
+	if img.Labels == nil && len(options.Labels) > 0 {
+		img.Labels = make(map[string]string)
+	}
+	for k, v := range options.Labels {
+		img.Labels[k] = v
+	}
+	if len(img.History) > 0 {
+		img.History[0].ID = img.ID
+	}
+	c.images[img.ID] = img
+
+	if err := out.aux(types.BuildResult{ID: img.ID}); err != nil {
+		return v1alpha1.OCIBuildResponse{}, err
+	}
+	out.output("Successfully built " + shortID(img.ID))
+	for _, tag := range options.Tags {
+		c.tag(img, tag)
+		out.output("Successfully tagged " + normalize(tag))
+	}
+	c.Logger.WithField("id", img.ID).Debugln("built fake image")
+
+	body, err := out.body()
+	if err != nil {
+		return v1alpha1.OCIBuildResponse{}, err
+	}
+	return v1alpha1.OCIBuildResponse{
+		ImageBuildResponse: types.ImageBuildResponse{Body: body},
+	}, nil
+}
+
+// ImagePull pulls an image from the fake registry. Images which have not been pushed are created as if pulled
+// from a remote registry.
+func (c *Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(PullOperation, options); err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+
+	name := normalize(options.Ref)
+	remote := c.remoteImage(name)
+	var out stream
+	out.status("Pulling from "+repository(name), tagOf(name))
+	out.status("Digest: "+digestOf(remote.ID), "")
+	if id, ok := c.names[name]; ok && id == remote.ID {
+		out.status("Status: Image is up to date for "+name, "")
+	} else {
+		for _, layer := range remote.Layers {
+			out.status("Pull complete", shortID(layer))
+		}
+		if _, ok := c.images[remote.ID]; !ok {
+			c.images[remote.ID] = remote
+		}
+		c.tag(c.images[remote.ID], name)
+		out.status("Status: Downloaded newer image for "+name, "")
+	}
+	c.images[remote.ID].addDigest(name, digestOf(remote.ID))
+
+	body, err := out.body()
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+	return v1alpha1.OCIPullResponse{Body: body}, nil
+}
+
+// ImagePush pushes a local image to the fake registry, reporting the digest of the pushed image
+func (c *Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(PushOperation, options); err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+
+	name := normalize(options.Ref)
+	id, ok := c.names[name]
+	if !ok {
+		return v1alpha1.OCIPushResponse{}, errors.Errorf("An image does not exist locally with the tag: %s", repository(name))
+	}
+	img := c.images[id]
+	digest := digestOf(img.ID)
+
+	var out stream
+	out.status(fmt.Sprintf("The push refers to repository [%s]", repository(name)), "")
+	for _, layer := range img.Layers {
+		out.status("Pushed", shortID(layer))
+	}
+	size := len(img.Layers)*100 + 200
+	out.status(fmt.Sprintf("%s: digest: %s size: %d", tagOf(name), digest, size), "")
+	if err := out.aux(types.PushResult{Tag: tagOf(name), Digest: digest, Size: size}); err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+
+	img.addDigest(name, digest)
+	pushed := img.copy()
+	pushed.Names = nil
+	c.registry[name] = pushed
+
+	body, err := out.body()
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+	return v1alpha1.OCIPushResponse{Body: body}, nil
+}
+
+// ImageRemove untags an image name, deleting the image once it has no names. Removing an image by ID which
+// has more than one name requires force.
+func (c *Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(RemoveOperation, options); err != nil {
+		return v1alpha1.OCIRemoveResponse{}, err
+	}
+
+	img, err := c.lookup(options.Image)
+	if err != nil {
+		return v1alpha1.OCIRemoveResponse{}, err
+	}
+
+	var res []types.ImageDeleteResponseItem
+	if name := normalize(options.Image); c.names[name] == img.ID {
+		img.removeName(name)
+		delete(c.names, name)
+		res = append(res, types.ImageDeleteResponseItem{Untagged: name})
+	} else {
+		if len(img.Names) > 1 && !options.Force {
+			return v1alpha1.OCIRemoveResponse{}, errors.Errorf("conflict: unable to delete %s (must be forced) - image is referenced in multiple repositories", shortID(img.ID))
+		}
+		for _, name := range img.Names {
+			delete(c.names, name)
+			res = append(res, types.ImageDeleteResponseItem{Untagged: name})
+		}
+		img.Names = nil
+	}
+	if len(img.Names) == 0 {
+		delete(c.images, img.ID)
+		res = append(res, types.ImageDeleteResponseItem{Deleted: img.ID})
+	}
+	return v1alpha1.OCIRemoveResponse{Response: res}, nil
+}
+
+// ImageTag tags a local image with a new name, moving the name from any image already tagged with it
+func (c *Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(TagOperation, options); err != nil {
+		return v1alpha1.OCITagResponse{}, err
+	}
+
+	img, err := c.lookup(options.Source)
+	if err != nil {
+		return v1alpha1.OCITagResponse{}, err
+	}
+	c.tag(img, options.Target)
+	return v1alpha1.OCITagResponse{}, nil
+}
+
+// ImagesPrune deletes the dangling images without any names, which have all the passed in labels
+func (c *Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(PruneOperation, options); err != nil {
+		return v1alpha1.OCIPruneResponse{}, err
+	}
+
+	var report types.ImagesPruneReport
+	var ids []string
+	for id, img := range c.images {
+		if len(img.Names) == 0 && img.hasLabels(options.Labels) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		report.ImagesDeleted = append(report.ImagesDeleted, types.ImageDeleteResponseItem{Deleted: id})
+		report.SpaceReclaimed += uint64(c.images[id].Size())
+		delete(c.images, id)
+	}
+	return v1alpha1.OCIPruneResponse{ImagesPruneReport: report}, nil
+}
+
+// ImageInspect inspects a local image by name or ID
+func (c *Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(InspectOperation, imageId); err != nil {
+		return types.ImageInspect{}, err
+	}
+
+	img, err := c.lookup(imageId)
+	if err != nil {
+		return types.ImageInspect{}, err
+	}
+	return types.ImageInspect{
+		ID:           img.ID,
+		RepoTags:     append([]string{}, img.Names...),
+		RepoDigests:  append([]string{}, img.Digests...),
+		Created:      img.Created.Format(time.RFC3339Nano),
+		Architecture: "amd64",
+		Os:           "linux",
+		Size:         img.Size(),
+		VirtualSize:  img.Size(),
+		Config:       &container.Config{Labels: img.Labels},
+		RootFS: types.RootFS{
+			Type:   "layers",
+			Layers: append([]string{}, img.Layers...),
+		},
+	}, nil
+}
+
+// ImageHistory returns the history of a local image by name or ID, newest first
+func (c *Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(HistoryOperation, imageId); err != nil {
+		return nil, err
+	}
+
+	img, err := c.lookup(imageId)
+	if err != nil {
+		return nil, err
+	}
+	history := append([]image.HistoryResponseItem{}, img.History...)
+	if len(history) > 0 {
+		history[0].Tags = append([]string{}, img.Names...)
+	}
+	return history, nil
+}
+
+// ImageSave saves a local image to a destination in memory, from which it can be loaded again. Nothing is
+// written to the destination.
+func (c *Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(SaveOperation, options); err != nil {
+		return v1alpha1.OCISaveResponse{}, err
+	}
+
+	img, err := c.lookup(options.Image)
+	if err != nil {
+		return v1alpha1.OCISaveResponse{}, err
+	}
+	c.saved[options.Dest] = img.ID
+	return v1alpha1.OCISaveResponse{}, nil
+}
+
+// ImageLoad loads an image saved by the fake client, the image must still be stored locally
+func (c *Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(LoadOperation, options); err != nil {
+		return v1alpha1.OCILoadResponse{}, err
+	}
+
+	id, ok := c.saved[options.Input]
+	if !ok {
+		return v1alpha1.OCILoadResponse{}, errors.Errorf("no image saved to %s", options.Input)
+	}
+	img, ok := c.images[id]
+	if !ok {
+		return v1alpha1.OCILoadResponse{}, errors.Errorf("image %s saved to %s no longer exists", id, options.Input)
+	}
+
+	var out stream
+	if len(img.Names) == 0 {
+		out.output("Loaded image ID: " + img.ID)
+	}
+	for _, name := range img.Names {
+		out.output("Loaded image: " + name)
+	}
+	body, err := out.body()
+	if err != nil {
+		return v1alpha1.OCILoadResponse{}, err
+	}
+	return v1alpha1.OCILoadResponse{Body: body}, nil
+}
+
+// ContainerRun runs a command in a container of a local image, which exits successfully without any output
+func (c *Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(RunOperation, options); err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+
+	if len(options.Cmd) == 0 {
+		return v1alpha1.OCIRunResponse{}, errors.New("no command specified to run")
+	}
+	if _, err := c.lookup(options.Image); err != nil {
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	return v1alpha1.OCIRunResponse{
+		Body:   ioutil.NopCloser(strings.NewReader("")),
+		Stderr: ioutil.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+// RegistryLogin logs in to a registry, which always succeeds
+func (c *Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(LoginOperation, options); err != nil {
+		return v1alpha1.OCILoginResponse{}, err
+	}
+	return v1alpha1.OCILoginResponse{
+		AuthenticateOKBody: registry.AuthenticateOKBody{Status: "Login Succeeded"},
+	}, nil
+}
+
+// GenerateAuthRegistryString generates the auth registry string in the same form as the docker client
+func (c *Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.record(AuthRegistryOperation, auth)
+
+	encodedJSON, err := json.Marshal(auth)
+	if err != nil {
+		c.Logger.WithError(err).Errorln("error trying to marshall auth config")
+	}
+	return base64.URLEncoding.EncodeToString(encodedJSON)
+}
+
+// Capabilities returns the capabilities of the fake client, which stores built images locally like docker
+func (c *Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{LocalImages: true}
+}
+
+// init initializes the storage of the client, so that the zero value of a client can be used
+func (c *Client) init() {
+	if c.images == nil {
+		c.failures = make(map[Operation]error)
+		c.images = make(map[string]*Image)
+		c.names = make(map[string]string)
+		c.registry = make(map[string]*Image)
+		c.saved = make(map[string]string)
+	}
+	if c.Logger == nil {
+		c.Logger = logrus.New()
+		c.Logger.SetOutput(ioutil.Discard)
+	}
+}
+
+// record records a call to the client, returning any failure injected for the operation
+func (c *Client) record(op Operation, options interface{}) error {
+	c.init()
+	c.calls = append(c.calls, Call{Operation: op, Options: options})
+	c.Logger.WithField("operation", op).Debugln("fake client called")
+	return c.failures[op]
+}
+
+// lookup returns a local image by name, ID or short ID
+func (c *Client) lookup(ref string) (*Image, error) {
+	if id, ok := c.names[normalize(ref)]; ok {
+		return c.images[id], nil
+	}
+	if img, ok := c.images[ref]; ok {
+		return img, nil
+	}
+	if hex := strings.TrimPrefix(ref, "sha256:"); len(hex) >= 12 {
+		for id, img := range c.images {
+			if strings.HasPrefix(strings.TrimPrefix(id, "sha256:"), hex) {
+				return img, nil
+			}
+		}
+	}
+	return nil, errors.Errorf("No such image: %s", ref)
+}
+
+// tag tags a local image with a name, moving the name from any image already tagged with it
+func (c *Client) tag(img *Image, name string) {
+	name = normalize(name)
+	if id, ok := c.names[name]; ok {
+		if id == img.ID {
+			return
+		}
+		c.images[id].removeName(name)
+	}
+	c.names[name] = img.ID
+	img.Names = append(img.Names, name)
+	sort.Strings(img.Names)
+}
+
+// baseImage returns a copy of the base image of a build, the image stored locally or the remote image
+func (c *Client) baseImage(name string) *Image {
+	if name == "scratch" {
+		return &Image{}
+	}
+	if img, err := c.lookup(name); err == nil {
+		return img.copy()
+	}
+	return c.remoteImage(normalize(name))
+}
+
+// fromImage returns the image name of a FROM instruction, skipping any flags such as --platform
+func fromImage(step string) string {
+	for _, field := range strings.Fields(step)[1:] {
+		if !strings.HasPrefix(field, "--") {
+			return field
+		}
+	}
+	return "scratch"
+}
+
+// remoteImage returns a copy of an image in the registry, the image pushed to the fake registry or an image
+// with a single layer derived from the image name
+func (c *Client) remoteImage(name string) *Image {
+	if img, ok := c.registry[name]; ok {
+		return img.copy()
+	}
+	layer := digestOf("layer", name)
+	return &Image{
+		ID:      digestOf("image", name),
+		Layers:  []string{layer},
+		Created: time.Unix(0, 0).UTC(),
+		History: []image.HistoryResponseItem{{
+			ID:        digestOf("image", name),
+			CreatedBy: "/bin/sh -c #(nop) ADD file:" + strings.TrimPrefix(layer, "sha256:") + " in / ",
+			Size:      layerSize,
+		}},
+	}
+}
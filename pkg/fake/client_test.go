@@ -0,0 +1,210 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+const dockerfile = `FROM alpine
+# install curl
+RUN apk add \
+  curl
+COPY . /app
+CMD ["/app/run"]
+`
+
+func TestClient_ImageBuild(t *testing.T) {
+	cli := NewClient(nil)
+	res, err := cli.ImageBuild(buildOptions(t, "image-name:v0.1.0", "image-name"))
+	assert.Equal(t, nil, err)
+
+	messages := readMessages(t, res.Body)
+	assert.Equal(t, "Step 1/4 : FROM alpine\n", messages[0].Stream)
+	assert.Equal(t, "Step 2/4 : RUN apk add curl\n", messages[2].Stream)
+	assert.Equal(t, "Successfully tagged image-name:latest\n", messages[len(messages)-1].Stream)
+
+	var result types.BuildResult
+	assert.Equal(t, nil, json.Unmarshal(*messages[8].Aux, &result))
+
+	inspect, err := cli.ImageInspect("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, result.ID, inspect.ID)
+	assert.Equal(t, []string{"image-name:latest", "image-name:v0.1.0"}, inspect.RepoTags)
+	assert.Equal(t, map[string]string{"build": "fake"}, inspect.Config.Labels)
+	assert.Equal(t, 3, len(inspect.RootFS.Layers))
+
+	history, err := cli.ImageHistory(result.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, len(history))
+	assert.Equal(t, result.ID, history[0].ID)
+	assert.Equal(t, `/bin/sh -c #(nop)  CMD ["/app/run"]`, history[0].CreatedBy)
+	assert.Equal(t, "/bin/sh -c apk add curl", history[2].CreatedBy)
+}
+
+func TestClient_ImagePushPull(t *testing.T) {
+	cli := NewClient(nil)
+	_, err := cli.ImageBuild(buildOptions(t, "image-name:v0.1.0"))
+	assert.Equal(t, nil, err)
+	_, err = cli.ImageTag(v1alpha1.OCITagOptions{Source: "image-name:v0.1.0", Target: "registry.io/image-name:v0.1.0"})
+	assert.Equal(t, nil, err)
+
+	res, err := cli.ImagePush(v1alpha1.OCIPushOptions{Ref: "registry.io/image-name:v0.1.0"})
+	assert.Equal(t, nil, err)
+	var pushResult types.PushResult
+	for _, msg := range readMessages(t, res.Body) {
+		if msg.Aux != nil {
+			assert.Equal(t, nil, json.Unmarshal(*msg.Aux, &pushResult))
+		}
+	}
+	assert.Equal(t, "v0.1.0", pushResult.Tag)
+
+	built, err := cli.ImageInspect("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"registry.io/image-name@" + pushResult.Digest}, built.RepoDigests)
+
+	removed, err := cli.ImageRemove(v1alpha1.OCIRemoveOptions{Image: built.ID, ImageRemoveOptions: types.ImageRemoveOptions{Force: true}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, types.ImageDeleteResponseItem{Deleted: built.ID}, removed.Response[2])
+	_, err = cli.ImageInspect("image-name:v0.1.0")
+	assert.Error(t, err)
+
+	_, err = cli.ImagePull(v1alpha1.OCIPullOptions{Ref: "registry.io/image-name:v0.1.0"})
+	assert.Equal(t, nil, err)
+	pulled, err := cli.ImageInspect("registry.io/image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, built.ID, pulled.ID)
+	assert.Equal(t, built.RootFS, pulled.RootFS)
+}
+
+func TestClient_ImagePush_notExist(t *testing.T) {
+	cli := NewClient(nil)
+	_, err := cli.ImagePush(v1alpha1.OCIPushOptions{Ref: "registry.io/image-name:v0.1.0"})
+	assert.Error(t, err)
+}
+
+func TestClient_ImagesPrune(t *testing.T) {
+	cli := NewClient(nil)
+	_, err := cli.ImageBuild(buildOptions(t, "image-name:v0.1.0"))
+	assert.Equal(t, nil, err)
+	// rebuilding moves the name to the new image, leaving the first image dangling
+	_, err = cli.ImageBuild(buildOptions(t, "image-name:v0.1.0"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(cli.Images()))
+
+	res, err := cli.ImagesPrune(v1alpha1.OCIPruneOptions{Labels: []string{"build=other"}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(res.ImagesDeleted))
+
+	res, err = cli.ImagesPrune(v1alpha1.OCIPruneOptions{Labels: []string{"build=fake"}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(res.ImagesDeleted))
+	assert.Equal(t, uint64(3*layerSize), res.SpaceReclaimed)
+	assert.Equal(t, []string{"image-name:v0.1.0"}, cli.Images()[0].Names)
+}
+
+func TestClient_ImageSaveLoad(t *testing.T) {
+	cli := NewClient(nil)
+	_, err := cli.ImageBuild(buildOptions(t, "image-name:v0.1.0"))
+	assert.Equal(t, nil, err)
+
+	_, err = cli.ImageSave(v1alpha1.OCISaveOptions{Image: "image-name:v0.1.0", Dest: "/tmp/image.tar"})
+	assert.Equal(t, nil, err)
+	res, err := cli.ImageLoad(v1alpha1.OCILoadOptions{Input: "/tmp/image.tar"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Loaded image: image-name:v0.1.0\n", readMessages(t, res.Body)[0].Stream)
+
+	_, err = cli.ImageLoad(v1alpha1.OCILoadOptions{Input: "/tmp/other.tar"})
+	assert.Error(t, err)
+}
+
+func TestReadSteps_Gzip(t *testing.T) {
+	options := buildOptions(t)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := io.Copy(gw, options.Context)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, gw.Close())
+
+	steps, err := readSteps(&buf, "Dockerfile")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"FROM alpine", "RUN apk add curl", "COPY . /app", `CMD ["/app/run"]`}, steps)
+}
+
+func TestClient_Fail(t *testing.T) {
+	cli := NewClient(nil)
+	failure := errors.New("push failed")
+	cli.Fail(PushOperation, failure)
+
+	_, err := cli.ImageBuild(buildOptions(t, "image-name:v0.1.0"))
+	assert.Equal(t, nil, err)
+	_, err = cli.ImagePush(v1alpha1.OCIPushOptions{Ref: "image-name:v0.1.0"})
+	assert.Equal(t, failure, err)
+
+	cli.Fail(PushOperation, nil)
+	_, err = cli.ImagePush(v1alpha1.OCIPushOptions{Ref: "image-name:v0.1.0"})
+	assert.Equal(t, nil, err)
+
+	calls := cli.Calls()
+	assert.Equal(t, 3, len(calls))
+	assert.Equal(t, Call{Operation: PushOperation, Options: v1alpha1.OCIPushOptions{Ref: "image-name:v0.1.0"}}, calls[1])
+}
+
+// buildOptions returns build options with a build context of the test Dockerfile
+func buildOptions(t *testing.T, tags ...string) v1alpha1.OCIBuildOptions {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	assert.Equal(t, nil, tw.WriteHeader(&tar.Header{Name: "Dockerfile", Mode: 0644, Size: int64(len(dockerfile))}))
+	_, err := tw.Write([]byte(dockerfile))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, tw.Close())
+
+	return v1alpha1.OCIBuildOptions{
+		Context: &buf,
+		ImageBuildOptions: types.ImageBuildOptions{
+			Dockerfile: "Dockerfile",
+			Tags:       tags,
+			Labels:     map[string]string{"build": "fake"},
+		},
+	}
+}
+
+// readMessages reads the docker json messages of a response body
+func readMessages(t *testing.T, body io.ReadCloser) []jsonmessage.JSONMessage {
+	contents, err := ioutil.ReadAll(body)
+	assert.Equal(t, nil, err)
+	var messages []jsonmessage.JSONMessage
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	for decoder.More() {
+		var msg jsonmessage.JSONMessage
+		assert.Equal(t, nil, decoder.Decode(&msg))
+		messages = append(messages, msg)
+	}
+	return messages
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+// layerSize is the size reported for each layer of a fake image
+const layerSize = 1024
+
+// Image is an image stored by the fake client
+type Image struct {
+	// ID is the image ID in the form sha256:hex
+	ID string
+	// Names are the names the image is tagged with, in the form repository:tag
+	Names []string
+	// Digests are the repository digests of the image, in the form repository@digest
+	Digests []string
+	// Labels are the labels of the image
+	Labels map[string]string
+	// Layers are the diff IDs of the layers of the image
+	Layers []string
+	// History is the history of the image, newest first
+	History []image.HistoryResponseItem
+	// Created is the time the image was created
+	Created time.Time
+}
+
+// Size is the size of the image, the total size of its layers
+func (img Image) Size() int64 {
+	return int64(len(img.Layers)) * layerSize
+}
+
+// copy returns a deep copy of the image
+func (img *Image) copy() *Image {
+	c := *img
+	c.Names = append([]string(nil), img.Names...)
+	c.Digests = append([]string(nil), img.Digests...)
+	c.Layers = append([]string(nil), img.Layers...)
+	c.History = append([]image.HistoryResponseItem(nil), img.History...)
+	if img.Labels != nil {
+		c.Labels = make(map[string]string, len(img.Labels))
+		for k, v := range img.Labels {
+			c.Labels[k] = v
+		}
+	}
+	return &c
+}
+
+// hasLabels returns whether the image has all labels in the form key or key=value
+func (img *Image) hasLabels(labels []string) bool {
+	for _, label := range labels {
+		kv := strings.SplitN(label, "=", 2)
+		value, ok := img.Labels[kv[0]]
+		if !ok || (len(kv) == 2 && value != kv[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeName removes a name from the image, returning whether the image had the name
+func (img *Image) removeName(name string) bool {
+	for i, n := range img.Names {
+		if n == name {
+			img.Names = append(img.Names[:i], img.Names[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// addDigest adds the repository digest of a name of the image
+func (img *Image) addDigest(name string, digest string) {
+	repoDigest := fmt.Sprintf("%s@%s", repository(name), digest)
+	for _, d := range img.Digests {
+		if d == repoDigest {
+			return
+		}
+	}
+	img.Digests = append(img.Digests, repoDigest)
+	sort.Strings(img.Digests)
+}
+
+// normalize adds the latest tag to image names without a tag or digest
+func normalize(name string) string {
+	if strings.Contains(name, "@") || strings.LastIndex(name, ":") > strings.LastIndex(name, "/") {
+		return name
+	}
+	return name + ":latest"
+}
+
+// repository returns the repository of an image name, without its tag or digest
+func repository(name string) string {
+	if i := strings.Index(name, "@"); i >= 0 {
+		return name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i]
+	}
+	return name
+}
+
+// tagOf returns the tag of an image name, or latest if the name has no tag
+func tagOf(name string) string {
+	name = normalize(name)
+	if strings.Contains(name, "@") {
+		return ""
+	}
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// digestOf returns a sha256 digest of the passed in parts
+func digestOf(parts ...string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(strings.Join(parts, "\n"))))
+}
+
+// shortID returns the short form of an image or layer ID used in docker output
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// gzipMagic is the header of gzipped build contexts
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// stream is a stream of docker json messages, as returned by the docker daemon
+type stream []jsonmessage.JSONMessage
+
+// status appends a status message to the stream
+func (s *stream) status(status string, id string) {
+	*s = append(*s, jsonmessage.JSONMessage{Status: status, ID: id})
+}
+
+// output appends a line of output to the stream
+func (s *stream) output(line string) {
+	*s = append(*s, jsonmessage.JSONMessage{Stream: line + "\n"})
+}
+
+// aux appends an auxiliary message to the stream, such as the ID of a built image
+func (s *stream) aux(v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	aux := json.RawMessage(raw)
+	*s = append(*s, jsonmessage.JSONMessage{Aux: &aux})
+	return nil
+}
+
+// body returns the stream as the body of a response
+func (s stream) body() (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range s {
+		if err := encoder.Encode(&s[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ioutil.NopCloser(&buf), nil
+}
+
+// readSteps reads the instructions of a Dockerfile from a tarred build context, which is gzipped or
+// uncompressed like the build contexts accepted by the docker daemon, joining continued lines and skipping
+// comments. No steps are returned if the Dockerfile is not in the build context.
+func readSteps(context io.Reader, dockerfile string) ([]string, error) {
+	if context == nil {
+		return nil, nil
+	}
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	dockerfile = path.Clean(dockerfile)
+
+	br := bufio.NewReader(context)
+	var archive io.Reader = br
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		archive = gz
+	}
+
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Clean(hdr.Name) != dockerfile {
+			continue
+		}
+		return parseSteps(tr)
+	}
+}
+
+// parseSteps parses the instructions of a Dockerfile
+func parseSteps(dockerfile io.Reader) ([]string, error) {
+	var steps []string
+	var step string
+	scanner := bufio.NewScanner(dockerfile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			step += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		steps = append(steps, step+line)
+		step = ""
+	}
+	if step != "" {
+		steps = append(steps, step)
+	}
+	return steps, scanner.Err()
+}
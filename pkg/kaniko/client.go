@@ -0,0 +1,199 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kaniko
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Client is the client used for building with Kaniko using the ocibuilder. Kaniko builds without a daemon or
+// privileges and pushes images to their destinations as part of the build, so images are never stored locally.
+type Client struct {
+	Logger *logrus.Logger
+	// DockerConfigDir is the directory of the docker config Kaniko reads registry auth from,
+	// defaults to the Kaniko docker config directory
+	DockerConfigDir string
+}
+
+// ImageBuild conducts an image build with Kaniko using the ocibuilder, pushing the built image to every
+// destination. Images are not stored locally, so an image without destinations is built without pushing.
+func (cli Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
+
+	buildFlags := []command.Flag{
+		{Name: "context", Value: options.ContextPath, Short: false, OmitEmpty: true},
+		{Name: "dockerfile", Value: options.Dockerfile, Short: false, OmitEmpty: true},
+	}
+
+	for _, destination := range options.Destinations {
+		buildFlags = append(buildFlags, command.Flag{Name: "destination", Value: destination, Short: false, OmitEmpty: true})
+	}
+	if len(options.Destinations) == 0 {
+		buildFlags = append(buildFlags, command.Flag{Name: "no-push", Value: "", Short: false, OmitEmpty: false})
+	}
+
+	if !options.NoCache {
+		buildFlags = append(buildFlags,
+			command.Flag{Name: "cache", Value: "", Short: false, OmitEmpty: false},
+			command.Flag{Name: "cache-repo", Value: options.CacheRepo, Short: false, OmitEmpty: true},
+		)
+	}
+
+	var labelKeys []string
+	for k := range options.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		buildFlags = append(buildFlags, command.Flag{Name: "label", Value: fmt.Sprintf("%s=%s", k, options.Labels[k]), Short: false, OmitEmpty: true})
+	}
+
+	var buildArgKeys []string
+	for k := range options.BuildArgs {
+		buildArgKeys = append(buildArgKeys, k)
+	}
+	sort.Strings(buildArgKeys)
+	for _, k := range buildArgKeys {
+		buildArg := k
+		if v := options.BuildArgs[k]; v != nil {
+			buildArg = fmt.Sprintf("%s=%s", k, *v)
+		}
+		// build args often hold secrets such as tokens, so are redacted from logs
+		buildFlags = append(buildFlags, command.Flag{Name: "build-arg", Value: buildArg, Short: false, OmitEmpty: true, Sensitive: true})
+	}
+
+	cmd := command.Builder(common.KanikoExecutor).Flags(buildFlags...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing build with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error building image...")
+		return v1alpha1.OCIBuildResponse{}, err
+	}
+	return v1alpha1.OCIBuildResponse{
+		ImageBuildResponse: types.ImageBuildResponse{
+			Body: stdout,
+		},
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImagePull is not supported by Kaniko, base images are pulled as part of the build
+func (cli Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullResponse, error) {
+	return v1alpha1.OCIPullResponse{}, unsupported("image pull")
+}
+
+// ImagePush is not supported by Kaniko, images are pushed to their destinations as part of the build
+func (cli Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
+	return v1alpha1.OCIPushResponse{}, unsupported("image push")
+}
+
+// ImageRemove is a no-op with Kaniko as built images are not stored locally
+func (cli Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
+	cli.Logger.WithField("image", options.Image).Debugln("kaniko does not store images locally, nothing to remove")
+	return v1alpha1.OCIRemoveResponse{}, nil
+}
+
+// ImagesPrune is a no-op with Kaniko as built images are not stored locally
+func (cli Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	cli.Logger.WithField("labels", options.Labels).Debugln("kaniko does not store images locally, nothing to prune")
+	return v1alpha1.OCIPruneResponse{}, nil
+}
+
+// ImageTag is not supported by Kaniko, tags are pushed as destinations of the build
+func (cli Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	return v1alpha1.OCITagResponse{}, unsupported("image tag")
+}
+
+// ImageInspect is not supported by Kaniko as built images are not stored locally
+func (cli Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+	return types.ImageInspect{}, unsupported("image inspect")
+}
+
+// ImageHistory is not supported by Kaniko as built images are not stored locally
+func (cli Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
+	return nil, unsupported("image history")
+}
+
+// ImageSave is not supported by Kaniko as built images are not stored locally
+func (cli Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	return v1alpha1.OCISaveResponse{}, unsupported("image export")
+}
+
+// ImageLoad is not supported by Kaniko as images are not stored locally
+func (cli Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	return v1alpha1.OCILoadResponse{}, unsupported("image load")
+}
+
+// ContainerRun is not supported by Kaniko as it cannot run containers
+func (cli Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	return v1alpha1.OCIRunResponse{}, unsupported("container run")
+}
+
+// RegistryLogin writes the registry auth to the docker config Kaniko reads from, keeping the auth of any
+// other registries already in the config
+func (cli Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
+	configDir := cli.DockerConfigDir
+	if configDir == "" {
+		configDir = common.KanikoDockerConfigDir
+	}
+	cli.Logger.WithField("path", configDir).Debugln("writing registry auth to kaniko docker config")
+
+	if err := util.WriteDockerConfigAuth(configDir, options.AuthConfig); err != nil {
+		cli.Logger.WithError(err).Errorln("error writing kaniko docker config...")
+		return v1alpha1.OCILoginResponse{}, err
+	}
+
+	return v1alpha1.OCILoginResponse{
+		AuthenticateOKBody: registry.AuthenticateOKBody{
+			Status: "login completed",
+		},
+	}, nil
+}
+
+// GenerateAuthRegistryString generates the auth of a registry in the docker config format of base64 encoded
+// username:password
+func (cli Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
+	return util.EncodeDockerConfigAuth(auth)
+}
+
+// Capabilities returns the capabilities of Kaniko, which neither stores built images locally nor writes exports
+func (cli Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{}
+}
+
+// unsupported returns the error for an operation Kaniko cannot conduct
+func unsupported(operation string) error {
+	return errors.Errorf("%s is not supported by the kaniko builder", operation)
+}
+
+// execute executes the kaniko executor command. This function is mocked in kaniko client tests.
+var execute = func(cmd *command.Command) (stdout io.ReadCloser, stderr io.ReadCloser, err error) {
+	return cmd.Exec()
+}
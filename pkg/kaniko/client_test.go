@@ -0,0 +1,148 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kaniko
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ImageBuild(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedBuildCommand, cmd)
+		return nil, nil, nil
+	}
+	_, err := cli.ImageBuild(ociBuildOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageBuild_NoDestination(t *testing.T) {
+	options := ociBuildOptions
+	options.Destinations = nil
+	options.NoCache = true
+	options.Labels = nil
+	options.BuildArgs = nil
+
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedNoPushBuildCommand, cmd)
+		return nil, nil, nil
+	}
+	_, err := cli.ImageBuild(options)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImagePush(t *testing.T) {
+	_, err := cli.ImagePush(v1alpha1.OCIPushOptions{Ref: "image-name"})
+	assert.Error(t, err)
+}
+
+func TestClient_ImageRemove(t *testing.T) {
+	_, err := cli.ImageRemove(v1alpha1.OCIRemoveOptions{Image: "image-name"})
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_RegistryLogin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-kaniko")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	configPath := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(configPath, []byte(`{"auths":{"other-registry":{"auth":"b3RoZXI6YXV0aA=="}},"credsStore":"ecr-login"}`), 0600)
+	assert.Equal(t, nil, err)
+
+	loginCli := Client{
+		Logger:          util.GetLogger(true),
+		DockerConfigDir: dir,
+	}
+	_, err = loginCli.RegistryLogin(ociLoginOptions)
+	assert.Equal(t, nil, err)
+
+	contents, err := ioutil.ReadFile(configPath)
+	assert.Equal(t, nil, err)
+	var config struct {
+		Auths      map[string]types.AuthConfig `json:"auths"`
+		CredsStore string                      `json:"credsStore"`
+	}
+	assert.Equal(t, nil, json.Unmarshal(contents, &config))
+	assert.Equal(t, "dXNlcjpwYXNz", config.Auths["arts-test-registry"].Auth)
+	assert.Equal(t, "b3RoZXI6YXV0aA==", config.Auths["other-registry"].Auth)
+	assert.Equal(t, "ecr-login", config.CredsStore)
+}
+
+func TestClient_GenerateAuthRegistryString(t *testing.T) {
+	authString := cli.GenerateAuthRegistryString(authConfig)
+	assert.Equal(t, "dXNlcjpwYXNz", authString)
+}
+
+var cli = Client{
+	Logger: util.GetLogger(true),
+}
+
+var buildArg = "1.13"
+
+var ociBuildOptions = v1alpha1.OCIBuildOptions{
+	Ctx:          context.Background(),
+	ContextPath:  "/ocib/context/",
+	CacheRepo:    "example-registry/image-name/cache",
+	Destinations: []string{"example-registry/image-name:v0.1.0", "example-registry/image-name:latest"},
+	ImageBuildOptions: types.ImageBuildOptions{
+		Dockerfile: "Dockerfile",
+		Tags:       []string{"image-name:v0.1.0", "image-name:latest"},
+		Labels:     map[string]string{"team": "builds", "build-id": "1234"},
+		BuildArgs:  map[string]*string{"GO_VERSION": &buildArg},
+	},
+}
+
+var expectedBuildCommand = command.Builder("/kaniko/executor").Flags([]command.Flag{
+	{Name: "context", Value: "/ocib/context/", Short: false, OmitEmpty: true},
+	{Name: "dockerfile", Value: "Dockerfile", Short: false, OmitEmpty: true},
+	{Name: "destination", Value: "example-registry/image-name:v0.1.0", Short: false, OmitEmpty: true},
+	{Name: "destination", Value: "example-registry/image-name:latest", Short: false, OmitEmpty: true},
+	{Name: "cache", Value: "", Short: false, OmitEmpty: false},
+	{Name: "cache-repo", Value: "example-registry/image-name/cache", Short: false, OmitEmpty: true},
+	{Name: "label", Value: "build-id=1234", Short: false, OmitEmpty: true},
+	{Name: "label", Value: "team=builds", Short: false, OmitEmpty: true},
+	{Name: "build-arg", Value: "GO_VERSION=1.13", Short: false, OmitEmpty: true, Sensitive: true},
+}...).Build()
+
+var expectedNoPushBuildCommand = command.Builder("/kaniko/executor").Flags([]command.Flag{
+	{Name: "context", Value: "/ocib/context/", Short: false, OmitEmpty: true},
+	{Name: "dockerfile", Value: "Dockerfile", Short: false, OmitEmpty: true},
+	{Name: "no-push", Value: "", Short: false, OmitEmpty: false},
+}...).Build()
+
+var ociLoginOptions = v1alpha1.OCILoginOptions{
+	Ctx:        context.Background(),
+	AuthConfig: authConfig,
+}
+
+var authConfig = types.AuthConfig{
+	Username:      "user",
+	Password:      "pass",
+	ServerAddress: "arts-test-registry",
+}
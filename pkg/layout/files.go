@@ -0,0 +1,132 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package layout
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// whiteoutPrefix marks a file deleted from the layers below
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaque marks a directory whose contents in the layers below are hidden
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// Files returns every path in the filesystem of the first image in an extracted docker-archive, applying the
+// whiteouts of each layer in order. Paths are absolute, e.g. /etc/passwd.
+func Files(src string) (map[string]bool, error) {
+	manifestBytes, err := ioutil.ReadFile(filepath.Join(src, dockerManifestFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s of the docker-archive", dockerManifestFile)
+	}
+	var manifests []dockerManifest
+	if err := json.Unmarshal(manifestBytes, &manifests); err != nil {
+		return nil, err
+	}
+	if len(manifests) == 0 {
+		return nil, errors.New("no images found in the docker-archive")
+	}
+
+	files := make(map[string]bool)
+	for _, layer := range manifests[0].Layers {
+		if err := applyLayer(filepath.Join(src, layer), files); err != nil {
+			return nil, errors.Wrapf(err, "failed to read layer %s", layer)
+		}
+	}
+	return files, nil
+}
+
+// applyLayer adds the paths of a layer tarball to files, first removing the paths whited out by the layer
+func applyLayer(layerPath string, files map[string]bool) error {
+	f, err := os.Open(layerPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := decompress(f)
+	if err != nil {
+		return err
+	}
+
+	var added, removed, opaque []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean("/" + hdr.Name)
+		dir, base := path.Split(name)
+		switch {
+		case base == whiteoutOpaque:
+			opaque = append(opaque, path.Clean(dir))
+		case strings.HasPrefix(base, whiteoutPrefix):
+			removed = append(removed, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+		default:
+			added = append(added, name)
+		}
+	}
+
+	for _, dir := range opaque {
+		for file := range files {
+			if strings.HasPrefix(file, dir+"/") {
+				delete(files, file)
+			}
+		}
+	}
+	for _, name := range removed {
+		for file := range files {
+			if file == name || strings.HasPrefix(file, name+"/") {
+				delete(files, file)
+			}
+		}
+	}
+	for _, name := range added {
+		files[name] = true
+	}
+	return nil
+}
+
+// decompress returns a reader of the uncompressed contents of a layer, which may or may not be gzipped
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package layout
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	lowerLayer := tarFiles(t, map[string]string{
+		"etc/passwd":   "root",
+		"usr/bin/app":  "app",
+		"./tmp/a":      "a",
+		"tmp/nested/c": "c",
+	})
+	upperLayer := &bytes.Buffer{}
+	gw := gzip.NewWriter(upperLayer)
+	_, err = gw.Write(tarFiles(t, map[string]string{
+		"etc/.wh.passwd":   "",
+		"tmp/.wh..wh..opq": "",
+		"tmp/b":            "b",
+	}))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, gw.Close())
+
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "lower.tar"), lowerLayer, 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "upper.tar"), upperLayer.Bytes(), 0644))
+	manifest := `[{"Config":"config.json","RepoTags":["image-name:v0.1.0"],"Layers":["lower.tar","upper.tar"]}]`
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, dockerManifestFile), []byte(manifest), 0644))
+
+	files, err := Files(dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]bool{"/usr/bin/app": true, "/tmp/b": true}, files)
+}
+
+func TestFiles_NoManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	_, err = Files(dir)
+	assert.Error(t, err)
+}
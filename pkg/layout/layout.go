@@ -0,0 +1,405 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package layout
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// dockerManifestFile is the name of the manifest file written into a docker-archive by docker save
+const dockerManifestFile = "manifest.json"
+
+// dockerManifest is a single image entry in the manifest.json of a docker-archive
+type dockerManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// blob is a file from a docker-archive which has been written to the blobs directory of an OCI image layout
+type blob struct {
+	digest digest.Digest
+	size   int64
+}
+
+// FromDockerArchive converts a docker-archive tar stream, as produced by docker save, into an OCI image
+// layout directory at dest. Every repo tag in the archive is added to the layout index as a ref name.
+func FromDockerArchive(archive io.Reader, dest string) error {
+	blobsDir := filepath.Join(dest, "blobs", string(digest.Canonical))
+	if err := os.MkdirAll(blobsDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create OCI image layout at %s", dest)
+	}
+
+	var manifests []dockerManifest
+	blobs := make(map[string]blob)
+
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read docker archive")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.Clean(hdr.Name)
+		if name == dockerManifestFile {
+			if err := json.NewDecoder(tr).Decode(&manifests); err != nil {
+				return errors.Wrap(err, "failed to decode docker archive manifest")
+			}
+			continue
+		}
+		b, err := writeBlob(blobsDir, tr)
+		if err != nil {
+			return err
+		}
+		blobs[name] = b
+	}
+
+	if len(manifests) == 0 {
+		return errors.New("docker archive does not contain any images")
+	}
+
+	referenced := make(map[digest.Digest]bool)
+	index := ispec.Index{Versioned: specs.Versioned{SchemaVersion: 2}}
+
+	for _, m := range manifests {
+		config, ok := blobs[filepath.Clean(m.Config)]
+		if !ok {
+			return errors.Errorf("config %s not found in docker archive", m.Config)
+		}
+		referenced[config.digest] = true
+
+		manifest := ispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			Config: ispec.Descriptor{
+				MediaType: ispec.MediaTypeImageConfig,
+				Digest:    config.digest,
+				Size:      config.size,
+			},
+		}
+		for _, l := range m.Layers {
+			layer, ok := blobs[filepath.Clean(l)]
+			if !ok {
+				return errors.Errorf("layer %s not found in docker archive", l)
+			}
+			referenced[layer.digest] = true
+			manifest.Layers = append(manifest.Layers, ispec.Descriptor{
+				MediaType: ispec.MediaTypeImageLayer,
+				Digest:    layer.digest,
+				Size:      layer.size,
+			})
+		}
+
+		manifestBytes, err := json.Marshal(manifest)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal image manifest")
+		}
+		manifestDigest := digest.FromBytes(manifestBytes)
+		if err := ioutil.WriteFile(filepath.Join(blobsDir, manifestDigest.Hex()), manifestBytes, 0644); err != nil {
+			return errors.Wrap(err, "failed to write image manifest")
+		}
+		referenced[manifestDigest] = true
+
+		descriptor := ispec.Descriptor{
+			MediaType: ispec.MediaTypeImageManifest,
+			Digest:    manifestDigest,
+			Size:      int64(len(manifestBytes)),
+		}
+		if len(m.RepoTags) == 0 {
+			index.Manifests = append(index.Manifests, descriptor)
+		}
+		for _, repoTag := range m.RepoTags {
+			tagged := descriptor
+			tagged.Annotations = map[string]string{ispec.AnnotationRefName: repoTag}
+			index.Manifests = append(index.Manifests, tagged)
+		}
+	}
+
+	// the remaining files in a docker-archive (legacy layer json, VERSION etc.) are not part of the layout
+	for _, b := range blobs {
+		if !referenced[b.digest] {
+			if err := os.Remove(filepath.Join(blobsDir, b.digest.Hex())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "failed to remove unreferenced blob")
+			}
+		}
+	}
+
+	indexBytes, err := json.Marshal(index)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal image index")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dest, "index.json"), indexBytes, 0644); err != nil {
+		return errors.Wrap(err, "failed to write image index")
+	}
+
+	layoutBytes, err := json.Marshal(ispec.ImageLayout{Version: ispec.ImageLayoutVersion})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal image layout")
+	}
+	return errors.Wrap(ioutil.WriteFile(filepath.Join(dest, ispec.ImageLayoutFile), layoutBytes, 0644), "failed to write image layout")
+}
+
+// ToDockerArchive writes the OCI image layout directory at src to w as a docker-archive which can be loaded
+// by docker load. Ref name annotations in the layout index are used as the repo tags of each image.
+func ToDockerArchive(src string, w io.Writer) error {
+	indexBytes, err := ioutil.ReadFile(filepath.Join(src, "index.json"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read image index of OCI image layout %s", src)
+	}
+	var index ispec.Index
+	if err := json.Unmarshal(indexBytes, &index); err != nil {
+		return errors.Wrap(err, "failed to decode image index")
+	}
+
+	tw := tar.NewWriter(w)
+	written := make(map[digest.Digest]bool)
+	var manifests []dockerManifest
+
+	for _, descriptor := range index.Manifests {
+		if descriptor.MediaType != ispec.MediaTypeImageManifest {
+			return errors.Errorf("unsupported media type %s in image index", descriptor.MediaType)
+		}
+		manifestBytes, err := ioutil.ReadFile(blobPath(src, descriptor.Digest))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read image manifest %s", descriptor.Digest)
+		}
+		var manifest ispec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return errors.Wrap(err, "failed to decode image manifest")
+		}
+
+		dm := dockerManifest{Config: manifest.Config.Digest.Hex() + ".json"}
+		if err := writeTarBlob(tw, src, manifest.Config.Digest, dm.Config, written); err != nil {
+			return err
+		}
+		for _, l := range manifest.Layers {
+			// docker load decompresses layers itself, so compressed layers can be written as they are
+			name := l.Digest.Hex() + "/layer.tar"
+			if err := writeTarBlob(tw, src, l.Digest, name, written); err != nil {
+				return err
+			}
+			dm.Layers = append(dm.Layers, name)
+		}
+		// docker repo tags must be a full reference, a layout ref name can also be a bare tag
+		if ref := descriptor.Annotations[ispec.AnnotationRefName]; strings.Contains(ref, ":") {
+			dm.RepoTags = []string{ref}
+		}
+		manifests = append(manifests, dm)
+	}
+
+	manifestBytes, err := json.Marshal(manifests)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal docker archive manifest")
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: dockerManifestFile, Mode: 0644, Size: int64(len(manifestBytes)), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Extract extracts the tar stream r into the directory at dest
+func Extract(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read archive")
+		}
+		name := filepath.Clean(hdr.Name)
+		if filepath.IsAbs(name) || strings.HasPrefix(name, "..") {
+			return errors.Errorf("invalid path %s in archive", hdr.Name)
+		}
+		path := filepath.Join(dest, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// DetectFormat detects the format of an exported image at path. A directory must be an OCI image layout,
+// while an archive is a docker-archive if it contains a docker manifest and an oci-archive otherwise.
+func DetectFormat(path string) (v1alpha1.ExportFormat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		if _, err := os.Stat(filepath.Join(path, ispec.ImageLayoutFile)); err != nil {
+			return "", errors.Errorf("directory %s is not an OCI image layout", path)
+		}
+		return v1alpha1.OCIExportFormat, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	isLayout := false
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "%s is not an image archive", path)
+		}
+		switch filepath.Clean(hdr.Name) {
+		case dockerManifestFile:
+			return v1alpha1.DockerArchiveExportFormat, nil
+		case ispec.ImageLayoutFile:
+			isLayout = true
+		}
+	}
+	if isLayout {
+		return v1alpha1.OCIArchiveExportFormat, nil
+	}
+	return "", errors.Errorf("unable to detect image format of %s", path)
+}
+
+// Archive writes the contents of the directory at src to w as a tar stream, with paths relative to src.
+// Archiving an OCI image layout directory produces an oci-archive.
+func Archive(src string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to archive %s", src)
+	}
+	return tw.Close()
+}
+
+// writeBlob writes r into the blobs directory, named by the digest of its content
+func writeBlob(blobsDir string, r io.Reader) (blob, error) {
+	tmp, err := ioutil.TempFile(blobsDir, ".blob-")
+	if err != nil {
+		return blob{}, errors.Wrap(err, "failed to create blob")
+	}
+	defer os.Remove(tmp.Name())
+
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(tmp, io.TeeReader(r, digester.Hash()))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return blob{}, errors.Wrap(err, "failed to write blob")
+	}
+
+	b := blob{digest: digester.Digest(), size: size}
+	if err := os.Rename(tmp.Name(), filepath.Join(blobsDir, b.digest.Hex())); err != nil {
+		return blob{}, errors.Wrap(err, "failed to write blob")
+	}
+	return b, nil
+}
+
+// writeTarBlob writes a blob from the layout at src into the tar stream under name, skipping blobs already written
+func writeTarBlob(tw *tar.Writer, src string, d digest.Digest, name string, written map[digest.Digest]bool) error {
+	if written[d] {
+		return nil
+	}
+	f, err := os.Open(blobPath(src, d))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read blob %s", d)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: info.Size(), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return errors.Wrapf(err, "failed to write blob %s", d)
+	}
+	written[d] = true
+	return nil
+}
+
+// blobPath returns the path of a blob within the OCI image layout at src
+func blobPath(src string, d digest.Digest) string {
+	return filepath.Join(src, "blobs", string(d.Algorithm()), d.Hex())
+}
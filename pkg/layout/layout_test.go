@@ -0,0 +1,188 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package layout
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/opencontainers/go-digest"
+	ispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromDockerArchive(t *testing.T) {
+	dest, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dest)
+
+	err = FromDockerArchive(bytes.NewReader(dockerArchive(t)), dest)
+	assert.Equal(t, nil, err)
+
+	indexBytes, err := ioutil.ReadFile(filepath.Join(dest, "index.json"))
+	assert.Equal(t, nil, err)
+	var index ispec.Index
+	assert.Equal(t, nil, json.Unmarshal(indexBytes, &index))
+	assert.Equal(t, 1, len(index.Manifests))
+	assert.Equal(t, "image-name:v0.1.0", index.Manifests[0].Annotations[ispec.AnnotationRefName])
+
+	manifestBytes, err := ioutil.ReadFile(filepath.Join(dest, "blobs", "sha256", index.Manifests[0].Digest.Hex()))
+	assert.Equal(t, nil, err)
+	var manifest ispec.Manifest
+	assert.Equal(t, nil, json.Unmarshal(manifestBytes, &manifest))
+	assert.Equal(t, digest.FromBytes([]byte(config)), manifest.Config.Digest)
+	assert.Equal(t, 1, len(manifest.Layers))
+	assert.Equal(t, digest.FromBytes([]byte(layer)), manifest.Layers[0].Digest)
+
+	blobs, err := ioutil.ReadDir(filepath.Join(dest, "blobs", "sha256"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(blobs), "expecting only the config, layer and manifest blobs")
+
+	_, err = os.Stat(filepath.Join(dest, ispec.ImageLayoutFile))
+	assert.Equal(t, nil, err)
+}
+
+func TestFromDockerArchive_NoManifest(t *testing.T) {
+	dest, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dest)
+
+	err = FromDockerArchive(bytes.NewReader(tarFiles(t, map[string]string{"VERSION": "1.0"})), dest)
+	assert.Error(t, err)
+}
+
+func TestToDockerArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, nil, FromDockerArchive(bytes.NewReader(dockerArchive(t)), dir))
+
+	buf := &bytes.Buffer{}
+	assert.Equal(t, nil, ToDockerArchive(dir, buf))
+
+	files := make(map[string]string)
+	tr := tar.NewReader(buf)
+	for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+		contents, err := ioutil.ReadAll(tr)
+		assert.Equal(t, nil, err)
+		files[hdr.Name] = string(contents)
+	}
+
+	var manifests []dockerManifest
+	assert.Equal(t, nil, json.Unmarshal([]byte(files["manifest.json"]), &manifests))
+	assert.Equal(t, 1, len(manifests))
+	assert.Equal(t, []string{"image-name:v0.1.0"}, manifests[0].RepoTags)
+	assert.Equal(t, config, files[manifests[0].Config])
+	assert.Equal(t, layer, files[manifests[0].Layers[0]])
+}
+
+func TestExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	err = Extract(bytes.NewReader(tarFiles(t, map[string]string{"blobs/sha256/abc": "blob"})), dir)
+	assert.Equal(t, nil, err)
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "blobs", "sha256", "abc"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "blob", string(contents))
+
+	err = Extract(bytes.NewReader(tarFiles(t, map[string]string{"../escape": "blob"})), dir)
+	assert.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	layoutDir := filepath.Join(dir, "layout")
+	assert.Equal(t, nil, FromDockerArchive(bytes.NewReader(dockerArchive(t)), layoutDir))
+	format, err := DetectFormat(layoutDir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, v1alpha1.OCIExportFormat, format)
+
+	ociArchive := &bytes.Buffer{}
+	assert.Equal(t, nil, Archive(layoutDir, ociArchive))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "oci.tar"), ociArchive.Bytes(), 0644))
+	format, err = DetectFormat(filepath.Join(dir, "oci.tar"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, v1alpha1.OCIArchiveExportFormat, format)
+
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "docker.tar"), dockerArchive(t), 0644))
+	format, err = DetectFormat(filepath.Join(dir, "docker.tar"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, v1alpha1.DockerArchiveExportFormat, format)
+
+	_, err = DetectFormat(dir)
+	assert.Error(t, err)
+}
+
+func TestArchive(t *testing.T) {
+	src, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(src)
+
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(src, "blobs", "sha256"), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(src, "index.json"), []byte("{}"), 0644))
+
+	buf := &bytes.Buffer{}
+	assert.Equal(t, nil, Archive(src, buf))
+
+	var names []string
+	tr := tar.NewReader(buf)
+	for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+		names = append(names, hdr.Name)
+	}
+	assert.Equal(t, []string{"blobs", "blobs/sha256", "index.json"}, names)
+}
+
+const config = `{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`
+
+const layer = "layer contents"
+
+func dockerArchive(t *testing.T) []byte {
+	configName := digest.FromBytes([]byte(config)).Hex() + ".json"
+	return tarFiles(t, map[string]string{
+		configName:      config,
+		"abc/layer.tar": layer,
+		"abc/json":      "{}",
+		"abc/VERSION":   "1.0",
+		"manifest.json": `[{"Config":"` + configName + `","RepoTags":["image-name:v0.1.0"],"Layers":["abc/layer.tar"]}]`,
+		"repositories":  `{"image-name":{"v0.1.0":"abc"}}`,
+	})
+}
+
+func tarFiles(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, contents := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+		assert.Equal(t, nil, err)
+		_, err = tw.Write([]byte(contents))
+		assert.Equal(t, nil, err)
+	}
+	assert.Equal(t, nil, tw.Close())
+	return buf.Bytes()
+}
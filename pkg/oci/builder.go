@@ -17,16 +17,27 @@ limitations under the License.
 package oci
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/common"
+	"github.com/ocibuilder/ocibuilder/pkg/layout"
 	"github.com/ocibuilder/ocibuilder/pkg/parser"
+	"github.com/ocibuilder/ocibuilder/pkg/tag"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
 	"github.com/ocibuilder/ocibuilder/pkg/validate"
 	"github.com/sirupsen/logrus"
 )
@@ -35,6 +46,12 @@ type Builder struct {
 	Logger     *logrus.Logger
 	Client     v1alpha1.BuilderClient
 	Provenance []*v1alpha1.BuildProvenance
+	// PushResults are the results of each image pushed by the builder
+	PushResults []v1alpha1.PushResult
+	// PushParallelism is the maximum number of images pushed concurrently, defaults to common.DefaultPushParallelism
+	PushParallelism int
+	// TagRecordPath is the path of the record of the tags rendered at build time, defaults to common.TagRecordPath
+	TagRecordPath string
 }
 
 func (b *Builder) Build(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIBuildResponse, errChan chan<- error, finished chan<- bool) {
@@ -50,6 +67,30 @@ func (b *Builder) Build(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIBuild
 	if err != nil {
 		log.WithError(err).Errorln("error in parsing build spec")
 		errChan <- err
+		return
+	}
+
+	capabilities := cli.Capabilities()
+	if err := validateCapabilities(spec, buildOpts, capabilities); err != nil {
+		log.WithError(err).Errorln("build spec is not supported by the builder")
+		errChan <- err
+		return
+	}
+
+	record := tag.NewRecord()
+
+	if err := b.Load(spec.Build.Preload); err != nil {
+		log.WithError(err).Errorln("unable to preload images")
+		errChan <- err
+		return
+	}
+
+	if spec.Build.PullBaseImages {
+		if err := b.PullBaseImages(spec); err != nil {
+			log.WithError(err).Errorln("unable to pull base images")
+			errChan <- err
+			return
+		}
 	}
 
 	for idx, opt := range buildOpts {
@@ -73,6 +114,27 @@ func (b *Builder) Build(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIBuild
 		}
 
 		imageName := fmt.Sprintf("%s:%s", opt.Name, opt.Tag)
+		imageNames := []string{imageName}
+		for _, t := range opt.Tags {
+			if name := fmt.Sprintf("%s:%s", opt.Name, t); name != imageName {
+				imageNames = append(imageNames, name)
+			}
+		}
+
+		// push specs are rendered with the variables of the build step, as they can differ at push time
+		vars, err := parser.TagVariables(spec.Build.Steps[idx])
+		if err != nil {
+			log.WithError(err).Errorln("unable to read tag template variables")
+			errChan <- err
+			return
+		}
+
+		destinations, err := pushDestinations(spec.Push, opt.Name, opt.Tags, vars)
+		if err != nil {
+			log.WithError(err).Errorln("unable to render push destinations")
+			errChan <- err
+			return
+		}
 
 		builderOptions := v1alpha1.OCIBuildOptions{
 			Ctx:         context.Background(),
@@ -80,12 +142,15 @@ func (b *Builder) Build(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIBuild
 			Context:     buildContext,
 			ImageBuildOptions: types.ImageBuildOptions{
 				Dockerfile: opt.Dockerfile,
-				Tags:       []string{imageName},
+				Tags:       imageNames,
 				Context:    buildContext,
 				Labels:     opt.Labels,
 				NoCache:    !opt.Cache,
 			},
 			StorageDriver: opt.StorageDriver,
+			CacheRepo:     opt.CacheRepo,
+			Exports:       opt.Export,
+			Destinations:  destinations,
 		}
 
 		buildProvenance.StartTime = time.Now()
@@ -108,6 +173,12 @@ func (b *Builder) Build(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIBuild
 		<-res
 		buildProvenance.EndTime = time.Now()
 
+		if err := b.Test(imageName, opt.Test); err != nil {
+			log.WithError(err).Errorln("image failed structure tests")
+			errChan <- err
+			return
+		}
+
 		if spec.Metadata != nil && spec.Metadata.StoreConfig != nil {
 			log.Debugln("metadata specification present")
 			mw := NewMetadataWriter(log, spec.Metadata)
@@ -123,30 +194,88 @@ func (b *Builder) Build(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIBuild
 
 		}
 
-		if opt.Purge {
-			if err := b.Purge(imageName); err != nil {
-				log.WithError(err).Errorln("unable to complete image purge")
+		// builders which do not store images locally write exports as outputs of the build
+		if capabilities.LocalImages {
+			if err := b.Export(imageName, opt.Export); err != nil {
+				log.WithError(err).Errorln("unable to complete image export")
 				errChan <- err
 				return
 			}
 		}
+
+		if opt.Purge {
+			for _, name := range imageNames {
+				if err := b.Purge(name); err != nil {
+					log.WithError(err).Errorln("unable to complete image purge")
+					errChan <- err
+					return
+				}
+			}
+			if opt.BuildID != "" {
+				if err := b.Prune(opt.BuildID); err != nil {
+					log.WithError(err).Errorln("unable to prune intermediate images")
+					errChan <- err
+					return
+				}
+			}
+		}
+		record.StepVariables[opt.Name] = vars
+		record.Steps[opt.Name] = opt.Tags
+		if err := record.Write(b.tagRecordPath()); err != nil {
+			log.WithError(err).Errorln("unable to record the tags of the build")
+			errChan <- err
+			return
+		}
+
 		b.Provenance = append(b.Provenance, buildProvenance)
 		log.WithField("step", idx).Debugln("build step has finished excuting")
 	}
 }
 
+// Push pushes the images in each push spec, pushing up to PushParallelism images concurrently.
+// Push output is buffered and sent on the response channel in push spec order once all pushes have completed.
 func (b *Builder) Push(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIPushResponse, errChan chan<- error, finished chan<- bool) {
 	log := b.Logger
-	cli := b.Client
 
+	record, err := tag.ReadRecord(b.tagRecordPath())
+	if err != nil {
+		log.WithError(err).Errorln("unable to read the tags of the build")
+		errChan <- err
+		return
+	}
+	// build steps are pushed with tags rendered with the variables recorded when they were built, images which
+	// were not built by ocictl with tags rendered once at push time
+	var pushVars *tag.Variables
+	variables := func(step string) (tag.Variables, error) {
+		if record != nil {
+			if vars, ok := record.StepVariables[step]; ok {
+				return vars, nil
+			}
+		}
+		if pushVars == nil {
+			vars, err := tag.NewVariables(common.RemoteLocalDirectory, nil)
+			if err != nil {
+				return tag.Variables{}, err
+			}
+			pushVars = &vars
+		}
+		return *pushVars, nil
+	}
+
+	var targets []pushTarget
 	for idx, pushSpec := range spec.Push {
 		log.WithField("step: ", idx).Debugln("running push step")
-		if err := validate.ValidatePushSpec(&pushSpec); err != nil {
+		source, err := resolvePushSpec(&pushSpec, record)
+		if err != nil {
+			log.WithError(err).Errorln("unable to resolve build step to push")
 			errChan <- err
+			return
 		}
 
-		pushFullImageName := fmt.Sprintf("%s/%s:%s", pushSpec.Registry, pushSpec.Image, pushSpec.Tag)
-		log.WithField("name", pushFullImageName).Infoln("pushing image with name")
+		if err := validate.ValidatePushSpec(&pushSpec); err != nil {
+			errChan <- err
+			return
+		}
 
 		authString, err := b.generateAuthRegistryString(pushSpec.Registry, spec)
 		if err != nil {
@@ -155,74 +284,296 @@ func (b *Builder) Push(spec v1alpha1.OCIBuilderSpec, res chan v1alpha1.OCIPushRe
 			return
 		}
 
-		pushOptions := v1alpha1.OCIPushOptions{
-			Ctx: context.Background(),
-			Ref: pushFullImageName,
-			ImagePushOptions: types.ImagePushOptions{
-				RegistryAuth: authString,
-			},
+		vars, err := variables(pushSpec.From)
+		if err != nil {
+			log.WithError(err).Errorln("unable to read tag template variables")
+			errChan <- err
+			return
 		}
 
-		pushResponse, err := cli.ImagePush(pushOptions)
+		tags, err := tag.RenderAll(pushSpec.Tag, pushSpec.Tags, vars)
 		if err != nil {
-			log.WithError(err).Debugln("failed to push image")
+			log.WithError(err).Errorln("unable to render push tags")
 			errChan <- err
 			return
 		}
 
-		res <- pushResponse
-		if pushResponse.Exec != nil {
-			log.Debugln("executing wait on push response")
-			if err := pushResponse.Exec.Wait(); err != nil {
-				errChan <- err
-				return
+		for _, pushTag := range tags {
+			targets = append(targets, pushTarget{
+				spec:       pushSpec,
+				tag:        pushTag,
+				authString: authString,
+				source:     source,
+			})
+		}
+	}
+
+	parallelism := b.PushParallelism
+	if parallelism <= 0 {
+		parallelism = common.DefaultPushParallelism
+	}
+
+	results := make([]pushOutcome, len(targets))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, target pushTarget) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = b.pushImage(target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	// the results of every pushed image are recorded before any failed push is reported, as the images have
+	// already been pushed to their registries
+	var pushErr error
+	for i, result := range results {
+		if result.err != nil {
+			log.WithError(result.err).WithField("name", targets[i].name()).Errorln("failed to push image")
+			if pushErr == nil {
+				pushErr = result.err
 			}
+			continue
 		}
+		res <- result.response
 		<-res
-		if pushSpec.Purge {
-			if err := b.Purge(pushFullImageName); err != nil {
-				log.WithError(err).Errorln("unable to complete image purge")
-				errChan <- err
-				return
+		b.PushResults = append(b.PushResults, result.result)
+	}
+	if pushErr != nil {
+		errChan <- pushErr
+		return
+	}
+	finished <- true
+}
+
+// validateCapabilities validates that the builder client is capable of every operation of the build before
+// any step is built. Structure tests and metadata inspect and run built images, so require images to be stored
+// locally, as do exports unless the builder writes them as outputs of the build.
+func validateCapabilities(spec v1alpha1.OCIBuilderSpec, buildOpts []v1alpha1.ImageBuildArgs, capabilities v1alpha1.BuilderCapabilities) error {
+	if capabilities.LocalImages {
+		return nil
+	}
+	if spec.Metadata != nil && spec.Metadata.StoreConfig != nil {
+		return errors.New("image metadata is not supported by builders which do not store images locally")
+	}
+	for _, opt := range buildOpts {
+		if opt.Test != nil {
+			return fmt.Errorf("structure tests of build step %s are not supported by builders which do not store images locally", opt.Name)
+		}
+		if len(opt.Export) > 0 && !capabilities.BuildExports {
+			return fmt.Errorf("exports of build step %s are not supported by the builder", opt.Name)
+		}
+	}
+	return nil
+}
+
+// tagRecordPath returns the path of the record of the tags rendered at build time
+func (b *Builder) tagRecordPath() string {
+	if b.TagRecordPath != "" {
+		return b.TagRecordPath
+	}
+	return common.TagRecordPath
+}
+
+// pushTarget is a single image reference pushed as part of a push spec
+type pushTarget struct {
+	spec       v1alpha1.PushSpec
+	tag        string
+	authString string
+	// source is the locally built image which is tagged as the target before pushing
+	source string
+}
+
+// resolvePushSpec defaults the image and tags of a push spec which references a build step, returning the
+// name of the image built by the step. The image is found by the tags recorded when the step was built.
+// An empty name is returned for push specs without a build step.
+func resolvePushSpec(pushSpec *v1alpha1.PushSpec, record *tag.Record) (string, error) {
+	if pushSpec.From == "" {
+		return "", nil
+	}
+	var tags []string
+	if record != nil {
+		tags = record.Steps[pushSpec.From]
+	}
+	if len(tags) == 0 {
+		return "", fmt.Errorf("no built image found for build step %s, the step must be built before it is pushed", pushSpec.From)
+	}
+	if pushSpec.Image == "" {
+		pushSpec.Image = pushSpec.From
+	}
+	if pushSpec.Tag == "" && len(pushSpec.Tags) == 0 {
+		pushSpec.Tag = tags[0]
+		pushSpec.Tags = tags[1:]
+	}
+	return fmt.Sprintf("%s:%s", pushSpec.From, tags[0]), nil
+}
+
+// pushDestinations returns the fully qualified image names of the push specs which push a build step, for
+// builders which push as part of the build. Push specs without tags push the tags of the step.
+func pushDestinations(pushSpecs []v1alpha1.PushSpec, step string, stepTags []string, vars tag.Variables) ([]string, error) {
+	var destinations []string
+	for _, pushSpec := range pushSpecs {
+		if pushSpec.From != step {
+			continue
+		}
+		image := pushSpec.Image
+		if image == "" {
+			image = step
+		}
+		tags := stepTags
+		if pushSpec.Tag != "" || len(pushSpec.Tags) > 0 {
+			rendered, err := tag.RenderAll(pushSpec.Tag, pushSpec.Tags, vars)
+			if err != nil {
+				return nil, err
 			}
+			tags = rendered
+		}
+		for _, t := range tags {
+			destinations = append(destinations, fmt.Sprintf("%s/%s:%s", pushSpec.Registry, image, t))
 		}
-		log.WithField("step", idx).Debugln("push step has finished executing")
 	}
-	finished <- true
+	return destinations, nil
 }
 
-func (b *Builder) Pull(spec v1alpha1.OCIBuilderSpec, imageName string, res chan<- v1alpha1.OCIPullResponse, errChan chan<- error, finished chan<- bool) {
-	log := b.Logger
-	cli := b.Client
+// name is the fully qualified image name of the push target
+func (t pushTarget) name() string {
+	return fmt.Sprintf("%s/%s:%s", t.spec.Registry, t.spec.Image, t.tag)
+}
 
-	for idx, loginSpec := range spec.Login {
-		registry := loginSpec.Registry
-		log.WithField("registry", registry).Debugln("attempting to pull from logged in registry")
-		authString, err := b.generateAuthRegistryString(registry, spec)
+// pushOutcome holds the buffered response and result of pushing a push target
+type pushOutcome struct {
+	response v1alpha1.OCIPushResponse
+	result   v1alpha1.PushResult
+	err      error
+}
+
+// pushImage pushes a single push target, buffering the push output and capturing the digest of the pushed image
+func (b *Builder) pushImage(target pushTarget) pushOutcome {
+	log := b.Logger.WithField("name", target.name())
+	log.Infoln("pushing image with name")
 
-		if registry != "" {
-			registry = registry + "/"
+	digestFile, err := ioutil.TempFile("", "ocib-digest")
+	if err != nil {
+		return pushOutcome{err: err}
+	}
+	digestFile.Close()
+	defer os.Remove(digestFile.Name())
+
+	pushOptions := v1alpha1.OCIPushOptions{
+		Ctx: context.Background(),
+		Ref: target.name(),
+		ImagePushOptions: types.ImagePushOptions{
+			RegistryAuth: target.authString,
+		},
+		DigestFile: digestFile.Name(),
+	}
+
+	if target.source != "" {
+		if err := b.Tag(target.source, target.name()); err != nil {
+			return pushOutcome{err: err}
 		}
+	}
+
+	pushResponse, err := b.Client.ImagePush(pushOptions)
+	if err != nil {
+		return pushOutcome{err: err}
+	}
 
+	outputs := readOutputs(pushResponse.Body, pushResponse.Stderr)
+	pushResponse.Body = ioutil.NopCloser(bytes.NewReader(outputs[0]))
+	pushResponse.Stderr = ioutil.NopCloser(bytes.NewReader(outputs[1]))
+
+	if pushResponse.Exec != nil {
+		log.Debugln("executing wait on push response")
+		if err := pushResponse.Exec.Wait(); err != nil {
+			return pushOutcome{err: err}
+		}
+		digest, err := ioutil.ReadFile(digestFile.Name())
 		if err != nil {
-			errChan <- err
+			return pushOutcome{err: err}
+		}
+		pushResponse.Digest = strings.TrimSpace(string(digest))
+	} else {
+		digest, err := parsePushDigest(outputs[0])
+		if err != nil {
+			return pushOutcome{err: err}
+		}
+		pushResponse.Digest = digest
+	}
+	log.WithField("digest", pushResponse.Digest).Debugln("captured digest of pushed image")
+
+	if target.spec.Purge {
+		if err := b.Purge(target.name()); err != nil {
+			return pushOutcome{err: err}
+		}
+	}
+
+	result := v1alpha1.PushResult{
+		Registry: target.spec.Registry,
+		Image:    target.spec.Image,
+		Tag:      target.tag,
+		Digest:   pushResponse.Digest,
+	}
+	if result.Digest != "" {
+		result.Reference = fmt.Sprintf("%s/%s@%s", result.Registry, result.Image, result.Digest)
+	}
+	return pushOutcome{response: pushResponse, result: result}
+}
+
+// parsePushDigest parses the digest of a pushed image from the aux message of a docker push json stream.
+// Any error reported in the stream is returned.
+func parsePushDigest(stream []byte) (string, error) {
+	var digest string
+	err := jsonmessage.DisplayJSONMessagesStream(bytes.NewReader(stream), ioutil.Discard, 0, false, func(msg jsonmessage.JSONMessage) {
+		if msg.Aux == nil {
 			return
 		}
+		var pushResult types.PushResult
+		if err := json.Unmarshal(*msg.Aux, &pushResult); err == nil && pushResult.Digest != "" {
+			digest = pushResult.Digest
+		}
+	})
+	return digest, err
+}
 
-		pullOptions := v1alpha1.OCIPullOptions{
-			Ctx: context.Background(),
-			Ref: registry + imageName,
-			ImagePullOptions: types.ImagePullOptions{
-				RegistryAuth: authString,
-			},
+// readOutputs reads each of the output streams of a response to completion
+func readOutputs(outputs ...io.ReadCloser) [][]byte {
+	read := make([][]byte, len(outputs))
+	var wg sync.WaitGroup
+	for i, output := range outputs {
+		if output == nil {
+			continue
 		}
+		wg.Add(1)
+		go func(i int, output io.ReadCloser) {
+			defer wg.Done()
+			read[i], _ = ioutil.ReadAll(output)
+		}(i, output)
+	}
+	wg.Wait()
+	return read
+}
 
-		pullResponse, err := cli.ImagePull(pullOptions)
+// Pull pulls each of the passed in images, skipping images already present in the builder when their pull
+// policy is IfNotPresent. Credentials are taken from the login spec matching the registry of each image.
+func (b *Builder) Pull(spec v1alpha1.OCIBuilderSpec, pulls []v1alpha1.PullSpec, res chan<- v1alpha1.OCIPullResponse, errChan chan<- error, finished chan<- bool) {
+	log := b.Logger
+
+	for idx, pull := range pulls {
+		pullResponse, pulled, err := b.pullImage(spec, pull)
 		if err != nil {
 			log.WithError(err).Errorln("failed to pull image")
 			errChan <- err
 			return
 		}
+		if !pulled {
+			continue
+		}
 
 		res <- pullResponse
 		if pullResponse.Exec != nil {
@@ -233,11 +584,143 @@ func (b *Builder) Pull(spec v1alpha1.OCIBuilderSpec, imageName string, res chan<
 			}
 		}
 
-		log.WithField("step", idx).Debugln("finished pull attempt from registry")
+		log.WithField("step", idx).Debugln("finished pull of image")
 	}
 	finished <- true
 }
 
+// PullBaseImages pulls the base image of every stage in the build spec which is not built by a previous stage
+func (b *Builder) PullBaseImages(spec v1alpha1.OCIBuilderSpec) error {
+	log := b.Logger
+
+	for _, pull := range baseImages(spec.Build) {
+		pullResponse, pulled, err := b.pullImage(spec, pull)
+		if err != nil {
+			return err
+		}
+		if !pulled {
+			continue
+		}
+
+		if pullResponse.Exec != nil {
+			b.drain(pullResponse.Body, pullResponse.Stderr)
+			log.Debugln("executing wait on pull response")
+			if err := pullResponse.Exec.Wait(); err != nil {
+				return err
+			}
+		} else if err := b.drainJSON(pullResponse.Body); err != nil {
+			return err
+		}
+		log.WithField("image", pullReference(pull)).Infoln("base image pulled")
+	}
+	return nil
+}
+
+// pullImage pulls an image according to its pull spec, returning false if the image was already present
+func (b *Builder) pullImage(spec v1alpha1.OCIBuilderSpec, pull v1alpha1.PullSpec) (v1alpha1.OCIPullResponse, bool, error) {
+	log := b.Logger
+	cli := b.Client
+
+	if err := validate.ValidatePullSpec(&pull); err != nil {
+		return v1alpha1.OCIPullResponse{}, false, err
+	}
+
+	ref := pullReference(pull)
+	if pull.Policy == v1alpha1.PullIfNotPresent {
+		if _, err := cli.ImageInspect(ref); err == nil {
+			log.WithField("image", ref).Debugln("image already present, skipping pull")
+			return v1alpha1.OCIPullResponse{}, false, nil
+		}
+	}
+
+	registry := pull.Registry
+	if registry == "" {
+		registry = util.ImageRegistry(pull.Image)
+	}
+	authString, err := b.pullAuth(registry, spec)
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, false, err
+	}
+
+	log.WithFields(logrus.Fields{"image": ref, "policy": pull.Policy}).Debugln("pulling image")
+	pullOptions := v1alpha1.OCIPullOptions{
+		Ctx: context.Background(),
+		Ref: ref,
+		ImagePullOptions: types.ImagePullOptions{
+			RegistryAuth: authString,
+			Platform:     pull.Platform,
+		},
+	}
+
+	pullResponse, err := cli.ImagePull(pullOptions)
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, false, err
+	}
+	return pullResponse, true, nil
+}
+
+// pullAuth returns the auth string of the login matching the registry, or an empty auth string for anonymous
+// pulls if no login matches
+func (b Builder) pullAuth(registry string, spec v1alpha1.OCIBuilderSpec) (string, error) {
+	for _, loginSpec := range spec.Login {
+		if loginSpec.Registry == registry || (loginSpec.Registry == "" && registry == common.DefaultImageRegistry) {
+			return b.generateAuthRegistryString(loginSpec.Registry, spec)
+		}
+	}
+	return "", nil
+}
+
+// pullReference returns the reference of the image to pull, defaulting to the latest tag
+func pullReference(pull v1alpha1.PullSpec) string {
+	ref := pull.Image
+	if pull.Registry != "" {
+		ref = fmt.Sprintf("%s/%s", pull.Registry, pull.Image)
+	}
+
+	switch {
+	case pull.Digest != "":
+		return fmt.Sprintf("%s@%s", ref, pull.Digest)
+	case pull.Tag != "":
+		return fmt.Sprintf("%s:%s", ref, pull.Tag)
+	case strings.Contains(ref, "@") || strings.LastIndex(ref, ":") > strings.LastIndex(ref, "/"):
+		return ref
+	default:
+		return fmt.Sprintf("%s:latest", ref)
+	}
+}
+
+// baseImages returns the pull specs of the base images of every stage in the build spec, excluding scratch and
+// bases which refer to a previous stage of the same build step
+func baseImages(spec *v1alpha1.BuildSpec) []v1alpha1.PullSpec {
+	var pulls []v1alpha1.PullSpec
+	if spec == nil {
+		return pulls
+	}
+
+	seen := make(map[string]bool)
+	for _, step := range spec.Steps {
+		stages := make(map[string]bool)
+		for _, stage := range step.Stages {
+			pull := v1alpha1.PullSpec{
+				Image: stage.Base.Image,
+				Tag:   stage.Base.Tag,
+			}
+			isStage := stages[pull.Image] && pull.Tag == ""
+			if stage.ImageMetadata != nil && stage.Name != "" {
+				stages[stage.Name] = true
+			}
+			if pull.Image == "" || pull.Image == "scratch" || isStage {
+				continue
+			}
+			if ref := pullReference(pull); !seen[ref] {
+				seen[ref] = true
+				pulls = append(pulls, pull)
+			}
+		}
+	}
+	return pulls
+}
+
 func (b *Builder) Login(spec v1alpha1.OCIBuilderSpec, res chan<- v1alpha1.OCILoginResponse, errChan chan<- error, finished chan<- bool) {
 	log := b.Logger
 	cli := b.Client
@@ -294,16 +777,194 @@ func (b *Builder) Purge(imageName string) error {
 		ImageRemoveOptions: types.ImageRemoveOptions{},
 	}
 
-	_, err := cli.ImageRemove(removeOptions)
+	removeResponse, err := cli.ImageRemove(removeOptions)
 	if err != nil {
 		log.WithError(err).Errorln("unable to complete image purge")
 		return err
 	}
 
+	if removeResponse.Exec != nil {
+		b.drain(removeResponse.Stderr)
+		log.Debugln("executing wait on remove response")
+		if err := removeResponse.Exec.Wait(); err != nil {
+			log.WithError(err).Errorln("unable to complete image purge")
+			return err
+		}
+	}
+
 	log.WithField("name", imageName).Infoln("image purged")
 	return nil
 }
 
+// Prune removes the intermediate stage images and dangling layers labelled with the build id
+func (b *Builder) Prune(buildID string) error {
+	log := b.Logger
+	cli := b.Client
+
+	log.WithField("buildId", buildID).Debugln("attempting to prune intermediate images")
+
+	pruneOptions := v1alpha1.OCIPruneOptions{
+		Labels: []string{fmt.Sprintf("%s=%s", common.LabelBuildID, buildID)},
+		Ctx:    context.Background(),
+	}
+
+	pruneResponse, err := cli.ImagesPrune(pruneOptions)
+	if err != nil {
+		return err
+	}
+
+	if pruneResponse.Exec != nil {
+		b.drain(pruneResponse.Stderr)
+		log.Debugln("executing wait on prune response")
+		if err := pruneResponse.Exec.Wait(); err != nil {
+			return err
+		}
+	}
+
+	log.WithField("count", len(pruneResponse.ImagesDeleted)).Infoln("intermediate images pruned")
+	return nil
+}
+
+// Tag tags the source image with the target name
+func (b *Builder) Tag(source string, target string) error {
+	log := b.Logger
+	cli := b.Client
+
+	log.WithFields(logrus.Fields{"source": source, "target": target}).Debugln("tagging image")
+	tagOptions := v1alpha1.OCITagOptions{
+		Source: source,
+		Target: target,
+		Ctx:    context.Background(),
+	}
+
+	tagResponse, err := cli.ImageTag(tagOptions)
+	if err != nil {
+		return err
+	}
+
+	if tagResponse.Exec != nil {
+		b.drain(tagResponse.Body, tagResponse.Stderr)
+		log.Debugln("executing wait on tag response")
+		if err := tagResponse.Exec.Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export writes an image to the local filesystem in each of the formats of the passed in export specs
+func (b *Builder) Export(imageName string, exports []v1alpha1.ExportSpec) error {
+	log := b.Logger
+	cli := b.Client
+
+	for _, export := range exports {
+		if err := validate.ValidateExportSpec(export); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(export.Path), 0755); err != nil {
+			return err
+		}
+
+		log.WithFields(logrus.Fields{"image": imageName, "format": export.Format, "path": export.Path}).Infoln("exporting image")
+		saveOptions := v1alpha1.OCISaveOptions{
+			Image:  imageName,
+			Format: export.Format,
+			Dest:   export.Path,
+			Ctx:    context.Background(),
+		}
+
+		saveResponse, err := cli.ImageSave(saveOptions)
+		if err != nil {
+			return err
+		}
+
+		if saveResponse.Exec != nil {
+			b.drain(saveResponse.Body, saveResponse.Stderr)
+			log.Debugln("executing wait on export response")
+			if err := saveResponse.Exec.Wait(); err != nil {
+				return err
+			}
+		}
+		log.WithField("path", export.Path).Infoln("image exported")
+	}
+	return nil
+}
+
+// Load loads images from the local filesystem into the builder, detecting the format of any load specs without one
+func (b *Builder) Load(loads []v1alpha1.LoadSpec) error {
+	log := b.Logger
+	cli := b.Client
+
+	for _, load := range loads {
+		format := load.Format
+		if format == "" {
+			detected, err := layout.DetectFormat(load.Input)
+			if err != nil {
+				return err
+			}
+			format = detected
+		}
+
+		log.WithFields(logrus.Fields{"input": load.Input, "format": format}).Infoln("loading image")
+		loadOptions := v1alpha1.OCILoadOptions{
+			Input:  load.Input,
+			Format: format,
+			Ctx:    context.Background(),
+		}
+
+		loadResponse, err := cli.ImageLoad(loadOptions)
+		if err != nil {
+			return err
+		}
+
+		if loadResponse.Exec != nil {
+			b.drain(loadResponse.Body, loadResponse.Stderr)
+			log.Debugln("executing wait on load response")
+			if err := loadResponse.Exec.Wait(); err != nil {
+				return err
+			}
+		} else if err := b.drainJSON(loadResponse.Body); err != nil {
+			return err
+		}
+		log.WithField("input", load.Input).Infoln("image loaded")
+	}
+	return nil
+}
+
+// drain reads the output streams of an executed command to completion, logging them at debug level
+func (b *Builder) drain(outputs ...io.ReadCloser) {
+	w := b.Logger.WriterLevel(logrus.DebugLevel)
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for _, output := range outputs {
+		if output == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(output io.ReadCloser) {
+			defer wg.Done()
+			if _, err := io.Copy(w, output); err != nil {
+				b.Logger.WithError(err).Debugln("error reading command output")
+			}
+		}(output)
+	}
+	wg.Wait()
+}
+
+// drainJSON reads a docker json message stream to completion, logging it at debug level. The docker daemon
+// reports errors in the json message stream, which are returned.
+func (b *Builder) drainJSON(body io.ReadCloser) error {
+	if body == nil {
+		return nil
+	}
+	defer body.Close()
+
+	w := b.Logger.WriterLevel(logrus.DebugLevel)
+	defer w.Close()
+	return jsonmessage.DisplayJSONMessagesStream(body, w, 0, false, nil)
+}
+
 func (b *Builder) Clean() {
 	log := b.Logger
 	log.WithField("provenance", b.Provenance).Debugln("attempting to cleanup files listed in build provenance")
@@ -19,22 +19,32 @@ package oci
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/image"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/fake"
+	"github.com/ocibuilder/ocibuilder/pkg/tag"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
 	"github.com/ocibuilder/ocibuilder/testing/dummy"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestBuilder_Build(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-build")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
 	builder := Builder{
-		Logger:     util.GetLogger(true),
-		Client:     testClient{},
-		Provenance: []*v1alpha1.BuildProvenance{},
+		Logger:        util.GetLogger(true),
+		Client:        testClient{},
+		Provenance:    []*v1alpha1.BuildProvenance{},
+		TagRecordPath: filepath.Join(dir, "tags.json"),
 	}
 
 	res := make(chan v1alpha1.OCIBuildResponse)
@@ -63,6 +73,9 @@ func TestBuilder_Build(t *testing.T) {
 		case fin := <-finished:
 			{
 				assert.True(t, fin, "expecting finished to be reached without an error on the error channel")
+				record, err := tag.ReadRecord(builder.TagRecordPath)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, len(dummy.Spec.Build.Steps), len(record.Steps))
 				return
 			}
 		}
@@ -70,6 +83,45 @@ func TestBuilder_Build(t *testing.T) {
 
 }
 
+func TestBuilder_Build_Fake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-build")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	cli := fake.NewClient(util.GetLogger(true))
+	builder := Builder{
+		Logger:        util.GetLogger(true),
+		Client:        cli,
+		TagRecordPath: filepath.Join(dir, "tags.json"),
+	}
+
+	res := make(chan v1alpha1.OCIBuildResponse)
+	errChan := make(chan error)
+	finished := make(chan bool)
+
+	go builder.Build(dummy.Spec, res, errChan, finished)
+
+	var output []byte
+	for done := false; !done; {
+		select {
+		case err := <-errChan:
+			assert.Fail(t, "unexpected build error", err)
+			done = true
+		case buildResponse := <-res:
+			body, err := ioutil.ReadAll(buildResponse.Body)
+			assert.Equal(t, nil, err)
+			output = append(output, body...)
+			res <- buildResponse
+		case <-finished:
+			done = true
+		}
+	}
+
+	// the fake client builds the generated Dockerfile of the gzipped build context
+	assert.Contains(t, string(output), "Step 1/3 : FROM alpine AS stage-one")
+	assert.Contains(t, string(output), "Step 3/3 : done")
+}
+
 func TestBuilder_Build2(t *testing.T) {
 	exists := true
 	if _, err := os.Stat("./ocib"); os.IsNotExist(err) {
@@ -79,9 +131,275 @@ func TestBuilder_Build2(t *testing.T) {
 }
 
 func TestBuilder_Pull(t *testing.T) {
+	builder := Builder{
+		Logger: util.GetLogger(true),
+		Client: testClient{},
+	}
+
+	res := make(chan v1alpha1.OCIPullResponse)
+	errChan := make(chan error)
+	finished := make(chan bool)
+
+	pulls := []v1alpha1.PullSpec{
+		{Image: "image-name", Tag: "v0.1.0", Policy: v1alpha1.PullAlways},
+		{Image: "image-name", Tag: "v0.1.0"},
+	}
+	go builder.Pull(v1alpha1.OCIBuilderSpec{}, pulls, res, errChan, finished)
+
+	var pulled int
+	for done := false; !done; {
+		select {
+		case <-res:
+			pulled++
+		case err := <-errChan:
+			assert.Fail(t, "unexpected pull error", err)
+			done = true
+		case <-finished:
+			done = true
+		}
+	}
+	assert.Equal(t, 1, pulled, "expecting images which are present to be skipped by the IfNotPresent policy")
+}
+
+func TestPullReference(t *testing.T) {
+	assert.Equal(t, "image-name:latest", pullReference(v1alpha1.PullSpec{Image: "image-name"}))
+	assert.Equal(t, "localhost:5000/image-name:v0.1.0", pullReference(v1alpha1.PullSpec{Image: "localhost:5000/image-name:v0.1.0"}))
+	assert.Equal(t, "example-registry/image-name:v0.1.0", pullReference(v1alpha1.PullSpec{Registry: "example-registry", Image: "image-name", Tag: "v0.1.0"}))
+	assert.Equal(t, "image-name@sha256:abc", pullReference(v1alpha1.PullSpec{Image: "image-name", Digest: "sha256:abc"}))
+}
+
+func TestBaseImages(t *testing.T) {
+	spec := &v1alpha1.BuildSpec{
+		Steps: []v1alpha1.BuildStep{{
+			Stages: []v1alpha1.Stage{
+				{ImageMetadata: &v1alpha1.ImageMetadata{Name: "build"}, Base: v1alpha1.Base{Image: "golang", Tag: "1.13"}},
+				{ImageMetadata: &v1alpha1.ImageMetadata{Name: "test"}, Base: v1alpha1.Base{Image: "build"}},
+				{Base: v1alpha1.Base{Image: "scratch"}},
+			},
+		}, {
+			Stages: []v1alpha1.Stage{
+				{Base: v1alpha1.Base{Image: "golang", Tag: "1.13"}},
+				{Base: v1alpha1.Base{Image: "alpine"}},
+			},
+		}},
+	}
+	assert.Equal(t, []v1alpha1.PullSpec{
+		{Image: "golang", Tag: "1.13"},
+		{Image: "alpine"},
+	}, baseImages(spec))
 }
 
 func TestBuilder_Push(t *testing.T) {
+	builder := Builder{
+		Logger:        util.GetLogger(true),
+		Client:        testPushClient{},
+		TagRecordPath: filepath.Join(os.TempDir(), "ocib-no-build-tags.json"),
+	}
+
+	spec := v1alpha1.OCIBuilderSpec{
+		Login: dummy.LoginSpec,
+		Push: []v1alpha1.PushSpec{{
+			Registry: "example-registry",
+			Image:    "example-image",
+			Tag:      "1.0.0",
+			Tags:     []string{"latest"},
+		}},
+	}
+
+	res := make(chan v1alpha1.OCIPushResponse)
+	errChan := make(chan error)
+	finished := make(chan bool)
+
+	go builder.Push(spec, res, errChan, finished)
+
+	var digests []string
+	for {
+		select {
+		case err := <-errChan:
+			assert.Equal(t, nil, err)
+			return
+		case pushResponse := <-res:
+			digests = append(digests, pushResponse.Digest)
+			res <- pushResponse
+		case <-finished:
+			assert.Equal(t, []string{"sha256:example-image-1.0.0", "sha256:example-image-latest"}, digests)
+			assert.Equal(t, []v1alpha1.PushResult{
+				{
+					Registry:  "example-registry",
+					Image:     "example-image",
+					Tag:       "1.0.0",
+					Digest:    "sha256:example-image-1.0.0",
+					Reference: "example-registry/example-image@sha256:example-image-1.0.0",
+				},
+				{
+					Registry:  "example-registry",
+					Image:     "example-image",
+					Tag:       "latest",
+					Digest:    "sha256:example-image-latest",
+					Reference: "example-registry/example-image@sha256:example-image-latest",
+				},
+			}, builder.PushResults)
+			return
+		}
+	}
+}
+
+func TestBuilder_Push_PartialFailure(t *testing.T) {
+	builder := Builder{
+		Logger:        util.GetLogger(true),
+		Client:        testPushClient{},
+		TagRecordPath: filepath.Join(os.TempDir(), "ocib-no-build-tags.json"),
+	}
+
+	spec := v1alpha1.OCIBuilderSpec{
+		Login: dummy.LoginSpec,
+		Push: []v1alpha1.PushSpec{{
+			Registry: "example-registry",
+			Image:    "example-image",
+			Tag:      "broken",
+			Tags:     []string{"latest"},
+		}},
+	}
+
+	res := make(chan v1alpha1.OCIPushResponse)
+	errChan := make(chan error)
+	finished := make(chan bool)
+
+	go builder.Push(spec, res, errChan, finished)
+
+	for {
+		select {
+		case err := <-errChan:
+			assert.EqualError(t, err, "push of example-registry/example-image:broken failed")
+			assert.Equal(t, []v1alpha1.PushResult{{
+				Registry:  "example-registry",
+				Image:     "example-image",
+				Tag:       "latest",
+				Digest:    "sha256:example-image-latest",
+				Reference: "example-registry/example-image@sha256:example-image-latest",
+			}}, builder.PushResults)
+			return
+		case pushResponse := <-res:
+			res <- pushResponse
+		case <-finished:
+			t.Fatal("push finished without reporting the failed push")
+		}
+	}
+}
+
+func TestBuilder_Push_RecordedVariables(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-tags")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	record := tag.NewRecord()
+	record.StepVariables["my-step"] = tag.Variables{Git: tag.Git{Branch: "feature"}}
+	record.Steps["my-step"] = []string{"v0.1.0"}
+	recordPath := filepath.Join(dir, "tags.json")
+	assert.Equal(t, nil, record.Write(recordPath))
+
+	builder := Builder{
+		Logger:        util.GetLogger(true),
+		Client:        testPushClient{},
+		TagRecordPath: recordPath,
+	}
+	spec := v1alpha1.OCIBuilderSpec{
+		Login: dummy.LoginSpec,
+		Push: []v1alpha1.PushSpec{{
+			Registry: "example-registry",
+			From:     "my-step",
+			Tag:      "{{ .Git.Branch }}",
+		}},
+	}
+
+	res := make(chan v1alpha1.OCIPushResponse)
+	errChan := make(chan error)
+	finished := make(chan bool)
+
+	go builder.Push(spec, res, errChan, finished)
+
+	var digests []string
+	for {
+		select {
+		case err := <-errChan:
+			assert.Equal(t, nil, err)
+			return
+		case pushResponse := <-res:
+			digests = append(digests, pushResponse.Digest)
+			res <- pushResponse
+		case <-finished:
+			assert.Equal(t, []string{"sha256:my-step-feature"}, digests)
+			return
+		}
+	}
+}
+
+func TestResolvePushSpec(t *testing.T) {
+	record := tag.NewRecord()
+	record.Steps["my-step"] = []string{"v0.1.0", "latest"}
+
+	pushSpec := v1alpha1.PushSpec{Registry: "example-registry", From: "my-step"}
+	source, err := resolvePushSpec(&pushSpec, record)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "my-step:v0.1.0", source)
+	assert.Equal(t, "my-step", pushSpec.Image)
+	assert.Equal(t, "v0.1.0", pushSpec.Tag)
+	assert.Equal(t, []string{"latest"}, pushSpec.Tags)
+
+	pushSpec = v1alpha1.PushSpec{Registry: "example-registry", From: "my-step", Image: "example-image", Tag: "1.0.0"}
+	source, err = resolvePushSpec(&pushSpec, record)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "my-step:v0.1.0", source)
+	assert.Equal(t, "example-image", pushSpec.Image)
+	assert.Equal(t, "1.0.0", pushSpec.Tag)
+
+	_, err = resolvePushSpec(&v1alpha1.PushSpec{From: "missing-step"}, record)
+	assert.Error(t, err)
+	_, err = resolvePushSpec(&v1alpha1.PushSpec{From: "my-step"}, nil)
+	assert.Error(t, err)
+}
+
+func TestPushDestinations(t *testing.T) {
+	pushSpecs := []v1alpha1.PushSpec{
+		{Registry: "example-registry", From: "my-step"},
+		{Registry: "other-registry", From: "my-step", Image: "example-image", Tag: "1.0.0"},
+		{Registry: "example-registry", From: "other-step"},
+	}
+	destinations, err := pushDestinations(pushSpecs, "my-step", []string{"v0.1.0", "latest"}, tag.Variables{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{
+		"example-registry/my-step:v0.1.0",
+		"example-registry/my-step:latest",
+		"other-registry/example-image:1.0.0",
+	}, destinations)
+
+	destinations, err = pushDestinations(pushSpecs, "unpushed-step", []string{"v0.1.0"}, tag.Variables{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(destinations))
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	buildOpts := []v1alpha1.ImageBuildArgs{{Name: "my-step", Export: []v1alpha1.ExportSpec{{Format: v1alpha1.OCIArchiveExportFormat, Path: "image.tar"}}}}
+	assert.Equal(t, nil, validateCapabilities(v1alpha1.OCIBuilderSpec{}, buildOpts, v1alpha1.BuilderCapabilities{LocalImages: true}))
+	assert.Equal(t, nil, validateCapabilities(v1alpha1.OCIBuilderSpec{}, buildOpts, v1alpha1.BuilderCapabilities{BuildExports: true}))
+	assert.Error(t, validateCapabilities(v1alpha1.OCIBuilderSpec{}, buildOpts, v1alpha1.BuilderCapabilities{}))
+
+	buildOpts = []v1alpha1.ImageBuildArgs{{Name: "my-step", Test: &v1alpha1.ImageTest{}}}
+	assert.Error(t, validateCapabilities(v1alpha1.OCIBuilderSpec{}, buildOpts, v1alpha1.BuilderCapabilities{BuildExports: true}))
+
+	spec := v1alpha1.OCIBuilderSpec{Metadata: &v1alpha1.Metadata{StoreConfig: &v1alpha1.StoreConfig{}}}
+	assert.Error(t, validateCapabilities(spec, nil, v1alpha1.BuilderCapabilities{BuildExports: true}))
+}
+
+func TestParsePushDigest(t *testing.T) {
+	digest, err := parsePushDigest([]byte(`{"status":"Pushed","id":"abc"}
+{"status":"1.0.0: digest: sha256:abc size: 528"}
+{"progressDetail":{},"aux":{"Tag":"1.0.0","Digest":"sha256:abc","Size":528}}`))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha256:abc", digest)
+
+	_, err = parsePushDigest([]byte(`{"errorDetail":{"message":"denied"},"error":"denied"}`))
+	assert.Error(t, err)
 }
 
 func TestBuilder_Login(t *testing.T) {
@@ -91,7 +409,50 @@ func TestBuilder_Clean(t *testing.T) {
 }
 
 func TestBuilder_Purge(t *testing.T) {
+	builder := Builder{
+		Logger: util.GetLogger(true),
+		Client: testClient{},
+	}
+	err := builder.Purge("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+
+	builder.Client = testRemoveClient{}
+	err = builder.Purge("image-name:v0.1.0")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "image is in use")
+}
+
+func TestBuilder_Load(t *testing.T) {
+	builder := Builder{
+		Logger: util.GetLogger(true),
+		Client: testClient{},
+	}
+
+	err := builder.Load([]v1alpha1.LoadSpec{{Input: "image.tar", Format: v1alpha1.DockerArchiveExportFormat}})
+	assert.Equal(t, nil, err)
 
+	err = builder.Load([]v1alpha1.LoadSpec{{Input: "does-not-exist.tar"}})
+	assert.Error(t, err)
+}
+
+func TestBuilder_Export(t *testing.T) {
+	builder := Builder{
+		Logger: util.GetLogger(true),
+		Client: testClient{},
+	}
+
+	dir, err := ioutil.TempDir("", "ocib-export")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	err = builder.Export("image-name:v0.1.0", []v1alpha1.ExportSpec{
+		{Format: v1alpha1.OCIExportFormat, Path: filepath.Join(dir, "layout")},
+		{Format: v1alpha1.DockerArchiveExportFormat, Path: filepath.Join(dir, "archives", "image.tar")},
+	})
+	assert.Equal(t, nil, err)
+
+	err = builder.Export("image-name:v0.1.0", []v1alpha1.ExportSpec{{Format: "invalid", Path: dir}})
+	assert.Error(t, err)
 }
 
 func (t testClient) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
@@ -113,18 +474,67 @@ func (t testClient) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPush
 func (t testClient) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
 	return v1alpha1.OCIRemoveResponse{}, nil
 }
+func (t testClient) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	return v1alpha1.OCIPruneResponse{}, nil
+}
+func (t testClient) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	return v1alpha1.OCITagResponse{}, nil
+}
 func (t testClient) ImageInspect(imageId string) (types.ImageInspect, error) {
 	return types.ImageInspect{}, nil
 }
 func (t testClient) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
 	return nil, nil
 }
+func (t testClient) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	return v1alpha1.OCISaveResponse{}, nil
+}
+func (t testClient) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	return v1alpha1.OCILoadResponse{}, nil
+}
+func (t testClient) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	return v1alpha1.OCIRunResponse{}, nil
+}
 func (t testClient) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
 	return v1alpha1.OCILoginResponse{}, nil
 }
 func (t testClient) GenerateAuthRegistryString(auth types.AuthConfig) string {
 	return ""
 }
+func (t testClient) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{LocalImages: true}
+}
 
 type testClient struct {
 }
+
+// testRemoveClient runs a remove command which fails, as the buildah and podman clients do for images in use
+type testRemoveClient struct {
+	testClient
+}
+
+func (t testRemoveClient) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
+	cmd := command.Builder("sh").Args("-c", "echo image is in use >&2; exit 1").Build()
+	_, stderr, err := cmd.Exec()
+	if err != nil {
+		return v1alpha1.OCIRemoveResponse{}, err
+	}
+	return v1alpha1.OCIRemoveResponse{Exec: &cmd, Stderr: stderr}, nil
+}
+
+// testPushClient returns a docker push json stream with a digest derived from the pushed reference
+type testPushClient struct {
+	testClient
+}
+
+func (t testPushClient) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
+	ref := strings.TrimPrefix(options.Ref, "example-registry/")
+	if strings.HasSuffix(ref, ":broken") {
+		return v1alpha1.OCIPushResponse{}, errors.New("push of " + options.Ref + " failed")
+	}
+	digest := "sha256:" + strings.Replace(ref, ":", "-", 1)
+	body := `{"status":"Pushed"}` + "\n" + `{"aux":{"Tag":"","Digest":"` + digest + `","Size":1}}`
+	return v1alpha1.OCIPushResponse{
+		Body: ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/ocibuilder/gofeas"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
-	"github.com/ocibuilder/ocibuilder/pkg/buildah"
 	"github.com/ocibuilder/ocibuilder/pkg/crypto"
 	"github.com/ocibuilder/ocibuilder/pkg/store"
 	"github.com/ocibuilder/ocibuilder/pkg/store/grafeas"
@@ -53,10 +52,6 @@ func (m MetadataWriter) Write() error {
 func (m *MetadataWriter) ParseMetadata(imageName string, cli v1alpha1.BuilderClient, provenance *v1alpha1.BuildProvenance) error {
 	log := m.Logger
 
-	if _, ok := cli.(buildah.Client); ok {
-		return errors.New("writing metadata is currently only supported for use with docker")
-	}
-
 	log.Debugln("conducting image inspect")
 	inspectResponse, err := cli.ImageInspect(imageName)
 	if err != nil || inspectResponse.ID == "" {
@@ -119,6 +119,14 @@ func (t testClientMetadata) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1al
 	return v1alpha1.OCIRemoveResponse{}, nil
 }
 
+func (t testClientMetadata) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	return v1alpha1.OCIPruneResponse{}, nil
+}
+
+func (t testClientMetadata) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	return v1alpha1.OCITagResponse{}, nil
+}
+
 func (t testClientMetadata) ImageInspect(imageId string) (types.ImageInspect, error) {
 	return types.ImageInspect{
 		ID:          "sha256-imageid",
@@ -134,6 +142,18 @@ func (t testClientMetadata) ImageHistory(imageId string) ([]image.HistoryRespons
 	}}, nil
 }
 
+func (t testClientMetadata) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	return v1alpha1.OCISaveResponse{}, nil
+}
+
+func (t testClientMetadata) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	return v1alpha1.OCILoadResponse{}, nil
+}
+
+func (t testClientMetadata) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	return v1alpha1.OCIRunResponse{}, nil
+}
+
 func (t testClientMetadata) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
 	return v1alpha1.OCILoginResponse{}, nil
 }
@@ -141,6 +161,9 @@ func (t testClientMetadata) RegistryLogin(options v1alpha1.OCILoginOptions) (v1a
 func (t testClientMetadata) GenerateAuthRegistryString(auth types.AuthConfig) string {
 	return ""
 }
+func (t testClientMetadata) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{LocalImages: true}
+}
 
 type testClientMetadata struct {
 }
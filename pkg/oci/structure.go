@@ -0,0 +1,228 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-units"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/layout"
+	"github.com/ocibuilder/ocibuilder/pkg/validate"
+)
+
+// Test runs the structure tests of a build step against a built image. Every test is run and each failure is
+// logged before an error is returned.
+func (b *Builder) Test(imageName string, test *v1alpha1.ImageTest) error {
+	log := b.Logger.WithField("image", imageName)
+	if test == nil {
+		return nil
+	}
+	if err := validate.ValidateImageTest(*test); err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, commandTest := range test.Commands {
+		commandFailures, err := b.testCommand(imageName, commandTest)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, commandFailures...)
+	}
+
+	if len(test.Files) > 0 {
+		files, err := b.imageFiles(imageName)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, testFiles(files, test.Files)...)
+	}
+
+	if test.Metadata != nil || test.MaxSize != "" {
+		imageInspect, err := b.Client.ImageInspect(imageName)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, testMetadata(imageInspect, test)...)
+	}
+
+	for _, failure := range failures {
+		log.Errorln(failure)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("image %s failed %d structure tests", imageName, len(failures))
+	}
+	log.Infoln("structure tests passed")
+	return nil
+}
+
+// testCommand runs a command test in a container of the image, returning its failures
+func (b *Builder) testCommand(imageName string, commandTest v1alpha1.CommandTest) ([]string, error) {
+	b.Logger.WithField("test", commandTest.Name).Debugln("running command test")
+	runResponse, err := b.Client.ContainerRun(v1alpha1.OCIRunOptions{
+		Image: imageName,
+		Cmd:   commandTest.Command,
+		Ctx:   context.Background(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout []byte
+	if runResponse.Body != nil {
+		defer runResponse.Body.Close()
+		if stdout, err = ioutil.ReadAll(runResponse.Body); err != nil {
+			return nil, err
+		}
+	}
+	if runResponse.Stderr != nil {
+		b.drain(runResponse.Stderr)
+	}
+
+	var failures []string
+	if runResponse.ExitCode != commandTest.ExitCode {
+		failures = append(failures, fmt.Sprintf("command test %s: expected exit code %d, got %d", commandTest.Name, commandTest.ExitCode, runResponse.ExitCode))
+	}
+	for _, expr := range commandTest.ExpectedOutput {
+		if !regexp.MustCompile(expr).Match(stdout) {
+			failures = append(failures, fmt.Sprintf("command test %s: expected output matching %s", commandTest.Name, expr))
+		}
+	}
+	for _, expr := range commandTest.ExcludedOutput {
+		if regexp.MustCompile(expr).Match(stdout) {
+			failures = append(failures, fmt.Sprintf("command test %s: excluded output matching %s found", commandTest.Name, expr))
+		}
+	}
+	return failures, nil
+}
+
+// imageFiles saves the image as a docker-archive and returns every path in its filesystem
+func (b *Builder) imageFiles(imageName string) (map[string]bool, error) {
+	dir, err := ioutil.TempDir("", "ocib-test")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "image.tar")
+	if err := b.Export(imageName, []v1alpha1.ExportSpec{{Format: v1alpha1.DockerArchiveExportFormat, Path: archive}}); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	extracted := filepath.Join(dir, "image")
+	if err := layout.Extract(f, extracted); err != nil {
+		return nil, err
+	}
+	return layout.Files(extracted)
+}
+
+// testFiles checks the paths which must or must not exist in the image files, returning the failures
+func testFiles(files map[string]bool, fileTests []v1alpha1.FileTest) []string {
+	var failures []string
+	for _, fileTest := range fileTests {
+		name := path.Clean("/" + fileTest.Path)
+		exists := files[name]
+		for file := range files {
+			if exists {
+				break
+			}
+			exists = strings.HasPrefix(file, name+"/")
+		}
+
+		if exists && !fileTest.ShouldExist {
+			failures = append(failures, fmt.Sprintf("file test: %s should not exist", name))
+		}
+		if !exists && fileTest.ShouldExist {
+			failures = append(failures, fmt.Sprintf("file test: %s should exist", name))
+		}
+	}
+	return failures
+}
+
+// testMetadata checks the expected configuration and size of an image, returning the failures
+func testMetadata(imageInspect types.ImageInspect, test *v1alpha1.ImageTest) []string {
+	var failures []string
+
+	if test.MaxSize != "" {
+		maxSize, _ := units.FromHumanSize(test.MaxSize)
+		if imageInspect.Size > maxSize {
+			failures = append(failures, fmt.Sprintf("size test: image size %s exceeds %s", units.HumanSize(float64(imageInspect.Size)), test.MaxSize))
+		}
+	}
+
+	metadata := test.Metadata
+	if metadata == nil {
+		return failures
+	}
+	if imageInspect.Config == nil {
+		return append(failures, "metadata test: image has no configuration")
+	}
+	config := imageInspect.Config
+
+	env := make(map[string]string)
+	for _, e := range config.Env {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 2 {
+			env[kv[0]] = kv[1]
+		}
+	}
+	for key, value := range metadata.Env {
+		if actual, ok := env[key]; !ok || actual != value {
+			failures = append(failures, fmt.Sprintf("metadata test: expected env %s=%s, got %s=%s", key, value, key, actual))
+		}
+	}
+
+	if metadata.Entrypoint != nil && !reflect.DeepEqual(metadata.Entrypoint, []string(config.Entrypoint)) {
+		failures = append(failures, fmt.Sprintf("metadata test: expected entrypoint %v, got %v", metadata.Entrypoint, config.Entrypoint))
+	}
+
+	for _, port := range metadata.ExposedPorts {
+		if !strings.Contains(port, "/") {
+			port = port + "/tcp"
+		}
+		found := false
+		for exposed := range config.ExposedPorts {
+			found = found || string(exposed) == port
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("metadata test: expected port %s to be exposed", port))
+		}
+	}
+
+	for key, value := range metadata.Labels {
+		if actual, ok := config.Labels[key]; !ok || actual != value {
+			failures = append(failures, fmt.Sprintf("metadata test: expected label %s=%s, got %s=%s", key, value, key, actual))
+		}
+	}
+	return failures
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Test(t *testing.T) {
+	builder := Builder{
+		Logger: util.GetLogger(true),
+		Client: testRunClient{},
+	}
+
+	err := builder.Test("image-name:v0.1.0", &v1alpha1.ImageTest{
+		Commands: []v1alpha1.CommandTest{{
+			Name:           "version",
+			Command:        []string{"app", "--version"},
+			ExpectedOutput: []string{"^app v[0-9]+"},
+			ExcludedOutput: []string{"dirty"},
+		}},
+	})
+	assert.Equal(t, nil, err)
+
+	err = builder.Test("image-name:v0.1.0", &v1alpha1.ImageTest{
+		Commands: []v1alpha1.CommandTest{{
+			Name:     "failing",
+			Command:  []string{"app", "--version"},
+			ExitCode: 1,
+		}},
+	})
+	assert.Error(t, err)
+
+	err = builder.Test("image-name:v0.1.0", &v1alpha1.ImageTest{
+		Commands: []v1alpha1.CommandTest{{Name: "invalid", Command: []string{"app"}, ExpectedOutput: []string{"("}}},
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, nil, builder.Test("image-name:v0.1.0", nil))
+}
+
+func TestTestFiles(t *testing.T) {
+	files := map[string]bool{"/usr/bin/app": true, "/etc/passwd": true}
+	failures := testFiles(files, []v1alpha1.FileTest{
+		{Path: "/usr/bin/app", ShouldExist: true},
+		{Path: "usr/bin", ShouldExist: true},
+		{Path: "/bin/sh", ShouldExist: false},
+	})
+	assert.Equal(t, 0, len(failures))
+
+	failures = testFiles(files, []v1alpha1.FileTest{
+		{Path: "/bin/sh", ShouldExist: true},
+		{Path: "/etc/passwd", ShouldExist: false},
+	})
+	assert.Equal(t, []string{"file test: /bin/sh should exist", "file test: /etc/passwd should not exist"}, failures)
+}
+
+func TestTestMetadata(t *testing.T) {
+	imageInspect := types.ImageInspect{
+		Size: 20000000,
+		Config: &container.Config{
+			Env:          []string{"PATH=/usr/bin", "PORT=8080"},
+			Entrypoint:   []string{"/usr/bin/app"},
+			ExposedPorts: nat.PortSet{"8080/tcp": struct{}{}},
+			Labels:       map[string]string{"maintainer": "ocibuilder"},
+		},
+	}
+
+	failures := testMetadata(imageInspect, &v1alpha1.ImageTest{
+		MaxSize: "25MB",
+		Metadata: &v1alpha1.MetadataTest{
+			Env:          map[string]string{"PORT": "8080"},
+			Entrypoint:   []string{"/usr/bin/app"},
+			ExposedPorts: []string{"8080"},
+			Labels:       map[string]string{"maintainer": "ocibuilder"},
+		},
+	})
+	assert.Equal(t, 0, len(failures))
+
+	failures = testMetadata(imageInspect, &v1alpha1.ImageTest{
+		MaxSize: "10MB",
+		Metadata: &v1alpha1.MetadataTest{
+			Entrypoint:   []string{"/bin/sh"},
+			ExposedPorts: []string{"9090/udp"},
+		},
+	})
+	assert.Equal(t, 3, len(failures))
+}
+
+// testRunClient returns an app version on stdout for every command run
+type testRunClient struct {
+	testClient
+}
+
+func (t testRunClient) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	return v1alpha1.OCIRunResponse{
+		Body:     ioutil.NopCloser(strings.NewReader("app v1.2.3\n")),
+		ExitCode: 0,
+	}, nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+)
+
+// maxCommandLineSize is the maximum size of a line of a docker commands file
+const maxCommandLineSize = 1024 * 1024
+
+// parserDirective matches a parser directive at the start of a Dockerfile, e.g. # escape=\
+var parserDirective = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+
+// lineContinuation matches a line continued on the next line
+var lineContinuation = regexp.MustCompile(`\\[ \t]*$`)
+
+// heredocMarker matches the start of a heredoc in a RUN, COPY or ADD instruction, e.g. <<EOF or <<-"EOF"
+var heredocMarker = regexp.MustCompile(`^<<(-?)["']?([a-zA-Z_][a-zA-Z0-9_]*)["']?$`)
+
+// heredocInstructions are the instructions which accept heredocs
+var heredocInstructions = map[string]bool{"run": true, "copy": true, "add": true}
+
+// readDockerCommands reads the instructions of a file of docker commands, keeping the source of each instruction
+// as written, with its flags, JSON form, line continuations and heredocs, and the comments preceding it.
+// Parser directives are added to directives to be written at the top of the generated Dockerfile, only the
+// default escape character is supported as the commands are added to a generated Dockerfile. Blank lines are
+// removed and a blank line is added before each FROM.
+func readDockerCommands(r io.Reader, directives map[string]string) ([]byte, error) {
+	var dockerfile []byte
+	var comments []string
+	atDirectives := true
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), maxCommandLineSize)
+	scan := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		lineNumber++
+		return strings.TrimRight(scanner.Text(), "\r"), true
+	}
+
+	for {
+		line, ok := scan()
+		if !ok {
+			break
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			atDirectives = false
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			if match := parserDirective.FindStringSubmatch(trimmed); atDirectives && match != nil {
+				if strings.ToLower(match[1]) == "escape" && match[2] != `\` {
+					return nil, errors.Errorf("unsupported escape parser directive %s, docker commands must use the default escape character", match[2])
+				}
+				if err := addParserDirective(directives, match[1], match[2]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			atDirectives = false
+			comments = append(comments, trimmed)
+			continue
+		}
+		atDirectives = false
+
+		// comments and blank lines within a continued instruction are skipped by the Dockerfile parser
+		startLine := lineNumber
+		lines := []string{strings.TrimLeft(line, " \t")}
+		instruction := lineContinuation.ReplaceAllString(lines[0], "")
+		for continued := lineContinuation.MatchString(line); continued; {
+			if line, ok = scan(); !ok {
+				break
+			}
+			lines = append(lines, line)
+			if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			continued = lineContinuation.MatchString(line)
+			instruction += lineContinuation.ReplaceAllString(line, "")
+		}
+
+		res, err := parser.Parse(strings.NewReader(instruction))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse docker command at line %d", startLine)
+		}
+		if len(res.AST.Children) == 0 {
+			continue
+		}
+		cmd := res.AST.Children[0].Value
+
+		if heredocInstructions[cmd] {
+			for _, field := range strings.Fields(instruction) {
+				match := heredocMarker.FindStringSubmatch(field)
+				if match == nil {
+					continue
+				}
+				body, err := readHeredoc(scan, match[2], match[1] == "-")
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to parse docker command at line %d", startLine)
+				}
+				lines = append(lines, body...)
+			}
+		}
+
+		if cmd == "from" {
+			dockerfile = append(dockerfile, '\n')
+		}
+		for _, comment := range comments {
+			dockerfile = append(dockerfile, comment+"\n"...)
+		}
+		comments = nil
+		dockerfile = append(dockerfile, strings.Join(lines, "\n")+"\n"...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, comment := range comments {
+		dockerfile = append(dockerfile, comment+"\n"...)
+	}
+	return dockerfile, nil
+}
+
+// addParserDirective adds a parser directive to the directives of a generated Dockerfile, keyed by its lower
+// case name. Docker commands setting the same directive to different values can't share a Dockerfile.
+func addParserDirective(directives map[string]string, name string, value string) error {
+	name = strings.ToLower(name)
+	if current, ok := directives[name]; ok && current != value {
+		return errors.Errorf("conflicting %s parser directives %s and %s, docker commands of a build step must use the same parser directives", name, current, value)
+	}
+	directives[name] = value
+	return nil
+}
+
+// parserDirectivesHeader returns the parser directives at the top of a generated Dockerfile, sorted by name
+func parserDirectivesHeader(directives map[string]string) []byte {
+	var names []string
+	for name := range directives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var header []byte
+	for _, name := range names {
+		header = append(header, fmt.Sprintf("# %s=%s\n", name, directives[name])...)
+	}
+	return header
+}
+
+// readHeredoc reads the lines of a heredoc up to and including its terminating line. Leading tabs are removed
+// from the terminating line of heredocs started with <<-.
+func readHeredoc(scan func() (string, bool), name string, stripTabs bool) ([]string, error) {
+	var lines []string
+	for {
+		line, ok := scan()
+		if !ok {
+			return nil, errors.Errorf("heredoc %s is not terminated", name)
+		}
+		lines = append(lines, line)
+		if stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+		if line == name {
+			return lines, nil
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	// distrolessBuildStage is the name given to an unnamed last stage of a distroless build step
+	distrolessBuildStage = "ocib-distroless-build"
+	// distrolessCollectStage is the stage which collects the entrypoint binary and its dynamic libraries
+	distrolessCollectStage = "ocib-distroless-collect"
+	// distrolessCollectDirectory is the directory the binary and libraries are collected into
+	distrolessCollectDirectory = "/ocib-distroless"
+)
+
+// shellMetacharacters are the characters of a shell form instruction which require a shell to run it
+const shellMetacharacters = "$`\\\"'|&;<>()*?[]{}~#!\n"
+
+// stageInstructions are the instructions of a stage which are carried over to the distroless stage
+type stageInstructions struct {
+	// config are the ENV, WORKDIR, LABEL and USER instructions in the order they are declared
+	config     []string
+	entrypoint *parser.Node
+	cmd        *parser.Node
+}
+
+// parseDistrolessStage generates the final distroless stage of a build step from the Dockerfile of its stages.
+// The declared paths, or the executable and its dynamic libraries, are copied from the last stage into the
+// distroless base along with the ENV, WORKDIR, LABEL, USER, ENTRYPOINT and CMD instructions of the last stage.
+// The executable is the binary of the ENTRYPOINT of the last stage, or of its CMD without an ENTRYPOINT.
+// Distroless images have no shell, so shell form ENTRYPOINT and CMD instructions are converted to the exec form.
+func parseDistrolessStage(config *v1alpha1.DistrolessConfig, dockerfile []byte, lastStage string, stageLabels map[string]string) ([]byte, error) {
+	instructions, err := parseLastStageInstructions(dockerfile)
+	if err != nil {
+		return nil, err
+	}
+
+	base := v1alpha1.Base{Image: common.DefaultDistrolessImage}
+	var paths []string
+	if config != nil {
+		if config.Base.Image != "" {
+			base = config.Base
+		}
+		paths = config.Paths
+	}
+
+	var entrypoint, cmd string
+	if instructions.entrypoint != nil {
+		if entrypoint, err = execForm(instructions.entrypoint); err != nil {
+			return nil, err
+		}
+	}
+	if instructions.cmd != nil {
+		if cmd, err = execForm(instructions.cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	var stage []byte
+	var copies []byte
+	if len(paths) == 0 {
+		binary := executable(instructions.entrypoint)
+		if instructions.entrypoint == nil {
+			binary = executable(instructions.cmd)
+		}
+		if binary == "" {
+			return nil, errors.New("distroless build steps require paths or an entrypoint in the last stage")
+		}
+		stage = append(stage, fmt.Sprintf("\n\nFROM %s AS %s\n", lastStage, distrolessCollectStage)...)
+		stage = append(stage, collectBinary(binary)...)
+		copies = append(copies, fmt.Sprintf("COPY --from=%s %s/ /\n", distrolessCollectStage, distrolessCollectDirectory)...)
+	}
+	for _, path := range paths {
+		copies = append(copies, fmt.Sprintf("COPY --from=%s %s %s\n", lastStage, path, path)...)
+	}
+
+	stage = append(stage, fmt.Sprintf("\n\n%s", parseBaseImage(base, ""))...)
+	stage = append(stage, copies...)
+	stageLabel := strings.TrimSpace(parseLabels(stageLabels))
+	for _, instruction := range instructions.config {
+		// the stage labels of the last stage are applied once, at the end of the distroless stage
+		if stageLabel != "" && instruction == stageLabel {
+			continue
+		}
+		stage = append(stage, fmt.Sprintf("%s\n", instruction)...)
+	}
+	if entrypoint != "" {
+		stage = append(stage, fmt.Sprintf("%s\n", entrypoint)...)
+	}
+	if cmd != "" {
+		stage = append(stage, fmt.Sprintf("%s\n", cmd)...)
+	}
+	stage = append(stage, parseLabels(stageLabels)...)
+	return stage, nil
+}
+
+// parseLastStageInstructions parses the instructions of the last stage of a Dockerfile which are carried over
+// to the distroless stage
+func parseLastStageInstructions(dockerfile []byte) (stageInstructions, error) {
+	res, err := parser.Parse(bytes.NewReader(dockerfile))
+	if err != nil {
+		return stageInstructions{}, err
+	}
+
+	var instructions stageInstructions
+	for _, child := range res.AST.Children {
+		switch child.Value {
+		case "from":
+			instructions = stageInstructions{}
+		case "entrypoint":
+			instructions.entrypoint = child
+		case "cmd":
+			instructions.cmd = child
+		case "env", "workdir", "label", "user":
+			instructions.config = append(instructions.config, child.Original)
+		}
+	}
+	return instructions, nil
+}
+
+// execForm returns an ENTRYPOINT or CMD instruction in the exec form, splitting the command of a shell form
+// instruction into its arguments. Shell form commands which rely on the shell e.g. variables, pipes or quoting
+// cannot be converted and return an error.
+func execForm(node *parser.Node) (string, error) {
+	if node.Attributes["json"] {
+		return node.Original, nil
+	}
+	instruction := strings.ToUpper(node.Value)
+	if node.Next == nil {
+		return instruction, nil
+	}
+	command := node.Next.Value
+	if strings.ContainsAny(command, shellMetacharacters) {
+		return "", errors.Errorf("%s of the last stage of a distroless build step requires a shell, use the exec form: %s", instruction, node.Original)
+	}
+	var args []string
+	for _, field := range strings.Fields(command) {
+		arg, err := json.Marshal(field)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, string(arg))
+	}
+	return fmt.Sprintf("%s [%s]", instruction, strings.Join(args, ", ")), nil
+}
+
+// executable returns the executable of an ENTRYPOINT or CMD instruction in either the exec or shell form
+func executable(node *parser.Node) string {
+	if node == nil || node.Next == nil {
+		return ""
+	}
+	if node.Attributes["json"] {
+		return node.Next.Value
+	}
+	fields := strings.Fields(node.Next.Value)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// collectBinary returns a RUN instruction copying a binary and the dynamic libraries it links against into the
+// collect directory, preserving their paths. The instruction only requires a POSIX shell and coreutils e.g.
+// busybox in the last stage, libraries are found with ldd and are not collected when ldd is not installed, so
+// dynamically linked binaries of stages without ldd must declare their libraries as paths.
+func collectBinary(binary string) string {
+	return fmt.Sprintf("RUN set -e; bin=$(command -v %q); libs=; "+
+		"if command -v ldd >/dev/null; then libs=$(ldd \"$bin\" 2>/dev/null | grep -o '/[^ ]*' || true); "+
+		"else echo \"ldd not found, dynamic libraries of $bin are not collected\" >&2; fi; "+
+		"for f in \"$bin\" $libs; do mkdir -p \"%s$(dirname \"$f\")\"; cp -L \"$f\" \"%s$f\"; done\n",
+		binary, distrolessCollectDirectory, distrolessCollectDirectory)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+const distrolessBuildDockerfile = "FROM golang:1.13 AS build\nLABEL stage=\"build\"\n\nFROM debian:buster AS final\nLABEL maintainer=\"ocibuilder\"\n" +
+	"ENV PORT=8080\nWORKDIR /app\nUSER nobody\nENTRYPOINT [\"/bin/app\", \"--serve\"]\nCMD [\"--port\", \"8080\"]\nLABEL build-id=\"1234\"\n"
+
+func TestParseDistrolessStage(t *testing.T) {
+	stage, err := parseDistrolessStage(nil, []byte(distrolessBuildDockerfile), "final", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "\n\nFROM final AS ocib-distroless-collect\n"+collectBinary("/bin/app")+
+		"\n\nFROM gcr.io/distroless/base\nCOPY --from=ocib-distroless-collect /ocib-distroless/ /\n"+
+		"LABEL maintainer=\"ocibuilder\"\nENV PORT=8080\nWORKDIR /app\nUSER nobody\nLABEL build-id=\"1234\"\n"+
+		"ENTRYPOINT [\"/bin/app\", \"--serve\"]\nCMD [\"--port\", \"8080\"]\n", string(stage))
+}
+
+func TestParseDistrolessStage_Paths(t *testing.T) {
+	config := &v1alpha1.DistrolessConfig{
+		Base:  v1alpha1.Base{Image: "scratch"},
+		Paths: []string{"/bin/app", "/etc/ssl/certs"},
+	}
+	stage, err := parseDistrolessStage(config, []byte(distrolessBuildDockerfile), "final", map[string]string{"build-id": "1234"})
+	assert.Equal(t, nil, err)
+	// the stage labels of the last stage are applied to the distroless stage once
+	assert.Equal(t, "\n\nFROM scratch\nCOPY --from=final /bin/app /bin/app\nCOPY --from=final /etc/ssl/certs /etc/ssl/certs\n"+
+		"LABEL maintainer=\"ocibuilder\"\nENV PORT=8080\nWORKDIR /app\nUSER nobody\n"+
+		"ENTRYPOINT [\"/bin/app\", \"--serve\"]\nCMD [\"--port\", \"8080\"]\nLABEL build-id=\"1234\"\n", string(stage))
+}
+
+func TestParseDistrolessStage_NoEntrypoint(t *testing.T) {
+	_, err := parseDistrolessStage(nil, []byte("FROM golang:1.13 AS build\nENTRYPOINT [\"/bin/app\"]\n\nFROM debian:buster\n"), "1", nil)
+	assert.Error(t, err)
+}
+
+func TestParseDistrolessStage_Cmd(t *testing.T) {
+	stage, err := parseDistrolessStage(nil, []byte("FROM debian:buster AS final\nCMD /bin/app --serve\n"), "final", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "\n\nFROM final AS ocib-distroless-collect\n"+collectBinary("/bin/app")+
+		"\n\nFROM gcr.io/distroless/base\nCOPY --from=ocib-distroless-collect /ocib-distroless/ /\n"+
+		"CMD [\"/bin/app\", \"--serve\"]\n", string(stage))
+}
+
+func TestParseDistrolessStage_ShellForm(t *testing.T) {
+	_, err := parseDistrolessStage(nil, []byte("FROM debian:buster AS final\nENTRYPOINT /bin/app --port $PORT\n"), "final", nil)
+	assert.Error(t, err)
+}
+
+func TestParseLastStageInstructions(t *testing.T) {
+	instructions, err := parseLastStageInstructions([]byte("FROM debian:buster\nENTRYPOINT /usr/local/bin/app --serve\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/usr/local/bin/app", executable(instructions.entrypoint))
+
+	entrypoint, err := execForm(instructions.entrypoint)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ENTRYPOINT [\"/usr/local/bin/app\", \"--serve\"]", entrypoint)
+}
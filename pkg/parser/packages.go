@@ -0,0 +1,157 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/validate"
+	"github.com/pkg/errors"
+)
+
+// distroPackageManagers are the package managers of distributions, matched against the words of the
+// name, tag and platform of a base image e.g. alpine in python:3.8-alpine3.12
+var distroPackageManagers = []struct {
+	distro  string
+	manager v1alpha1.PackageManager
+}{
+	{"alpine", v1alpha1.ApkPackageManager},
+	{"debian", v1alpha1.AptPackageManager},
+	{"ubuntu", v1alpha1.AptPackageManager},
+	{"buster", v1alpha1.AptPackageManager},
+	{"bullseye", v1alpha1.AptPackageManager},
+	{"stretch", v1alpha1.AptPackageManager},
+	{"xenial", v1alpha1.AptPackageManager},
+	{"bionic", v1alpha1.AptPackageManager},
+	{"focal", v1alpha1.AptPackageManager},
+	{"slim", v1alpha1.AptPackageManager},
+	{"fedora", v1alpha1.DnfPackageManager},
+	{"rockylinux", v1alpha1.DnfPackageManager},
+	{"almalinux", v1alpha1.DnfPackageManager},
+	{"centos", v1alpha1.YumPackageManager},
+	{"rhel", v1alpha1.YumPackageManager},
+	{"ubi", v1alpha1.YumPackageManager},
+	{"amazonlinux", v1alpha1.YumPackageManager},
+	{"oraclelinux", v1alpha1.YumPackageManager},
+}
+
+// debianImages are official images whose default variant is based on Debian
+var debianImages = map[string]bool{
+	"buildpack-deps": true,
+	"golang":         true,
+	"node":           true,
+	"openjdk":        true,
+	"perl":           true,
+	"php":            true,
+	"python":         true,
+	"ruby":           true,
+	"rust":           true,
+}
+
+// imageWord splits the name, tag and platform of an image into words
+var imageWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ParsePackagesStep generates a single RUN instruction installing the packages of a packages step,
+// cleaning up the package manager cache in the same layer. The auto package manager is detected
+// from the base image of the stage.
+func ParsePackagesStep(step *v1alpha1.PackagesStep, base v1alpha1.Base) ([]byte, error) {
+	if err := validate.ValidatePackagesStep(*step); err != nil {
+		return nil, err
+	}
+
+	manager := step.Manager
+	if manager == "" || manager == v1alpha1.AutoPackageManager {
+		detected, err := detectPackageManager(base)
+		if err != nil {
+			return nil, err
+		}
+		manager = detected
+	}
+
+	var packages []string
+	for _, pkg := range step.Packages {
+		packages = append(packages, packageSpec(manager, pkg))
+	}
+	install := strings.Join(packages, " ")
+
+	var cmds []string
+	switch manager {
+	case v1alpha1.AptPackageManager:
+		cmds = []string{
+			"apt-get update",
+			"DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends " + install,
+			"rm -rf /var/lib/apt/lists/*",
+		}
+	case v1alpha1.ApkPackageManager:
+		cmds = []string{"apk add --no-cache " + install}
+	case v1alpha1.YumPackageManager:
+		cmds = []string{
+			"yum install -y " + install,
+			"yum clean all",
+			"rm -rf /var/cache/yum",
+		}
+	case v1alpha1.DnfPackageManager:
+		cmds = []string{
+			"dnf install -y --setopt=install_weak_deps=False " + install,
+			"dnf clean all",
+			"rm -rf /var/cache/dnf",
+		}
+	}
+	return []byte(fmt.Sprintf("RUN %s\n", strings.Join(cmds, " \\\n    && "))), nil
+}
+
+// packageSpec returns a package pinned to its version in the format of the package manager
+func packageSpec(manager v1alpha1.PackageManager, pkg v1alpha1.Package) string {
+	if pkg.Version == "" {
+		return pkg.Name
+	}
+	if manager == v1alpha1.YumPackageManager || manager == v1alpha1.DnfPackageManager {
+		return fmt.Sprintf("%s-%s", pkg.Name, pkg.Version)
+	}
+	return fmt.Sprintf("%s=%s", pkg.Name, pkg.Version)
+}
+
+// detectPackageManager detects the package manager of a base image from the distribution in its
+// tag or platform e.g. golang:1.13-alpine, or from its name e.g. ubuntu
+func detectPackageManager(base v1alpha1.Base) (v1alpha1.PackageManager, error) {
+	image := strings.ToLower(base.Image)
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image = image[:idx]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	variant := strings.ToLower(base.Tag + " " + base.Platform)
+	if idx := strings.Index(name, ":"); idx != -1 {
+		name, variant = name[:idx], name[idx+1:]+" "+variant
+	}
+
+	for _, words := range [][]string{imageWord.Split(variant, -1), imageWord.Split(name, -1)} {
+		for _, word := range words {
+			for _, distro := range distroPackageManagers {
+				if strings.HasPrefix(word, distro.distro) {
+					return distro.manager, nil
+				}
+			}
+		}
+	}
+	if debianImages[name] {
+		return v1alpha1.AptPackageManager, nil
+	}
+	return "", errors.Errorf("unable to detect the package manager of base image %s, set the manager of the packages step", base.Image)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPackages = []v1alpha1.Package{{Name: "curl"}, {Name: "git", Version: "1:2.20.1-2"}}
+
+func TestParsePackagesStep(t *testing.T) {
+	step := &v1alpha1.PackagesStep{Manager: v1alpha1.AptPackageManager, Packages: testPackages}
+	cmds, err := ParsePackagesStep(step, v1alpha1.Base{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "RUN apt-get update \\\n"+
+		"    && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends curl git=1:2.20.1-2 \\\n"+
+		"    && rm -rf /var/lib/apt/lists/*\n", string(cmds))
+
+	step = &v1alpha1.PackagesStep{Manager: v1alpha1.DnfPackageManager, Packages: []v1alpha1.Package{{Name: "git", Version: "2.26.2"}}}
+	cmds, err = ParsePackagesStep(step, v1alpha1.Base{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "RUN dnf install -y --setopt=install_weak_deps=False git-2.26.2 \\\n"+
+		"    && dnf clean all \\\n"+
+		"    && rm -rf /var/cache/dnf\n", string(cmds))
+}
+
+func TestParsePackagesStep_Auto(t *testing.T) {
+	step := &v1alpha1.PackagesStep{Packages: []v1alpha1.Package{{Name: "curl"}}}
+	cmds, err := ParsePackagesStep(step, v1alpha1.Base{Image: "golang", Tag: "1.13-alpine3.12"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "RUN apk add --no-cache curl\n", string(cmds))
+
+	_, err = ParsePackagesStep(step, v1alpha1.Base{Image: "scratch"})
+	assert.Error(t, err)
+}
+
+func TestParsePackagesStep_Invalid(t *testing.T) {
+	step := &v1alpha1.PackagesStep{Manager: v1alpha1.AptPackageManager, Packages: []v1alpha1.Package{{Name: "curl; rm -rf /"}}}
+	_, err := ParsePackagesStep(step, v1alpha1.Base{})
+	assert.Error(t, err)
+
+	step = &v1alpha1.PackagesStep{Manager: "pacman", Packages: testPackages}
+	_, err = ParsePackagesStep(step, v1alpha1.Base{})
+	assert.Error(t, err)
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	bases := map[v1alpha1.Base]v1alpha1.PackageManager{
+		{Image: "alpine"}:                                 v1alpha1.ApkPackageManager,
+		{Image: "python", Tag: "3.8-slim"}:                v1alpha1.AptPackageManager,
+		{Image: "golang:1.13"}:                            v1alpha1.AptPackageManager,
+		{Image: "node:14-alpine"}:                         v1alpha1.ApkPackageManager,
+		{Image: "docker.io/library/ubuntu", Tag: "20.04"}: v1alpha1.AptPackageManager,
+		{Image: "registry.access.redhat.com/ubi8/ubi"}:    v1alpha1.YumPackageManager,
+		{Image: "fedora", Tag: "32"}:                      v1alpha1.DnfPackageManager,
+		{Image: "centos", Tag: "7"}:                       v1alpha1.YumPackageManager,
+	}
+	for base, expected := range bases {
+		manager, err := detectPackageManager(base)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expected, manager, base.Image)
+	}
+}
@@ -23,17 +23,19 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
 	"text/template"
 
 	"github.com/gobuffalo/packr"
-	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/google/uuid"
 	"github.com/ocibuilder/ocibuilder/ocictl/pkg/utils"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/common"
 	"github.com/ocibuilder/ocibuilder/pkg/context"
 	"github.com/ocibuilder/ocibuilder/pkg/request"
+	"github.com/ocibuilder/ocibuilder/pkg/tag"
 	"github.com/ocibuilder/ocibuilder/pkg/util"
 	"github.com/ocibuilder/ocibuilder/pkg/validate"
 	"github.com/pkg/errors"
@@ -47,6 +49,10 @@ func ParseBuildSpec(spec *v1alpha1.BuildSpec) ([]v1alpha1.ImageBuildArgs, error)
 	if !ok {
 		kubeConfig = ""
 	}
+	templates, err := ImportTemplates(spec, kubeConfig)
+	if err != nil {
+		return nil, err
+	}
 	for _, step := range spec.Steps {
 
 		if err := validate.ValidateContext(step.BuildContext); err != nil {
@@ -63,7 +69,15 @@ func ParseBuildSpec(spec *v1alpha1.BuildSpec) ([]v1alpha1.ImageBuildArgs, error)
 		}
 		cleanOnKill(buildContextPath)
 
-		dockerfilePath, err := GenerateDockerfile(step, spec.Templates, buildContextPath+common.ContextDirectory)
+		// purged steps label every stage so that intermediate images can be pruned after the build
+		var buildID string
+		var stageLabels map[string]string
+		if step.Purge {
+			buildID = uuid.New().String()
+			stageLabels = map[string]string{common.LabelBuildID: buildID}
+		}
+
+		dockerfilePath, err := generateDockerfile(step, templates, buildContextPath+common.ContextDirectory, stageLabels)
 		// Perform cleanup of generated files if parse errors out
 		if err != nil {
 			for _, args := range imageBuilds {
@@ -81,9 +95,15 @@ func ParseBuildSpec(spec *v1alpha1.BuildSpec) ([]v1alpha1.ImageBuildArgs, error)
 			return nil, errors.Errorf("error attempting to inject Dockerfile - err: %s", err)
 		}
 
+		tags, err := ParseTags(step)
+		if err != nil {
+			return nil, err
+		}
+
 		imageBuild := v1alpha1.ImageBuildArgs{
 			Name:             step.Name,
-			Tag:              step.Tag,
+			Tag:              tags[0],
+			Tags:             tags,
 			Dockerfile:       filepath.Base(dockerfilePath),
 			Purge:            step.Purge,
 			BuildContextPath: buildContextPath,
@@ -92,18 +112,74 @@ func ParseBuildSpec(spec *v1alpha1.BuildSpec) ([]v1alpha1.ImageBuildArgs, error)
 			Source:           step.Source,
 			Cache:            step.Cache,
 			StorageDriver:    spec.StorageDriver,
+			Export:           step.Export,
+			BuildID:          buildID,
+			Test:             step.Test,
+			CacheRepo:        step.CacheRepo,
 		}
 		imageBuilds = append(imageBuilds, imageBuild)
 	}
 	return imageBuilds, nil
 }
 
+// ParseTags renders the tag templates of a build step, with the rendered primary tag first.
+func ParseTags(step v1alpha1.BuildStep) ([]string, error) {
+	vars, err := TagVariables(step)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := tag.RenderAll(step.Tag, step.Tags, vars)
+	if err != nil {
+		return nil, err
+	}
+	// an untagged build is left for the builder to default
+	if len(tags) == 0 {
+		tags = []string{step.Tag}
+	}
+	return tags, nil
+}
+
+// TagVariables reads the tag template variables of a build step from the git repository of its build context.
+// The repository of a git build context is the clone read by its build context reader.
+func TagVariables(step v1alpha1.BuildStep) (tag.Variables, error) {
+	repoPath := common.RemoteLocalDirectory
+	var gitContext *v1alpha1.GitContext
+	if step.BuildContext != nil {
+		if step.BuildContext.LocalContext != nil {
+			repoPath = step.BuildContext.LocalContext.ContextPath
+		}
+		if step.BuildContext.GitContext != nil {
+			repoPath = common.RemoteLocalDirectory + common.RemoteTempDirectory
+			gitContext = step.BuildContext.GitContext
+		}
+	}
+
+	vars, err := tag.NewVariables(repoPath, gitContext)
+	if err != nil {
+		return tag.Variables{}, errors.Wrap(err, "failed to read tag template variables")
+	}
+	return vars, nil
+}
+
 // GenerateDockerfile takes in a build steps and generates a Dockerfile
 // returns path to generated dockerfile
 func GenerateDockerfile(step v1alpha1.BuildStep, templates []v1alpha1.BuildTemplate, destination string) (string, error) {
+	return generateDockerfile(step, templates, destination, nil)
+}
+
+// generateDockerfile generates a Dockerfile for a build step, applying the stage labels at the end of every stage
+func generateDockerfile(step v1alpha1.BuildStep, templates []v1alpha1.BuildTemplate, destination string, stageLabels map[string]string) (string, error) {
 	var dockerfile []byte
+	var lastStage string
+	// parser directives of docker commands only apply at the top of the Dockerfile
+	directives := make(map[string]string)
 	for idx, stage := range step.Stages {
-		baseImage := parseBaseImage(stage.Base, stage.Name)
+		lastStage = stage.Name
+		if step.Distroless && idx == len(step.Stages)-1 && lastStage == "" {
+			lastStage = distrolessBuildStage
+		}
+		baseImage := parseBaseImage(stage.Base, lastStage)
 
 		if idx != 0 {
 			baseImage = fmt.Sprintf("\n\n%s", baseImage)
@@ -111,7 +187,7 @@ func GenerateDockerfile(step v1alpha1.BuildStep, templates []v1alpha1.BuildTempl
 		dockerfile = append(dockerfile, baseImage...)
 
 		// handles parsing of cmds in stage without a template
-		tmp, err := parseCmdType(stage.Cmd)
+		tmp, err := parseCmdType(stage.Cmd, stage.Base, directives)
 		if err != nil {
 			return "", err
 		}
@@ -119,15 +195,31 @@ func GenerateDockerfile(step v1alpha1.BuildStep, templates []v1alpha1.BuildTempl
 
 		// handles parsing of cmds in a stage from a template
 		for _, t := range templates {
-			if stage.Template == t.Name {
-				tmp, err := parseCmdType(t.Cmd)
+			if stage.Template.Name == t.Name {
+				cmds, err := renderTemplateInputs(t, stage.Template.With)
+				if err != nil {
+					return "", err
+				}
+				tmp, err := parseCmdType(cmds, stage.Base, directives)
 				if err != nil {
 					return "", err
 				}
 				dockerfile = append(dockerfile, tmp...)
 			}
 		}
+
+		// stage labels differ for every build, so are applied last to keep the layers of the stage cached
+		dockerfile = append(dockerfile, parseLabels(stageLabels)...)
+	}
+
+	if step.Distroless {
+		distroless, err := parseDistrolessStage(step.DistrolessConfig, dockerfile, lastStage, stageLabels)
+		if err != nil {
+			return "", err
+		}
+		dockerfile = append(dockerfile, distroless...)
 	}
+	dockerfile = append(parserDirectivesHeader(directives), dockerfile...)
 
 	file, err := ioutil.TempFile(destination, "Dockerfile")
 	if err != nil {
@@ -140,8 +232,11 @@ func GenerateDockerfile(step v1alpha1.BuildStep, templates []v1alpha1.BuildTempl
 }
 
 // parseCmdType goes through a list of possible commands and parses them
-// based on the request e.g. Docker/Ansible Path/Inline
-func parseCmdType(cmds []v1alpha1.BuildTemplateStep) ([]byte, error) {
+// based on the request e.g. Docker/Ansible/Packages Path/Inline. Commands are added to the
+// Dockerfile in the order they are declared, the commands of a docker step in the
+// order inline, path then url. The base image of the stage is used to detect the
+// package manager of packages steps. The parser directives of docker commands are added to directives.
+func parseCmdType(cmds []v1alpha1.BuildTemplateStep, base v1alpha1.Base, directives map[string]string) ([]byte, error) {
 	var dockerfile []byte
 	for _, cmd := range cmds {
 		if err := validate.ValidateBuildTemplateStep(cmd); err != nil {
@@ -153,21 +248,29 @@ func parseCmdType(cmds []v1alpha1.BuildTemplateStep) ([]byte, error) {
 			if err != nil {
 				return nil, err
 			}
-			dockerfile = append(dockerfile, tmp...)
+			dockerfile = appendCommands(dockerfile, tmp)
+		}
+
+		if cmd.Packages != nil {
+			tmp, err := ParsePackagesStep(cmd.Packages, base)
+			if err != nil {
+				return nil, err
+			}
+			dockerfile = appendCommands(dockerfile, tmp)
 		}
 
 		if cmd.Docker != nil {
 
 			if cmd.Docker.Inline != nil {
-				return append(dockerfile, strings.Join(cmd.Docker.Inline, "\n")...), nil
+				dockerfile = appendCommands(dockerfile, []byte(strings.Join(cmd.Docker.Inline, "\n")))
 			}
 
 			if cmd.Docker.Path != "" {
-				tmp, err := ParseDockerCommands(cmd.Docker.Path)
+				tmp, err := ParseDockerCommands(cmd.Docker.Path, directives)
 				if err != nil {
 					return nil, err
 				}
-				dockerfile = append(dockerfile, tmp...)
+				dockerfile = appendCommands(dockerfile, tmp)
 			}
 
 			if cmd.Docker.Url != "" {
@@ -175,11 +278,12 @@ func parseCmdType(cmds []v1alpha1.BuildTemplateStep) ([]byte, error) {
 					return nil, err
 				}
 
-				tmp, err := ParseDockerCommands(common.DockerStepPath)
+				tmp, err := ParseDockerCommands(common.DockerStepPath, directives)
+				os.Remove(common.DockerStepPath)
 				if err != nil {
 					return nil, err
 				}
-				dockerfile = append(dockerfile, tmp...)
+				dockerfile = appendCommands(dockerfile, tmp)
 			}
 
 		}
@@ -188,6 +292,82 @@ func parseCmdType(cmds []v1alpha1.BuildTemplateStep) ([]byte, error) {
 	return dockerfile, nil
 }
 
+// renderTemplateInputs renders the inline docker commands and the packages of a template as go templates,
+// substituting the values passed to the inputs of the template by a stage, or the defaults of inputs which
+// are not passed. Commands read from paths, urls and ansible steps are not rendered. The steps of templates
+// without inputs are returned as they are.
+func renderTemplateInputs(t v1alpha1.BuildTemplate, with map[string]string) ([]v1alpha1.BuildTemplateStep, error) {
+	if len(t.Inputs) == 0 && len(with) == 0 {
+		return t.Cmd, nil
+	}
+	if err := validate.ValidateTemplateInputs(t, with); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, input := range t.Inputs {
+		values[input.Name] = input.Default
+	}
+	for k, v := range with {
+		values[k] = v
+	}
+
+	render := func(text string) (string, error) {
+		cmdTemplate, err := template.New(t.Name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse commands of template %s", t.Name)
+		}
+		buf := &bytes.Buffer{}
+		if err := cmdTemplate.Execute(buf, values); err != nil {
+			return "", errors.Wrapf(err, "failed to render commands of template %s", t.Name)
+		}
+		return buf.String(), nil
+	}
+
+	var cmds []v1alpha1.BuildTemplateStep
+	for _, cmd := range t.Cmd {
+		cmd = *cmd.DeepCopy()
+		if cmd.Docker != nil && len(cmd.Docker.Inline) > 0 {
+			// inline commands are rendered together so that actions can span commands
+			inline, err := render(strings.Join(cmd.Docker.Inline, "\n"))
+			if err != nil {
+				return nil, err
+			}
+			cmd.Docker.Inline = []string{inline}
+		}
+		if cmd.Packages != nil {
+			// packages are rendered before the packages step is validated
+			for i := range cmd.Packages.Packages {
+				pkg := &cmd.Packages.Packages[i]
+				name, err := render(pkg.Name)
+				if err != nil {
+					return nil, err
+				}
+				version, err := render(pkg.Version)
+				if err != nil {
+					return nil, err
+				}
+				pkg.Name, pkg.Version = name, version
+			}
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// appendCommands appends commands to a dockerfile, ending the commands with a newline
+// so that the commands which follow start on a new line
+func appendCommands(dockerfile []byte, commands []byte) []byte {
+	if len(commands) == 0 {
+		return dockerfile
+	}
+	dockerfile = append(dockerfile, commands...)
+	if commands[len(commands)-1] != '\n' {
+		dockerfile = append(dockerfile, '\n')
+	}
+	return dockerfile
+}
+
 // ParseAnsibleCommands is used to parse ansible commands from the ansible step
 // and append the parsed template to Dockerfile
 func ParseAnsibleCommands(ansibleStep *v1alpha1.AnsibleStep) ([]byte, error) {
@@ -226,50 +406,22 @@ func ParseAnsibleCommands(ansibleStep *v1alpha1.AnsibleStep) ([]byte, error) {
 
 }
 
-// ParseDockerCommands parses the inputted docker commands and adds to dockerfile
-func ParseDockerCommands(dockerCmdFilepath string) ([]byte, error) {
-	var dockerfile []byte
+// ParseDockerCommands parses the inputted docker commands and adds to dockerfile,
+// keeping each command as it is written in the file. The parser directives of the
+// file are added to directives.
+func ParseDockerCommands(dockerCmdFilepath string, directives map[string]string) ([]byte, error) {
 	cmdFile, err := os.Open(dockerCmdFilepath)
+	if err != nil {
+		return nil, err
+	}
 
 	defer func() {
-		if r := recover(); r != nil {
-			util.Logger.Warnln("panic recovered to execute final cleanup", r)
-		}
 		if err := cmdFile.Close(); err != nil {
 			util.Logger.WithError(err).Errorln("error closing cmdFile")
 		}
 	}()
 
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := parser.Parse(cmdFile)
-	if err != nil {
-		return nil, err
-	}
-
-	var commands []v1alpha1.Command
-	for _, child := range res.AST.Children {
-		cmd := v1alpha1.Command{
-			Cmd:       child.Value,
-			Original:  child.Original,
-			StartLine: child.StartLine,
-			Flags:     child.Flags,
-		}
-		if child.Next != nil && len(child.Next.Children) > 0 {
-			cmd.SubCmd = child.Next.Children[0].Value
-			child = child.Next.Children[0]
-		}
-		cmd.IsJSON = child.Attributes["json"]
-		for n := child.Next; n != nil; n = n.Next {
-			cmd.Value = append(cmd.Value, n.Value)
-		}
-		commands = append(commands, cmd)
-	}
-
-	return addCommandsToDockerfile(commands, dockerfile), nil
-
+	return readDockerCommands(cmdFile, directives)
 }
 
 // parseBaseImage parses the base image specification to include image, platform
@@ -288,17 +440,22 @@ func parseBaseImage(base v1alpha1.Base, name string) string {
 	return fmt.Sprintf("%s\n", baseImage)
 }
 
-// addCommandsToDockerfile is used to append commands to dockerfile
-func addCommandsToDockerfile(commands []v1alpha1.Command, dockerfile []byte) []byte {
-	for _, command := range commands {
-		cmd := command.Cmd
-		if cmd == "from" {
-			cmd = "\n" + cmd
-		}
-		line := strings.ToUpper(cmd) + " " + strings.Join(command.Value, " ") + "\n"
-		dockerfile = append(dockerfile, line...)
+// parseLabels parses labels into a single LABEL instruction, sorted by key
+func parseLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
 	}
-	return dockerfile
+	var keys []string
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+	return fmt.Sprintf("LABEL %s\n", strings.Join(pairs, " "))
 }
 
 func cleanOnKill(contextPath string) {
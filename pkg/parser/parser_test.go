@@ -17,8 +17,12 @@ limitations under the License.
 package parser
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/ghodss/yaml"
@@ -37,19 +41,81 @@ ENTRYPOINT ["/usr/local/bin/dumb-init", "-c", "--"]
 CMD ["/bin/sh", "-l"]
 `
 
-const expectedInlineDockerfile = "FROM go / java / nodejs / python:ubuntu_xenial:v1.0.0 AS first-stage\nADD ./ /test-path\nWORKDIR /test-dir\nENV PORT=3001\nCMD [\"go\", \"run\", \"main.go\"]\n\nFROM alpine:latest AS second-stage\nCMD [\"echo\", \"done\"]"
+// expectedDistrolessStage is the distroless stage of the distroless test build step, which collects the
+// executable of the CMD of its last stage
+var expectedDistrolessStage = "\n\nFROM second-stage AS ocib-distroless-collect\n" + collectBinary("echo") +
+	"\n\nFROM gcr.io/distroless/base\nCOPY --from=ocib-distroless-collect /ocib-distroless/ /\nCMD [\"echo\", \"done\"]\n"
 
-const expectedDockerfile = "FROM go / java / nodejs / python:ubuntu_xenial:v1.0.0 AS first-stage\nRUN pip install kubernetes\nCOPY app/ /bin/app\n\n\nFROM alpine:latest AS second-stage\nCMD [\"echo\", \"done\"]"
+var expectedInlineDockerfile = "FROM go / java / nodejs / python:ubuntu_xenial:v1.0.0 AS first-stage\nADD ./ /test-path\nWORKDIR /test-dir\nENV PORT=3001\nCMD [\"go\", \"run\", \"main.go\"]\n\n\nFROM alpine:latest AS second-stage\nCMD [\"echo\", \"done\"]\n" +
+	expectedDistrolessStage
+
+var expectedDockerfile = "FROM go / java / nodejs / python:ubuntu_xenial:v1.0.0 AS first-stage\nRUN pip install kubernetes\nCOPY app/ /bin/app\n\n\nFROM alpine:latest AS second-stage\nCMD [\"echo\", \"done\"]\n" +
+	expectedDistrolessStage
 
 func TestParseDockerCommands(t *testing.T) {
 	path := "../../testing/dummy/commands_basic_parser_test.txt"
-	dockerfile, err := ParseDockerCommands(path)
+	dockerfile, err := ParseDockerCommands(path, make(map[string]string))
 	expectedDockerfile := "RUN pip install kubernetes\nCOPY app/ /bin/app\n"
 
 	assert.Equal(t, nil, err)
 	assert.Equal(t, expectedDockerfile, string(dockerfile))
 }
 
+func TestParseDockerCommands_RoundTrip(t *testing.T) {
+	path := "../../testing/dummy/commands_round_trip_parser_test.txt"
+	directives := make(map[string]string)
+	dockerfile, err := ParseDockerCommands(path, directives)
+	expectedDockerfile := "\n# build the application\n" +
+		"FROM golang:1.15 AS builder\n" +
+		"COPY --chown=1000:1000 . /src\n" +
+		"RUN cd /src && \\\n    # build a static binary\n    CGO_ENABLED=0 go build -o /bin/app\n" +
+		"\nFROM alpine:3.12\n" +
+		"COPY --from=builder --chown=1000 /bin/app /bin/app\n" +
+		"RUN <<EOF\napk add --no-cache ca-certificates\nadduser -D app\nEOF\n" +
+		"USER app\n" +
+		"ENTRYPOINT [\"/bin/app\", \"--port\", \"8080\"]\n" +
+		"# end of commands\n"
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDockerfile, string(dockerfile))
+	assert.Equal(t, map[string]string{"syntax": "docker/dockerfile:1.4", "escape": "\\"}, directives)
+}
+
+func TestReadDockerCommands_Errors(t *testing.T) {
+	_, err := readDockerCommands(strings.NewReader("# escape=`\nRUN echo hello"), make(map[string]string))
+	assert.Error(t, err)
+
+	_, err = readDockerCommands(strings.NewReader("RUN <<EOF\necho hello\n"), make(map[string]string))
+	assert.Error(t, err)
+
+	directives := map[string]string{"syntax": "docker/dockerfile:1.4"}
+	_, err = readDockerCommands(strings.NewReader("# syntax=docker/dockerfile:1.2\nRUN echo hello"), directives)
+	assert.Error(t, err)
+}
+
+func TestGenerateDockerfile_ParserDirectives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-dockerfile")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	step := v1alpha1.BuildStep{
+		Stages: []v1alpha1.Stage{{
+			ImageMetadata: &v1alpha1.ImageMetadata{Name: "build"},
+			Base:          v1alpha1.Base{Image: "alpine"},
+			Cmd: []v1alpha1.BuildTemplateStep{
+				{Docker: &v1alpha1.DockerStep{Inline: []string{"RUN echo hello"}}},
+				{Docker: &v1alpha1.DockerStep{Path: "../../testing/dummy/commands_round_trip_parser_test.txt"}},
+			},
+		}},
+	}
+	path, err := GenerateDockerfile(step, nil, dir)
+	assert.Equal(t, nil, err)
+	dockerfile, err := ioutil.ReadFile(path)
+	assert.Equal(t, nil, err)
+	assert.True(t, strings.HasPrefix(string(dockerfile), "# escape=\\\n# syntax=docker/dockerfile:1.4\nFROM alpine AS build\nRUN echo hello\n"))
+	assert.Equal(t, 1, strings.Count(string(dockerfile), "# syntax="))
+}
+
 func TestGenerateDockerfile(t *testing.T) {
 	file, err := ioutil.ReadFile("../../testing/dummy/build.yaml")
 	assert.Equal(t, nil, err)
@@ -119,6 +185,178 @@ func TestGenerateDockerfileInline(t *testing.T) {
 	assert.Equal(t, expectedInlineDockerfile, string(dockerfile))
 }
 
+const templateInputsBuildSpec = `
+templates:
+  - name: go-build
+    inputs:
+      - name: goVersion
+        type: number
+        required: true
+      - name: binary
+        default: app
+    cmd:
+      - docker:
+          inline:
+            - RUN curl -sSL https://dl.google.com/go/go{{ .goVersion }}.linux-amd64.tar.gz | tar -C /usr/local -xz
+            - RUN go build -o /bin/{{ .binary }}
+steps:
+  - name: build
+    stages:
+      - metadata:
+          name: build
+        base:
+          image: alpine
+        template:
+          name: go-build
+          with:
+            goVersion: "1.13"
+      - metadata:
+          name: default
+        base:
+          image: alpine
+        template: go-build
+`
+
+func TestGenerateDockerfile_TemplateInputs(t *testing.T) {
+	buildSpecification := v1alpha1.BuildSpec{}
+	assert.Equal(t, nil, yaml.Unmarshal([]byte(templateInputsBuildSpec), &buildSpecification))
+	step := buildSpecification.Steps[0]
+	assert.Equal(t, v1alpha1.TemplateRef{Name: "go-build", With: map[string]string{"goVersion": "1.13"}}, step.Stages[0].Template)
+	assert.Equal(t, v1alpha1.TemplateRef{Name: "go-build"}, step.Stages[1].Template)
+
+	// the second stage does not pass the required go version
+	_, err := GenerateDockerfile(step, buildSpecification.Templates, "")
+	assert.Error(t, err)
+
+	step.Stages = step.Stages[:1]
+	path, err := GenerateDockerfile(step, buildSpecification.Templates, "")
+	assert.Equal(t, nil, err)
+	defer os.Remove(path)
+
+	dockerfile, err := ioutil.ReadFile(path)
+	assert.Equal(t, nil, err)
+	expectedDockerfile := "FROM alpine AS build\n" +
+		"RUN curl -sSL https://dl.google.com/go/go1.13.linux-amd64.tar.gz | tar -C /usr/local -xz\n" +
+		"RUN go build -o /bin/app\n"
+	assert.Equal(t, expectedDockerfile, string(dockerfile))
+}
+
+func TestGenerateDockerfile_StageLabels(t *testing.T) {
+	step := v1alpha1.BuildStep{
+		Stages: []v1alpha1.Stage{
+			{ImageMetadata: &v1alpha1.ImageMetadata{Name: "build"}, Base: v1alpha1.Base{Image: "golang", Tag: "1.13"}, Cmd: []v1alpha1.BuildTemplateStep{{Docker: &v1alpha1.DockerStep{Inline: []string{"RUN go build -o /bin/app"}}}}},
+			{ImageMetadata: &v1alpha1.ImageMetadata{}, Base: v1alpha1.Base{Image: "alpine"}, Cmd: []v1alpha1.BuildTemplateStep{{Docker: &v1alpha1.DockerStep{Inline: []string{"COPY --from=build /bin/app /bin/app"}}}}},
+		},
+	}
+	path, err := generateDockerfile(step, nil, "", map[string]string{"build-id": "1234"})
+	assert.Equal(t, nil, err)
+	defer os.Remove(path)
+
+	dockerfile, err := ioutil.ReadFile(path)
+	assert.Equal(t, nil, err)
+	// the label is the last instruction of each stage so that it does not invalidate the cache of the stage
+	expectedDockerfile := "FROM golang:1.13 AS build\nRUN go build -o /bin/app\nLABEL build-id=\"1234\"\n" +
+		"\n\nFROM alpine\nCOPY --from=build /bin/app /bin/app\nLABEL build-id=\"1234\"\n"
+	assert.Equal(t, expectedDockerfile, string(dockerfile))
+}
+
+func TestRenderTemplateInputs(t *testing.T) {
+	template := v1alpha1.BuildTemplate{
+		Name:   "go-build",
+		Inputs: []v1alpha1.TemplateInput{{Name: "goVersion", Type: v1alpha1.NumberTemplateInput}},
+		Cmd: []v1alpha1.BuildTemplateStep{
+			{Docker: &v1alpha1.DockerStep{
+				Inline: []string{"RUN go version | grep go{{ .goVersion }}"},
+				Path:   "../../testing/dummy/commands_basic_parser_test.txt",
+			}},
+			{Ansible: &v1alpha1.AnsibleStep{Workspace: "{{ .workspace }}"}},
+		},
+	}
+	cmds, err := renderTemplateInputs(template, map[string]string{"goVersion": "1.13"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"RUN go version | grep go1.13"}, cmds[0].Docker.Inline)
+	// paths and ansible steps are not rendered
+	assert.Equal(t, "../../testing/dummy/commands_basic_parser_test.txt", cmds[0].Docker.Path)
+	assert.Equal(t, "{{ .workspace }}", cmds[1].Ansible.Workspace)
+	assert.Equal(t, "RUN go version | grep go{{ .goVersion }}", template.Cmd[0].Docker.Inline[0])
+}
+
+func TestRenderTemplateInputs_Packages(t *testing.T) {
+	template := v1alpha1.BuildTemplate{
+		Name:   "go-build",
+		Inputs: []v1alpha1.TemplateInput{{Name: "goVersion", Type: v1alpha1.StringTemplateInput}},
+		Cmd: []v1alpha1.BuildTemplateStep{{Packages: &v1alpha1.PackagesStep{
+			Manager:  v1alpha1.ApkPackageManager,
+			Packages: []v1alpha1.Package{{Name: "go", Version: "{{ .goVersion }}"}, {Name: "git"}},
+		}}},
+	}
+	cmds, err := renderTemplateInputs(template, map[string]string{"goVersion": "1.13.15-r0"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []v1alpha1.Package{{Name: "go", Version: "1.13.15-r0"}, {Name: "git"}}, cmds[0].Packages.Packages)
+	assert.Equal(t, "{{ .goVersion }}", template.Cmd[0].Packages.Packages[0].Version)
+
+	// the rendered packages step is validated when parsed
+	dockerfile, err := parseCmdType(cmds, v1alpha1.Base{Image: "alpine"}, make(map[string]string))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "RUN apk add --no-cache go=1.13.15-r0 git\n", string(dockerfile))
+}
+
+func TestRenderTemplateInputs_Invalid(t *testing.T) {
+	template := v1alpha1.BuildTemplate{
+		Name:   "go-build",
+		Inputs: []v1alpha1.TemplateInput{{Name: "goVersion", Type: v1alpha1.NumberTemplateInput}},
+		Cmd:    []v1alpha1.BuildTemplateStep{{Docker: &v1alpha1.DockerStep{Inline: []string{"RUN go version | grep go{{ .goVersion }}"}}}},
+	}
+
+	_, err := renderTemplateInputs(template, map[string]string{"goVersion": "latest"})
+	assert.Error(t, err)
+	_, err = renderTemplateInputs(template, map[string]string{"version": "1.13"})
+	assert.Error(t, err)
+	template.Cmd[0].Docker.Inline = []string{"RUN echo {{ .other }}"}
+	_, err = renderTemplateInputs(template, nil)
+	assert.Error(t, err)
+}
+
+func TestTemplateRef_UnquotedDecimal(t *testing.T) {
+	var ref v1alpha1.TemplateRef
+	assert.Error(t, yaml.Unmarshal([]byte("name: go-build\nwith:\n  goVersion: 1.10\n"), &ref))
+	assert.Equal(t, nil, yaml.Unmarshal([]byte("name: go-build\nwith:\n  goVersion: \"1.10\"\n  retries: 3\n  debug: true\n"), &ref))
+	assert.Equal(t, map[string]string{"goVersion": "1.10", "retries": "3", "debug": "true"}, ref.With)
+}
+
+func TestParseCmdType_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "EXPOSE 8080")
+	}))
+	defer server.Close()
+
+	cmds := []v1alpha1.BuildTemplateStep{
+		{Docker: &v1alpha1.DockerStep{Inline: []string{"WORKDIR /app"}}},
+		{Docker: &v1alpha1.DockerStep{Url: server.URL}},
+		{Docker: &v1alpha1.DockerStep{
+			Inline: []string{"ENV PORT=8080"},
+			Path:   "../../testing/dummy/commands_basic_parser_test.txt",
+		}},
+		{Ansible: &v1alpha1.AnsibleStep{Workspace: "my-workspace"}},
+		{Docker: &v1alpha1.DockerStep{Inline: []string{"USER app", "CMD [\"/bin/app\"]"}}},
+	}
+	dockerfile, err := parseCmdType(cmds, v1alpha1.Base{Image: "alpine"}, make(map[string]string))
+	assert.Equal(t, nil, err)
+
+	expectedDockerfile := "WORKDIR /app\nEXPOSE 8080\nENV PORT=8080\nRUN pip install kubernetes\nCOPY app/ /bin/app\n" +
+		expectedAnsibleCommands + "USER app\nCMD [\"/bin/app\"]\n"
+	assert.Equal(t, expectedDockerfile, string(dockerfile))
+}
+
+func TestParseLabels(t *testing.T) {
+	labels := parseLabels(map[string]string{
+		"ocibuilder.io/build-id": "1234",
+		"maintainer":             "ocibuilder",
+	})
+	assert.Equal(t, "LABEL maintainer=\"ocibuilder\" ocibuilder.io/build-id=\"1234\"\n", labels)
+	assert.Equal(t, "", parseLabels(nil))
+}
+
 func TestParseAnsibleCommands(t *testing.T) {
 	ansibleStep := &v1alpha1.AnsibleStep{
 		Workspace: "my-workspace",
@@ -128,3 +366,30 @@ func TestParseAnsibleCommands(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, expectedAnsibleCommands, string(dockerfile))
 }
+
+func TestTagVariables(t *testing.T) {
+	step := v1alpha1.BuildStep{
+		BuildContext: &v1alpha1.BuildContext{
+			GitContext: &v1alpha1.GitContext{Branch: "feature", Tag: "v1.2.3"},
+		},
+	}
+	vars, err := TagVariables(step)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "feature", vars.Git.Branch)
+	assert.Equal(t, "v1.2.3", vars.Git.Tag)
+	assert.Equal(t, 1, vars.SemVer.Major)
+}
+
+func TestParseTags(t *testing.T) {
+	step := v1alpha1.BuildStep{
+		Tag:  "v0.1.0",
+		Tags: []string{"latest", "v0.1.0"},
+	}
+	tags, err := ParseTags(step)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"v0.1.0", "latest"}, tags)
+
+	tags, err = ParseTags(v1alpha1.BuildStep{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{""}, tags)
+}
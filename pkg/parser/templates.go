@@ -0,0 +1,208 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
+	"github.com/ocibuilder/ocibuilder/pkg/context"
+	"github.com/ocibuilder/ocibuilder/pkg/request"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/ocibuilder/ocibuilder/pkg/validate"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// templatesFile is a file of shared build templates
+type templatesFile struct {
+	Templates []v1alpha1.BuildTemplate `json:"templates"`
+}
+
+// ImportTemplates returns the templates of a build spec merged with the templates imported from its
+// template sources. Templates of the spec take precedence over imported templates with the same name,
+// and templates of a source take precedence over templates with the same name of the sources before it.
+func ImportTemplates(spec *v1alpha1.BuildSpec, k8sConfigPath string) ([]v1alpha1.BuildTemplate, error) {
+	if len(spec.TemplateSources) == 0 {
+		return spec.Templates, nil
+	}
+
+	var templates []v1alpha1.BuildTemplate
+	names := make(map[string]int)
+	merge := func(template v1alpha1.BuildTemplate, source string) {
+		idx, ok := names[template.Name]
+		if !ok {
+			names[template.Name] = len(templates)
+			templates = append(templates, template)
+			return
+		}
+		util.Logger.WithFields(logrus.Fields{"template": template.Name, "source": source}).Debugln("overriding imported template")
+		templates[idx] = template
+	}
+
+	for _, source := range spec.TemplateSources {
+		imported, err := readTemplateSource(source, k8sConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range imported {
+			merge(template, templateSourceName(source))
+		}
+	}
+	for _, template := range spec.Templates {
+		merge(template, "spec")
+	}
+	return templates, nil
+}
+
+// readTemplateSource reads the templates of a template source, fetching the templates file
+// when it is not cached
+func readTemplateSource(source v1alpha1.TemplateSource, k8sConfigPath string) ([]v1alpha1.BuildTemplate, error) {
+	if err := validate.ValidateTemplateSource(source); err != nil {
+		return nil, err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find the template cache directory")
+	}
+	cacheDir := filepath.Join(home, common.TemplateCacheDirectory)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create the template cache directory")
+	}
+
+	var path, dir string
+	if source.Git != nil {
+		dir = filepath.Join(cacheDir, templateSourceKey(source))
+		path, err = fetchGitTemplates(source, dir, k8sConfigPath)
+	} else {
+		path, err = fetchUrlTemplates(source, cacheDir)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch templates from %s", templateSourceName(source))
+	}
+
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read templates from %s", templateSourceName(source))
+	}
+	var templates templatesFile
+	if err := yaml.Unmarshal(file, &templates); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal templates from %s", templateSourceName(source))
+	}
+	if dir != "" {
+		resolveTemplatePaths(templates.Templates, dir)
+	}
+	return templates.Templates, nil
+}
+
+// resolveTemplatePaths resolves the relative paths of the docker commands of templates imported from a git
+// repository against the checkout of the repository, as the commands are files of the repository rather
+// than of the directory the build is run in
+func resolveTemplatePaths(templates []v1alpha1.BuildTemplate, dir string) {
+	for _, template := range templates {
+		for _, cmd := range template.Cmd {
+			if cmd.Docker != nil && cmd.Docker.Path != "" && !filepath.IsAbs(cmd.Docker.Path) {
+				cmd.Docker.Path = filepath.Join(dir, cmd.Docker.Path)
+			}
+		}
+	}
+}
+
+// fetchGitTemplates checks out the version of the git repository of a template source in a directory of the
+// cache and returns the path of its templates file. Repositories pinned to a tag or ref are only checked out
+// once, repositories on a branch are pulled for each build.
+func fetchGitTemplates(source v1alpha1.TemplateSource, dir string, k8sConfigPath string) (string, error) {
+	path := source.Path
+	if path == "" {
+		path = common.DefaultTemplatesPath
+	}
+	path = filepath.Join(dir, path)
+
+	_, err := os.Stat(dir)
+	cached := err == nil
+	pinned := source.Git.Tag != "" || source.Git.Ref != ""
+	if cached && pinned && !source.Refresh {
+		return path, nil
+	}
+
+	k8sClient, err := context.NewK8sClient(k8sConfigPath)
+	if err != nil {
+		return "", err
+	}
+	util.Logger.WithFields(logrus.Fields{"source": templateSourceName(source), "path": path}).Infoln("importing templates from git")
+	if err := context.NewGitBuildContextReader(source.Git, k8sClient).Checkout(dir); err != nil {
+		// a failed clone must not be read as a cached version of the templates
+		if !cached {
+			if err := os.RemoveAll(dir); err != nil {
+				util.Logger.WithError(err).Errorln("error cleaning up template cache")
+			}
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// fetchUrlTemplates downloads the templates file of a template source to the cache directory
+// when it is not cached and returns the path of the templates file
+func fetchUrlTemplates(source v1alpha1.TemplateSource, cacheDir string) (string, error) {
+	path := filepath.Join(cacheDir, templateSourceKey(source)+".yaml")
+	if _, err := os.Stat(path); err == nil && !source.Refresh {
+		return path, nil
+	}
+
+	// the templates are downloaded next to the cache so that a failed download is never cached
+	util.Logger.WithField("source", templateSourceName(source)).Infoln("importing templates from url")
+	download := path + ".download"
+	defer os.Remove(download)
+	if err := request.RequestRemote(source.Url, download, source.Auth); err != nil {
+		return "", err
+	}
+	if err := os.Rename(download, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// templateSourceKey returns the key of the version of a template source in the cache
+func templateSourceKey(source v1alpha1.TemplateSource) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(templateSourceName(source))))
+}
+
+// templateSourceName returns the url of a template source, including the branch, tag or ref
+// of git template sources
+func templateSourceName(source v1alpha1.TemplateSource) string {
+	if source.Git == nil {
+		return source.Url
+	}
+	version := context.DefaultBranch
+	switch {
+	case source.Git.Ref != "":
+		version = source.Git.Ref
+	case source.Git.Tag != "":
+		version = source.Git.Tag
+	case source.Git.Branch != "":
+		version = source.Git.Branch
+	}
+	return fmt.Sprintf("%s@%s", source.Git.URL, version)
+}
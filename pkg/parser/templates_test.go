@@ -0,0 +1,124 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+const sharedTemplates = `
+templates:
+  - name: go-build
+    cmd:
+      - docker:
+          inline:
+            - RUN go build -o /bin/app
+  - name: node-build
+    cmd:
+      - docker:
+          inline:
+            - RUN npm ci
+`
+
+func TestImportTemplates(t *testing.T) {
+	home, err := ioutil.TempDir("", "home")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, sharedTemplates)
+	}))
+	defer server.Close()
+
+	localTemplate := v1alpha1.BuildTemplate{
+		Name: "go-build",
+		Cmd:  []v1alpha1.BuildTemplateStep{{Docker: &v1alpha1.DockerStep{Inline: []string{"RUN make"}}}},
+	}
+	spec := &v1alpha1.BuildSpec{
+		Templates:       []v1alpha1.BuildTemplate{localTemplate},
+		TemplateSources: []v1alpha1.TemplateSource{{Url: server.URL + "/v1.0.0/templates.yaml"}},
+	}
+
+	templates, err := ImportTemplates(spec, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(templates))
+	// the local template takes precedence over the imported template with the same name
+	assert.Equal(t, localTemplate, templates[0])
+	assert.Equal(t, "node-build", templates[1].Name)
+	assert.Equal(t, 1, requests)
+
+	// cached templates are read without fetching them again
+	_, err = ImportTemplates(spec, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, requests)
+
+	spec.TemplateSources[0].Refresh = true
+	_, err = ImportTemplates(spec, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, requests)
+}
+
+func TestImportTemplates_Invalid(t *testing.T) {
+	spec := &v1alpha1.BuildSpec{
+		TemplateSources: []v1alpha1.TemplateSource{{Path: "templates.yaml"}},
+	}
+	_, err := ImportTemplates(spec, "")
+	assert.Error(t, err)
+
+	spec.TemplateSources[0] = v1alpha1.TemplateSource{
+		Git:  &v1alpha1.GitContext{URL: "https://github.com/ocibuilder/templates.git"},
+		Path: "../templates.yaml",
+	}
+	_, err = ImportTemplates(spec, "")
+	assert.Error(t, err)
+}
+
+func TestResolveTemplatePaths(t *testing.T) {
+	templates := []v1alpha1.BuildTemplate{{
+		Name: "go-build",
+		Cmd: []v1alpha1.BuildTemplateStep{
+			{Docker: &v1alpha1.DockerStep{Path: "go/commands.txt"}},
+			{Docker: &v1alpha1.DockerStep{Path: "/etc/commands.txt"}},
+			{Docker: &v1alpha1.DockerStep{Inline: []string{"RUN go build"}}},
+		},
+	}}
+	resolveTemplatePaths(templates, "/cache/templates")
+	assert.Equal(t, "/cache/templates/go/commands.txt", templates[0].Cmd[0].Docker.Path)
+	assert.Equal(t, "/etc/commands.txt", templates[0].Cmd[1].Docker.Path)
+	assert.Equal(t, "", templates[0].Cmd[2].Docker.Path)
+}
+
+func TestTemplateSourceName(t *testing.T) {
+	source := v1alpha1.TemplateSource{Git: &v1alpha1.GitContext{URL: "https://github.com/ocibuilder/templates.git"}}
+	assert.Equal(t, "https://github.com/ocibuilder/templates.git@master", templateSourceName(source))
+
+	source.Git.Tag = "v1.0.0"
+	assert.Equal(t, "https://github.com/ocibuilder/templates.git@v1.0.0", templateSourceName(source))
+	assert.NotEqual(t, templateSourceKey(source), templateSourceKey(v1alpha1.TemplateSource{Url: "https://github.com/ocibuilder/templates.git"}))
+}
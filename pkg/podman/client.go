@@ -0,0 +1,431 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Client is the client used for building with Podman using the ocibuilder
+type Client struct {
+	Logger *logrus.Logger
+}
+
+// saveFormats maps export formats to the formats of podman save
+var saveFormats = map[v1alpha1.ExportFormat]string{
+	v1alpha1.OCIExportFormat:           "oci-dir",
+	v1alpha1.OCIArchiveExportFormat:    "oci-archive",
+	v1alpha1.DockerArchiveExportFormat: "docker-archive",
+}
+
+// historyItem is an entry of the json output of podman history
+type historyItem struct {
+	ID        string    `json:"id"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"createdBy"`
+	Size      int64     `json:"size"`
+	Comment   string    `json:"comment"`
+	Tags      []string  `json:"tags"`
+}
+
+// ImageBuild conducts an image build with Podman using the ocibuilder
+func (cli Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
+
+	buildFlags := []command.Flag{
+		{Name: "f", Value: options.Dockerfile, Short: true, OmitEmpty: true},
+		{Name: "storage-driver", Value: options.StorageDriver, Short: false, OmitEmpty: true},
+	}
+
+	for _, t := range options.Tags {
+		buildFlags = append(buildFlags, command.Flag{Name: "t", Value: t, Short: true, OmitEmpty: true})
+	}
+
+	if options.NoCache {
+		buildFlags = append(buildFlags, command.Flag{Name: "no-cache", Value: "", Short: false, OmitEmpty: false})
+	}
+
+	var labelKeys []string
+	for k := range options.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		buildFlags = append(buildFlags, command.Flag{Name: "label", Value: fmt.Sprintf("%s=%s", k, options.Labels[k]), Short: false, OmitEmpty: true})
+	}
+
+	cmd := command.Builder("podman").Command("build").Flags(buildFlags...).Args(options.ContextPath).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing build with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error building image...")
+		return v1alpha1.OCIBuildResponse{}, err
+	}
+	return v1alpha1.OCIBuildResponse{
+		ImageBuildResponse: types.ImageBuildResponse{
+			Body: stdout,
+		},
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImagePull conducts an image pull with Podman using the ocibuilder
+func (cli Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullResponse, error) {
+
+	authFile, tempFiles, err := writeAuthFile(options.Ref, options.RegistryAuth)
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+
+	pullFlags := []command.Flag{
+		{Name: "authfile", Value: authFile, Short: false, OmitEmpty: true},
+		{Name: "platform", Value: options.Platform, Short: false, OmitEmpty: true},
+	}
+
+	cmd := command.Builder("podman").Command("pull").Flags(pullFlags...).Args(options.Ref).TempFiles(tempFiles...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing pull with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error pulling image...")
+		return v1alpha1.OCIPullResponse{}, err
+	}
+	return v1alpha1.OCIPullResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImagePush conducts an image push with Podman using the ocibuilder
+func (cli Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
+
+	authFile, tempFiles, err := writeAuthFile(options.Ref, options.RegistryAuth)
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+
+	pushFlags := []command.Flag{
+		{Name: "authfile", Value: authFile, Short: false, OmitEmpty: true},
+		{Name: "digestfile", Value: options.DigestFile, Short: false, OmitEmpty: true},
+	}
+
+	cmd := command.Builder("podman").Command("push").Flags(pushFlags...).Args(options.Ref).TempFiles(tempFiles...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing push with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error pushing image...")
+		return v1alpha1.OCIPushResponse{}, err
+	}
+	return v1alpha1.OCIPushResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImageRemove conducts an image remove with Podman using the ocibuilder
+func (cli Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
+
+	cmd := command.Builder("podman").Command("rmi").Args(options.Image).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing remove with command")
+
+	_, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error removing image...")
+		return v1alpha1.OCIRemoveResponse{}, err
+	}
+	return v1alpha1.OCIRemoveResponse{
+		Response: []types.ImageDeleteResponseItem{
+			{
+				Deleted: options.Image,
+			},
+		},
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImagesPrune removes dangling images with all the passed in labels with Podman using the ocibuilder
+func (cli Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+
+	// prune is a subcommand of podman image, so its flags are passed as arguments after the subcommand
+	pruneArgs := []string{"prune", "--force"}
+	for _, l := range options.Labels {
+		pruneArgs = append(pruneArgs, "--filter", "label="+l)
+	}
+
+	cmd := command.Builder("podman").Command("image").Args(pruneArgs...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing prune with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error pruning images...")
+		return v1alpha1.OCIPruneResponse{}, err
+	}
+	output, errOutput := command.ReadOutputs(stdout, stderr)
+
+	var deleted []types.ImageDeleteResponseItem
+	for _, id := range strings.Fields(string(output)) {
+		deleted = append(deleted, types.ImageDeleteResponseItem{Deleted: id})
+	}
+	return v1alpha1.OCIPruneResponse{
+		ImagesPruneReport: types.ImagesPruneReport{
+			ImagesDeleted: deleted,
+		},
+		Exec:   &cmd,
+		Stderr: ioutil.NopCloser(bytes.NewReader(errOutput)),
+	}, nil
+}
+
+// ImageTag tags an image with Podman using the ocibuilder
+func (cli Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+
+	cmd := command.Builder("podman").Command("tag").Args(options.Source, options.Target).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing tag with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error tagging image...")
+		return v1alpha1.OCITagResponse{}, err
+	}
+	return v1alpha1.OCITagResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImageInspect conducts an inspect of an image with Podman using the ocibuilder. The output of podman image
+// inspect is compatible with docker image inspect.
+func (cli Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+
+	cmd := command.Builder("podman").Command("image").Args("inspect", imageId).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing inspect with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error inspecting image...")
+		return types.ImageInspect{}, err
+	}
+	output, errOutput := command.ReadOutputs(stdout, stderr)
+	if err := wait(&cmd); err != nil {
+		return types.ImageInspect{}, errors.Wrapf(err, "failed to inspect image %s: %s", imageId, strings.TrimSpace(string(errOutput)))
+	}
+
+	var imageInspects []types.ImageInspect
+	if err := json.Unmarshal(output, &imageInspects); err != nil {
+		return types.ImageInspect{}, err
+	}
+	if len(imageInspects) == 0 {
+		return types.ImageInspect{}, errors.Errorf("image %s not found", imageId)
+	}
+	return imageInspects[0], nil
+}
+
+// ImageHistory conducts an image history call of an image with Podman using the ocibuilder
+func (cli Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
+
+	historyFlags := []command.Flag{
+		{Name: "format", Value: "json", Short: false, OmitEmpty: true},
+	}
+
+	cmd := command.Builder("podman").Command("history").Flags(historyFlags...).Args(imageId).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing history with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error fetching image history...")
+		return nil, err
+	}
+	output, errOutput := command.ReadOutputs(stdout, stderr)
+	if err := wait(&cmd); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch history of image %s: %s", imageId, strings.TrimSpace(string(errOutput)))
+	}
+
+	var items []historyItem
+	if err := json.Unmarshal(output, &items); err != nil {
+		return nil, err
+	}
+
+	var history []image.HistoryResponseItem
+	for _, item := range items {
+		history = append(history, image.HistoryResponseItem{
+			ID:        item.ID,
+			Created:   item.Created.Unix(),
+			CreatedBy: item.CreatedBy,
+			Size:      item.Size,
+			Comment:   item.Comment,
+			Tags:      item.Tags,
+		})
+	}
+	return history, nil
+}
+
+// ImageSave exports an image to the local filesystem with Podman using the ocibuilder
+func (cli Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+
+	format, ok := saveFormats[options.Format]
+	if !ok {
+		return v1alpha1.OCISaveResponse{}, errors.Errorf("unsupported export format %s", options.Format)
+	}
+
+	saveFlags := []command.Flag{
+		{Name: "format", Value: format, Short: false, OmitEmpty: true},
+		{Name: "o", Value: options.Dest, Short: true, OmitEmpty: true},
+	}
+
+	cmd := command.Builder("podman").Command("save").Flags(saveFlags...).Args(options.Image).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing export with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error exporting image...")
+		return v1alpha1.OCISaveResponse{}, err
+	}
+	return v1alpha1.OCISaveResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ImageLoad loads an image from the local filesystem into Podman storage using the ocibuilder
+func (cli Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+
+	if _, ok := saveFormats[options.Format]; !ok {
+		return v1alpha1.OCILoadResponse{}, errors.Errorf("unsupported load format %s", options.Format)
+	}
+
+	// Podman pull source in format transport:path
+	src := fmt.Sprintf("%s:%s", options.Format, options.Input)
+
+	cmd := command.Builder("podman").Command("pull").Args(src).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing load with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error loading image...")
+		return v1alpha1.OCILoadResponse{}, err
+	}
+	return v1alpha1.OCILoadResponse{
+		Body:   stdout,
+		Exec:   &cmd,
+		Stderr: stderr,
+	}, nil
+}
+
+// ContainerRun runs a command in a container of an image with Podman using the ocibuilder, removing the container
+// once the command has exited
+func (cli Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	if len(options.Cmd) == 0 {
+		return v1alpha1.OCIRunResponse{}, errors.New("no command specified to run")
+	}
+
+	runFlags := []command.Flag{
+		{Name: "rm", Value: "", Short: false, OmitEmpty: false},
+		{Name: "entrypoint", Value: options.Cmd[0], Short: false, OmitEmpty: true},
+	}
+
+	cmd := command.Builder("podman").Command("run").Flags(runFlags...).Args(append([]string{options.Image}, options.Cmd[1:]...)...).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing run with command")
+
+	stdout, stderr, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error running command...")
+		return v1alpha1.OCIRunResponse{}, err
+	}
+	output, errOutput := command.ReadOutputs(stdout, stderr)
+
+	exitCode := 0
+	if err := wait(&cmd); err != nil {
+		// an exit code is only available if the command ran to completion
+		exitErr, ok := err.(*command.ExitError)
+		if !ok || exitErr.ExitCode < 0 {
+			return v1alpha1.OCIRunResponse{}, err
+		}
+		exitCode = exitErr.ExitCode
+	}
+	return v1alpha1.OCIRunResponse{
+		Body:     ioutil.NopCloser(bytes.NewReader(output)),
+		Stderr:   ioutil.NopCloser(bytes.NewReader(errOutput)),
+		ExitCode: exitCode,
+	}, nil
+}
+
+// RegistryLogin conducts a registry login with Podman using the ocibuilder
+func (cli Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
+
+	loginFlags := []command.Flag{
+		{Name: "u", Value: options.Username, Short: true, OmitEmpty: true},
+		{Name: "password-stdin", Value: "", Short: false, OmitEmpty: false},
+	}
+
+	// the password is passed on stdin to keep it out of process listings
+	cmd := command.Builder("podman").Command("login").Flags(loginFlags...).Args(options.ServerAddress).Stdin(strings.NewReader(options.Password)).Build()
+	cli.Logger.WithField("cmd", cmd).Debugln("executing login with command")
+
+	_, _, err := execute(&cmd)
+	if err != nil {
+		cli.Logger.WithError(err).Errorln("error logging in...")
+		return v1alpha1.OCILoginResponse{}, err
+	}
+
+	return v1alpha1.OCILoginResponse{
+		AuthenticateOKBody: registry.AuthenticateOKBody{
+			Status: "login completed",
+		},
+		Exec: &cmd,
+	}, nil
+}
+
+// GenerateAuthRegistryString generates the auth registry string for pushing and pulling images targeting Podman
+func (cli Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
+	return fmt.Sprintf("%s:%s", auth.Username, auth.Password)
+}
+
+// Capabilities returns the capabilities of Podman, which stores built images locally
+func (cli Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{LocalImages: true}
+}
+
+// execute executes the podman command. This function is mocked in podman client tests.
+var execute = (*command.Command).Exec
+
+// writeAuthFile writes registry auth for the registry of an image to a temporary auth file. This function is
+// mocked in podman client tests.
+var writeAuthFile = util.WriteCommandAuthFile
+
+// wait waits on an executed podman command. This function is mocked in podman client tests.
+var wait = (*command.Command).Wait
@@ -0,0 +1,342 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/command"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ImageBuild(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedBuildCommand, cmd)
+		return nil, nil, nil
+	}
+	_, err := cli.ImageBuild(ociBuildOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImagePull(t *testing.T) {
+	writeAuthFile = fakeWriteAuthFile
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedPullCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImagePull(ociPullOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImagePush(t *testing.T) {
+	writeAuthFile = fakeWriteAuthFile
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedPushCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImagePush(ociPushOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageRemove(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedRemoveCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageRemove(ociRemoveOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImagesPrune(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedPruneCommand, cmd)
+		return ioutil.NopCloser(strings.NewReader("1a2b3c4d\n5e6f7a8b\n")), nil, nil
+	}
+
+	res, err := cli.ImagesPrune(ociPruneOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(res.ImagesDeleted))
+	assert.Equal(t, "5e6f7a8b", res.ImagesDeleted[1].Deleted)
+}
+
+func TestClient_ImageTag(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedTagCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageTag(ociTagOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageInspect(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedInspectCommand, cmd)
+		return ioutil.NopCloser(strings.NewReader(`[{"Id":"1a2b3c4d","RepoTags":["image-name:v0.1.0"],"Size":1024}]`)), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ImageInspect("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1a2b3c4d", res.ID)
+	assert.Equal(t, int64(1024), res.Size)
+}
+
+func TestClient_ImageInspect_NotFound(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("[]")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	_, err := cli.ImageInspect("image-name:v0.1.0")
+	assert.Error(t, err)
+}
+
+func TestClient_ImageHistory(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedHistoryCommand, cmd)
+		return ioutil.NopCloser(strings.NewReader(`[{"id":"1a2b3c4d","created":"2020-01-02T03:04:05Z","createdBy":"/bin/sh -c echo hello","size":512}]`)), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ImageHistory("image-name:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, int64(1577934245), res[0].Created)
+	assert.Equal(t, "/bin/sh -c echo hello", res[0].CreatedBy)
+	assert.Equal(t, int64(512), res[0].Size)
+}
+
+func TestClient_ImageSave(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedSaveCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageSave(ociSaveOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ImageSave_InvalidFormat(t *testing.T) {
+	options := ociSaveOptions
+	options.Format = "invalid"
+
+	_, err := cli.ImageSave(options)
+	assert.Error(t, err)
+}
+
+func TestClient_ImageLoad(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedLoadCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.ImageLoad(ociLoadOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_ContainerRun(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedRunCommand, cmd)
+		return ioutil.NopCloser(strings.NewReader("hello\n")), nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return nil
+	}
+
+	res, err := cli.ContainerRun(ociRunOptions)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, res.ExitCode)
+	output, err := ioutil.ReadAll(res.Body)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello\n", string(output))
+}
+
+func TestClient_ContainerRun_Error(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		return nil, nil, nil
+	}
+	wait = func(cmd *command.Command) error {
+		return errors.New("command was never started")
+	}
+
+	_, err := cli.ContainerRun(ociRunOptions)
+	assert.Error(t, err)
+}
+
+func TestClient_RegistryLogin(t *testing.T) {
+	execute = func(cmd *command.Command) (io.ReadCloser, io.ReadCloser, error) {
+		assert.Equal(t, &expectedLoginCommand, cmd)
+		return nil, nil, nil
+	}
+
+	_, err := cli.RegistryLogin(ociLoginOptions)
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_GenerateAuthRegistryString(t *testing.T) {
+	authString := cli.GenerateAuthRegistryString(authConfig)
+	assert.Equal(t, "user:pass", authString)
+}
+
+var cli = Client{
+	Logger: util.GetLogger(true),
+}
+
+var ociBuildOptions = v1alpha1.OCIBuildOptions{
+	Ctx:         context.Background(),
+	ContextPath: ".",
+	ImageBuildOptions: types.ImageBuildOptions{
+		Dockerfile: "./Dockerfile",
+		Tags:       []string{"image-name:v0.1.0"},
+		NoCache:    true,
+		Labels:     map[string]string{"build-id": "1234"},
+	},
+}
+
+var expectedBuildCommand = command.Builder("podman").Command("build").Flags([]command.Flag{
+	{Name: "f", Value: "./Dockerfile", Short: true, OmitEmpty: true},
+	{Name: "storage-driver", Value: "", Short: false, OmitEmpty: true},
+	{Name: "t", Value: "image-name:v0.1.0", Short: true, OmitEmpty: true},
+	{Name: "no-cache", Value: "", Short: false, OmitEmpty: false},
+	{Name: "label", Value: "build-id=1234", Short: false, OmitEmpty: true},
+}...).Args(".").Build()
+
+var fakeWriteAuthFile = func(image string, registryAuth string) (string, []string, error) {
+	return "/tmp/ocib-auth/config.json", []string{"/tmp/ocib-auth"}, nil
+}
+
+var ociPullOptions = v1alpha1.OCIPullOptions{
+	Ctx: context.Background(),
+	Ref: "image-name",
+	ImagePullOptions: types.ImagePullOptions{
+		RegistryAuth: "this-is-my-auth",
+		Platform:     "linux/arm64",
+	},
+}
+
+var expectedPullCommand = command.Builder("podman").Command("pull").Flags([]command.Flag{
+	{Name: "authfile", Value: "/tmp/ocib-auth/config.json", Short: false, OmitEmpty: true},
+	{Name: "platform", Value: "linux/arm64", Short: false, OmitEmpty: true},
+}...).Args("image-name").TempFiles("/tmp/ocib-auth").Build()
+
+var ociPushOptions = v1alpha1.OCIPushOptions{
+	Ctx: context.Background(),
+	Ref: "image-name",
+	ImagePushOptions: types.ImagePushOptions{
+		RegistryAuth: "this-is-my-auth",
+	},
+	DigestFile: "/tmp/digest",
+}
+
+var expectedPushCommand = command.Builder("podman").Command("push").Flags([]command.Flag{
+	{Name: "authfile", Value: "/tmp/ocib-auth/config.json", Short: false, OmitEmpty: true},
+	{Name: "digestfile", Value: "/tmp/digest", Short: false, OmitEmpty: true},
+}...).Args("image-name").TempFiles("/tmp/ocib-auth").Build()
+
+var ociRemoveOptions = v1alpha1.OCIRemoveOptions{
+	Image:              "image-name",
+	Ctx:                context.Background(),
+	ImageRemoveOptions: types.ImageRemoveOptions{},
+}
+
+var expectedRemoveCommand = command.Builder("podman").Command("rmi").Args("image-name").Build()
+
+var ociPruneOptions = v1alpha1.OCIPruneOptions{
+	Labels: []string{"build-id=1234"},
+	Ctx:    context.Background(),
+}
+
+var expectedPruneCommand = command.Builder("podman").Command("image").Args("prune", "--force", "--filter", "label=build-id=1234").Build()
+
+var ociTagOptions = v1alpha1.OCITagOptions{
+	Source: "image-name:v0.1.0",
+	Target: "example-registry/image-name:v0.1.0",
+	Ctx:    context.Background(),
+}
+
+var expectedTagCommand = command.Builder("podman").Command("tag").Args("image-name:v0.1.0", "example-registry/image-name:v0.1.0").Build()
+
+var expectedInspectCommand = command.Builder("podman").Command("image").Args("inspect", "image-name:v0.1.0").Build()
+
+var expectedHistoryCommand = command.Builder("podman").Command("history").Flags(command.Flag{
+	Name: "format", Value: "json", Short: false, OmitEmpty: true,
+}).Args("image-name:v0.1.0").Build()
+
+var ociSaveOptions = v1alpha1.OCISaveOptions{
+	Image:  "image-name:v0.1.0",
+	Format: v1alpha1.OCIExportFormat,
+	Dest:   "/tmp/image",
+	Ctx:    context.Background(),
+}
+
+var expectedSaveCommand = command.Builder("podman").Command("save").Flags([]command.Flag{
+	{Name: "format", Value: "oci-dir", Short: false, OmitEmpty: true},
+	{Name: "o", Value: "/tmp/image", Short: true, OmitEmpty: true},
+}...).Args("image-name:v0.1.0").Build()
+
+var ociLoadOptions = v1alpha1.OCILoadOptions{
+	Input:  "/tmp/image.tar",
+	Format: v1alpha1.DockerArchiveExportFormat,
+	Ctx:    context.Background(),
+}
+
+var expectedLoadCommand = command.Builder("podman").Command("pull").Args("docker-archive:/tmp/image.tar").Build()
+
+var ociRunOptions = v1alpha1.OCIRunOptions{
+	Image: "image-name:v0.1.0",
+	Cmd:   []string{"echo", "hello"},
+	Ctx:   context.Background(),
+}
+
+var expectedRunCommand = command.Builder("podman").Command("run").Flags([]command.Flag{
+	{Name: "rm", Value: "", Short: false, OmitEmpty: false},
+	{Name: "entrypoint", Value: "echo", Short: false, OmitEmpty: true},
+}...).Args("image-name:v0.1.0", "hello").Build()
+
+var ociLoginOptions = v1alpha1.OCILoginOptions{
+	Ctx:        context.Background(),
+	AuthConfig: authConfig,
+}
+
+var expectedLoginCommand = command.Builder("podman").Command("login").Flags([]command.Flag{
+	{Name: "u", Value: "user", Short: true, OmitEmpty: true},
+	{Name: "password-stdin", Value: "", Short: false, OmitEmpty: false},
+}...).Args("arts-test-registry").Stdin(strings.NewReader("pass")).Build()
+
+var authConfig = types.AuthConfig{
+	Username:      "user",
+	Password:      "pass",
+	ServerAddress: "arts-test-registry",
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/validate"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// NewClient returns a registry client authenticating with the registries of the login specifications, configured
+// with the registry options if they are set
+func NewClient(logins []v1alpha1.LoginSpec, options *v1alpha1.RegistryOptions, logger *logrus.Logger) (Client, error) {
+	auths := make(map[string]authn.Authenticator)
+	for _, login := range logins {
+		reg, err := name.NewRegistry(login.Registry)
+		if err != nil {
+			return Client{}, errors.Wrapf(err, "invalid login registry %s", login.Registry)
+		}
+		username, err := validate.ValidateLoginUsername(login)
+		if err != nil {
+			return Client{}, err
+		}
+		password, err := validate.ValidateLoginPassword(login)
+		if err != nil {
+			return Client{}, err
+		}
+		auths[reg.RegistryStr()] = &authn.Basic{
+			Username: username,
+			Password: password,
+		}
+	}
+	cli := Client{
+		Logger: logger,
+		Auths:  auths,
+	}
+	if options != nil {
+		cli.LayoutPath = options.LayoutPath
+		cli.Insecure = options.Insecure
+	}
+	return cli, nil
+}
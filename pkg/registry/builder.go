@@ -0,0 +1,256 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	dockerregistry "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/util"
+	ispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// The builder client methods of the registry client pull images into, and push images from, the OCI image
+// layout at LayoutPath, where images are named by their reference annotation. Images cannot be built or run.
+
+// ImagePull pulls an image from a registry into the image layout, returning a docker json message stream
+// with the digest of the pulled image
+func (c Client) ImagePull(options v1alpha1.OCIPullOptions) (v1alpha1.OCIPullResponse, error) {
+	if err := c.PullLayout(options.Ref, c.LayoutPath); err != nil {
+		c.Logger.WithError(err).Errorln("error pulling image from registry...")
+		return v1alpha1.OCIPullResponse{}, err
+	}
+	img, err := c.namedImage(options.Ref)
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+
+	body, err := messageStream(jsonmessage.JSONMessage{Status: "Digest: " + digest.String(), ID: options.Ref})
+	if err != nil {
+		return v1alpha1.OCIPullResponse{}, err
+	}
+	return v1alpha1.OCIPullResponse{
+		Body: body,
+	}, nil
+}
+
+// ImagePush pushes an image of the image layout to a registry, returning a docker json message stream with
+// the push result of the pushed image
+func (c Client) ImagePush(options v1alpha1.OCIPushOptions) (v1alpha1.OCIPushResponse, error) {
+	img, err := c.namedImage(options.Ref)
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+	digest, err := c.Push(img, options.Ref)
+	if err != nil {
+		c.Logger.WithError(err).Errorln("error pushing image to registry...")
+		return v1alpha1.OCIPushResponse{}, err
+	}
+	size, err := img.Size()
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+
+	raw, err := json.Marshal(types.PushResult{
+		Tag:    tagOf(options.Ref),
+		Digest: digest,
+		Size:   int(size),
+	})
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+	aux := json.RawMessage(raw)
+	body, err := messageStream(
+		jsonmessage.JSONMessage{Status: "Pushed", ID: options.Ref},
+		jsonmessage.JSONMessage{Aux: &aux},
+	)
+	if err != nil {
+		return v1alpha1.OCIPushResponse{}, err
+	}
+	return v1alpha1.OCIPushResponse{
+		Body:   body,
+		Digest: digest,
+	}, nil
+}
+
+// ImageRemove removes the image with the name from the image layout. The blobs of the image are kept.
+func (c Client) ImageRemove(options v1alpha1.OCIRemoveOptions) (v1alpha1.OCIRemoveResponse, error) {
+	p, err := layout.FromPath(c.LayoutPath)
+	if err != nil {
+		return v1alpha1.OCIRemoveResponse{}, err
+	}
+	if err := p.RemoveDescriptors(match.Annotation(ispec.AnnotationRefName, options.Image)); err != nil {
+		c.Logger.WithError(err).Errorln("error removing image from image layout...")
+		return v1alpha1.OCIRemoveResponse{}, err
+	}
+	return v1alpha1.OCIRemoveResponse{
+		Response: []types.ImageDeleteResponseItem{{Untagged: options.Image}},
+	}, nil
+}
+
+// ImagesPrune is a no-op with the registry client as the image layout holds no dangling images
+func (c Client) ImagesPrune(options v1alpha1.OCIPruneOptions) (v1alpha1.OCIPruneResponse, error) {
+	c.Logger.WithField("labels", options.Labels).Debugln("image layout holds no dangling images, nothing to prune")
+	return v1alpha1.OCIPruneResponse{}, nil
+}
+
+// ImageTag names the image of the image layout with the source name with the target name, replacing any
+// image already named with the target name
+func (c Client) ImageTag(options v1alpha1.OCITagOptions) (v1alpha1.OCITagResponse, error) {
+	img, err := c.namedImage(options.Source)
+	if err != nil {
+		return v1alpha1.OCITagResponse{}, err
+	}
+	if err := writeLayoutImage(c.LayoutPath, img, options.Target); err != nil {
+		c.Logger.WithError(err).Errorln("error tagging image in image layout...")
+		return v1alpha1.OCITagResponse{}, err
+	}
+	return v1alpha1.OCITagResponse{}, nil
+}
+
+// ImageInspect inspects the image of the image layout with the name, returning its config digest as its ID
+func (c Client) ImageInspect(imageId string) (types.ImageInspect, error) {
+	img, err := c.namedImage(imageId)
+	if err != nil {
+		return types.ImageInspect{}, err
+	}
+	config, err := img.ConfigName()
+	if err != nil {
+		return types.ImageInspect{}, err
+	}
+	return types.ImageInspect{
+		ID:       config.String(),
+		RepoTags: []string{imageId},
+	}, nil
+}
+
+// ImageBuild is not supported by the registry client as it cannot build images
+func (c Client) ImageBuild(options v1alpha1.OCIBuildOptions) (v1alpha1.OCIBuildResponse, error) {
+	return v1alpha1.OCIBuildResponse{}, unsupported("image build")
+}
+
+// ImageHistory is not supported by the registry client
+func (c Client) ImageHistory(imageId string) ([]image.HistoryResponseItem, error) {
+	return nil, unsupported("image history")
+}
+
+// ImageSave is not supported by the registry client
+func (c Client) ImageSave(options v1alpha1.OCISaveOptions) (v1alpha1.OCISaveResponse, error) {
+	return v1alpha1.OCISaveResponse{}, unsupported("image export")
+}
+
+// ImageLoad is not supported by the registry client
+func (c Client) ImageLoad(options v1alpha1.OCILoadOptions) (v1alpha1.OCILoadResponse, error) {
+	return v1alpha1.OCILoadResponse{}, unsupported("image load")
+}
+
+// ContainerRun is not supported by the registry client as it cannot run containers
+func (c Client) ContainerRun(options v1alpha1.OCIRunOptions) (v1alpha1.OCIRunResponse, error) {
+	return v1alpha1.OCIRunResponse{}, unsupported("container run")
+}
+
+// RegistryLogin authenticates later pulls and pushes of the registry with the registry auth
+func (c Client) RegistryLogin(options v1alpha1.OCILoginOptions) (v1alpha1.OCILoginResponse, error) {
+	reg, err := name.NewRegistry(options.ServerAddress)
+	if err != nil {
+		return v1alpha1.OCILoginResponse{}, errors.Wrapf(err, "invalid login registry %s", options.ServerAddress)
+	}
+	if c.Auths == nil {
+		return v1alpha1.OCILoginResponse{}, errors.New("registry client has no auths to log in to, create it with NewClient")
+	}
+	c.Auths[reg.RegistryStr()] = &authn.Basic{
+		Username: options.Username,
+		Password: options.Password,
+	}
+	return v1alpha1.OCILoginResponse{
+		AuthenticateOKBody: dockerregistry.AuthenticateOKBody{
+			Status: "login completed",
+		},
+	}, nil
+}
+
+// GenerateAuthRegistryString generates the auth of a registry in the docker config format of base64 encoded
+// username:password
+func (c Client) GenerateAuthRegistryString(auth types.AuthConfig) string {
+	return util.EncodeDockerConfigAuth(auth)
+}
+
+// Capabilities returns the capabilities of the registry client, which neither builds images nor writes exports
+func (c Client) Capabilities() v1alpha1.BuilderCapabilities {
+	return v1alpha1.BuilderCapabilities{}
+}
+
+// namedImage returns the image of the image layout annotated with the name
+func (c Client) namedImage(name string) (v1.Image, error) {
+	index, err := layout.ImageIndexFromPath(c.LayoutPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.Annotations[ispec.AnnotationRefName] == name {
+			return index.Image(desc.Digest)
+		}
+	}
+	return nil, errors.Errorf("no image named %s in image layout %s", name, c.LayoutPath)
+}
+
+// tagOf returns the tag of an image reference, or the digest of a digest reference
+func tagOf(ref string) string {
+	reference, err := name.ParseReference(ref)
+	if err != nil {
+		return ""
+	}
+	return reference.Identifier()
+}
+
+// messageStream returns the docker json messages as the body of a response
+func messageStream(messages ...jsonmessage.JSONMessage) (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range messages {
+		if err := encoder.Encode(&messages[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ioutil.NopCloser(&buf), nil
+}
+
+// unsupported returns the error for an operation the registry client cannot conduct
+func unsupported(operation string) error {
+	return errors.Errorf("%s is not supported by the registry builder", operation)
+}
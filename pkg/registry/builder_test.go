@@ -0,0 +1,106 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ImagePullTagPush(t *testing.T) {
+	host, closeRegistry := newRegistry()
+	defer closeRegistry()
+
+	img, err := random.Image(1024, 1)
+	assert.Equal(t, nil, err)
+	expectedDigest, err := img.Digest()
+	assert.Equal(t, nil, err)
+	_, err = cli.Push(img, host+"/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	layoutCli := cli
+	layoutCli.LayoutPath = dir
+
+	// pulling twice replaces the image in the layout
+	for i := 0; i < 2; i++ {
+		_, err = layoutCli.ImagePull(v1alpha1.OCIPullOptions{Ref: host + "/ocib/image:v0.1.0"})
+		assert.Equal(t, nil, err)
+	}
+	inspect, err := layoutCli.ImageInspect(host + "/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+	configName, err := img.ConfigName()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, configName.String(), inspect.ID)
+
+	_, err = layoutCli.ImageTag(v1alpha1.OCITagOptions{
+		Source: host + "/ocib/image:v0.1.0",
+		Target: host + "/ocib/mirror:v0.1.0",
+	})
+	assert.Equal(t, nil, err)
+
+	pushResponse, err := layoutCli.ImagePush(v1alpha1.OCIPushOptions{Ref: host + "/ocib/mirror:v0.1.0"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest.String(), pushResponse.Digest)
+
+	var pushResult types.PushResult
+	decoder := json.NewDecoder(pushResponse.Body)
+	for decoder.More() {
+		var msg jsonmessage.JSONMessage
+		assert.Equal(t, nil, decoder.Decode(&msg))
+		if msg.Aux != nil {
+			assert.Equal(t, nil, json.Unmarshal(*msg.Aux, &pushResult))
+		}
+	}
+	assert.Equal(t, types.PushResult{Tag: "v0.1.0", Digest: expectedDigest.String(), Size: pushResult.Size}, pushResult)
+
+	mirrorDigest, err := cli.Digest(host + "/ocib/mirror:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest.String(), mirrorDigest)
+
+	_, err = layoutCli.ImageRemove(v1alpha1.OCIRemoveOptions{Image: host + "/ocib/mirror:v0.1.0"})
+	assert.Equal(t, nil, err)
+	_, err = layoutCli.ImageInspect(host + "/ocib/mirror:v0.1.0")
+	assert.Error(t, err)
+	_, err = layoutCli.ImageInspect(host + "/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+}
+
+func TestClient_RegistryLogin(t *testing.T) {
+	loginCli := Client{Auths: make(map[string]authn.Authenticator)}
+	_, err := loginCli.RegistryLogin(v1alpha1.OCILoginOptions{
+		AuthConfig: types.AuthConfig{Username: "user", Password: "pass", ServerAddress: "example-registry"},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &authn.Basic{Username: "user", Password: "pass"}, loginCli.Auths["example-registry"])
+}
+
+func TestClient_Unsupported(t *testing.T) {
+	_, err := cli.ImageBuild(v1alpha1.OCIBuildOptions{})
+	assert.EqualError(t, err, "image build is not supported by the registry builder")
+}
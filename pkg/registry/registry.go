@@ -0,0 +1,220 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	ispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Client conducts registry operations over the registry HTTP API, without a docker daemon or buildah
+type Client struct {
+	Logger *logrus.Logger
+	// LayoutPath is the OCI image layout directory images are pulled into and pushed from when the client
+	// is used as a builder client
+	LayoutPath string
+	// Auths are the authenticators of registries, keyed by registry host. Registries without an
+	// authenticator are accessed anonymously
+	Auths map[string]authn.Authenticator
+	// Insecure allows registries to be accessed over plain http
+	Insecure bool
+}
+
+// Pull pulls an image from a registry. Layers are fetched lazily as the image is read.
+func (c Client) Pull(ref string) (v1.Image, error) {
+	reference, err := c.parseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	c.Logger.WithField("ref", reference.Name()).Debugln("pulling image from registry")
+	return remote.Image(reference, c.options(reference)...)
+}
+
+// PullLayout pulls an image from a registry into an OCI image layout directory, annotated with its reference.
+// An image of the layout with the same reference is replaced.
+func (c Client) PullLayout(ref string, path string) error {
+	img, err := c.Pull(ref)
+	if err != nil {
+		return err
+	}
+	return writeLayoutImage(path, img, ref)
+}
+
+// Push pushes an image to a registry, returning the digest of the pushed image
+func (c Client) Push(img v1.Image, ref string) (string, error) {
+	reference, err := c.parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	c.Logger.WithField("ref", reference.Name()).Debugln("pushing image to registry")
+	if err := remote.Write(reference, img, c.options(reference)...); err != nil {
+		return "", err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+// PushLayout pushes an image of an OCI image layout directory to a registry, returning the digest of the pushed
+// image. The image is the only image of the layout, or the image annotated with the name when there are many.
+func (c Client) PushLayout(path string, name string, ref string) (string, error) {
+	img, err := layoutImage(path, name)
+	if err != nil {
+		return "", err
+	}
+	return c.Push(img, ref)
+}
+
+// Tag tags the image of a reference with a new tag in the same repository
+func (c Client) Tag(ref string, tag string) error {
+	reference, err := c.parseReference(ref)
+	if err != nil {
+		return err
+	}
+	desc, err := remote.Get(reference, c.options(reference)...)
+	if err != nil {
+		return err
+	}
+	c.Logger.WithFields(logrus.Fields{"ref": reference.Name(), "tag": tag}).Debugln("tagging image in registry")
+	return remote.Tag(reference.Context().Tag(tag), desc, c.options(reference)...)
+}
+
+// Copy copies an image or image index between registries, returning the digest of the copied image
+func (c Client) Copy(src string, dst string) (string, error) {
+	srcRef, err := c.parseReference(src)
+	if err != nil {
+		return "", err
+	}
+	dstRef, err := c.parseReference(dst)
+	if err != nil {
+		return "", err
+	}
+
+	desc, err := remote.Get(srcRef, c.options(srcRef)...)
+	if err != nil {
+		return "", err
+	}
+	c.Logger.WithFields(logrus.Fields{"src": srcRef.Name(), "dst": dstRef.Name()}).Debugln("copying image between registries")
+
+	switch desc.MediaType {
+	case types.OCIImageIndex, types.DockerManifestList:
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return "", err
+		}
+		if err := remote.WriteIndex(dstRef, index, c.options(dstRef)...); err != nil {
+			return "", err
+		}
+	default:
+		img, err := desc.Image()
+		if err != nil {
+			return "", err
+		}
+		if err := remote.Write(dstRef, img, c.options(dstRef)...); err != nil {
+			return "", err
+		}
+	}
+	return desc.Digest.String(), nil
+}
+
+// Digest resolves the digest of the image of a reference
+func (c Client) Digest(ref string) (string, error) {
+	reference, err := c.parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(reference, c.options(reference)...)
+	if err != nil {
+		// some registries do not support HEAD requests for manifests
+		c.Logger.WithError(err).Debugln("failed to resolve digest with a HEAD request, falling back to GET")
+		getDesc, err := remote.Get(reference, c.options(reference)...)
+		if err != nil {
+			return "", err
+		}
+		return getDesc.Digest.String(), nil
+	}
+	return desc.Digest.String(), nil
+}
+
+// parseReference parses an image reference, allowing plain http when the client is insecure
+func (c Client) parseReference(ref string) (name.Reference, error) {
+	var opts []name.Option
+	if c.Insecure {
+		opts = append(opts, name.Insecure)
+	}
+	reference, err := name.ParseReference(ref, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse image reference %s", ref)
+	}
+	return reference, nil
+}
+
+// options returns the remote options for a reference, authenticating with the registry of the reference
+func (c Client) options(reference name.Reference) []remote.Option {
+	auth, ok := c.Auths[reference.Context().RegistryStr()]
+	if !ok {
+		auth = authn.Anonymous
+	}
+	return []remote.Option{remote.WithAuth(auth)}
+}
+
+// writeLayoutImage writes an image to an OCI image layout annotated with a reference, replacing the image
+// with the same reference. The layout is created if it does not exist.
+func writeLayoutImage(path string, img v1.Image, ref string) error {
+	p, err := layout.FromPath(path)
+	if err != nil {
+		if p, err = layout.Write(path, empty.Index); err != nil {
+			return err
+		}
+	}
+	return p.ReplaceImage(img, match.Annotation(ispec.AnnotationRefName, ref), layout.WithAnnotations(map[string]string{
+		ispec.AnnotationRefName: ref,
+	}))
+}
+
+// layoutImage returns the image of an OCI image layout, which is the only image of the layout or the image
+// annotated with the name
+func layoutImage(path string, name string) (v1.Image, error) {
+	index, err := layout.ImageIndexFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) == 1 {
+		return index.Image(manifest.Manifests[0].Digest)
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.Annotations[ispec.AnnotationRefName] == name {
+			return index.Image(desc.Digest)
+		}
+	}
+	return nil, errors.Errorf("no image named %s in image layout %s", name, path)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	inprocess "github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_PushPull(t *testing.T) {
+	host, closeRegistry := newRegistry()
+	defer closeRegistry()
+
+	img, err := random.Image(1024, 2)
+	assert.Equal(t, nil, err)
+	expectedDigest, err := img.Digest()
+	assert.Equal(t, nil, err)
+
+	digest, err := cli.Push(img, host+"/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest.String(), digest)
+
+	pulled, err := cli.Pull(host + "/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+	pulledDigest, err := pulled.Digest()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest, pulledDigest)
+}
+
+func TestClient_PushLayout(t *testing.T) {
+	host, closeRegistry := newRegistry()
+	defer closeRegistry()
+
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	path, err := layout.Write(dir, empty.Index)
+	assert.Equal(t, nil, err)
+	img, err := random.Image(1024, 1)
+	assert.Equal(t, nil, err)
+	other, err := random.Image(1024, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, path.AppendImage(img, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "image:v0.1.0",
+	})))
+	assert.Equal(t, nil, path.AppendImage(other, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "other:v0.1.0",
+	})))
+
+	digest, err := cli.PushLayout(dir, "image:v0.1.0", host+"/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+	expectedDigest, err := img.Digest()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest.String(), digest)
+
+	_, err = cli.PushLayout(dir, "missing:v0.1.0", host+"/ocib/missing:v0.1.0")
+	assert.Error(t, err)
+}
+
+func TestClient_PullLayout(t *testing.T) {
+	host, closeRegistry := newRegistry()
+	defer closeRegistry()
+
+	img, err := random.Image(1024, 1)
+	assert.Equal(t, nil, err)
+	_, err = cli.Push(img, host+"/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+
+	dir, err := ioutil.TempDir("", "ocib-layout")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, nil, cli.PullLayout(host+"/ocib/image:v0.1.0", dir))
+
+	pulled, err := layoutImage(dir, host+"/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+	expectedDigest, err := img.Digest()
+	assert.Equal(t, nil, err)
+	pulledDigest, err := pulled.Digest()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest, pulledDigest)
+}
+
+func TestClient_TagDigest(t *testing.T) {
+	host, closeRegistry := newRegistry()
+	defer closeRegistry()
+
+	img, err := random.Image(1024, 1)
+	assert.Equal(t, nil, err)
+	digest, err := cli.Push(img, host+"/ocib/image:v0.1.0")
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, cli.Tag(host+"/ocib/image:v0.1.0", "latest"))
+
+	tagDigest, err := cli.Digest(host + "/ocib/image:latest")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, digest, tagDigest)
+
+	_, err = cli.Digest(host + "/ocib/image:missing")
+	assert.Error(t, err)
+}
+
+func TestClient_Copy(t *testing.T) {
+	srcHost, closeSrc := newRegistry()
+	defer closeSrc()
+	dstHost, closeDst := newRegistry()
+	defer closeDst()
+
+	index, err := random.Index(1024, 1, 2)
+	assert.Equal(t, nil, err)
+	expectedDigest, err := index.Digest()
+	assert.Equal(t, nil, err)
+
+	reference, err := cli.parseReference(srcHost + "/ocib/index:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, remote.WriteIndex(reference, index))
+
+	digest, err := cli.Copy(srcHost+"/ocib/index:v0.1.0", dstHost+"/ocib/index:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest.String(), digest)
+
+	dstDigest, err := cli.Digest(dstHost + "/ocib/index:v0.1.0")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedDigest.String(), dstDigest)
+}
+
+func TestNewClient(t *testing.T) {
+	logins := []v1alpha1.LoginSpec{
+		{
+			Registry: "docker.io",
+			Creds: v1alpha1.RegistryCreds{
+				Plain: v1alpha1.PlainCreds{Username: "user", Password: "pass"},
+			},
+		},
+		{
+			Registry: "example-registry",
+			Token:    "token",
+			Creds: v1alpha1.RegistryCreds{
+				Plain: v1alpha1.PlainCreds{Username: "user"},
+			},
+		},
+	}
+	loginCli, err := NewClient(logins, nil, logrus.New())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &authn.Basic{Username: "user", Password: "pass"}, loginCli.Auths["index.docker.io"])
+	assert.Equal(t, &authn.Basic{Username: "user", Password: "token"}, loginCli.Auths["example-registry"])
+	assert.Equal(t, false, loginCli.Insecure)
+
+	optionsCli, err := NewClient(nil, &v1alpha1.RegistryOptions{LayoutPath: "/tmp/images", Insecure: true}, logrus.New())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/tmp/images", optionsCli.LayoutPath)
+	assert.Equal(t, true, optionsCli.Insecure)
+
+	_, err = NewClient([]v1alpha1.LoginSpec{{Registry: "example-registry"}}, nil, logrus.New())
+	assert.Error(t, err)
+}
+
+// newRegistry starts an in-process registry, returning its host and a function to stop it
+func newRegistry() (string, func()) {
+	server := httptest.NewServer(inprocess.New(inprocess.Logger(log.New(ioutil.Discard, "", 0))))
+	return strings.TrimPrefix(server.URL, "http://"), server.Close
+}
+
+var cli = Client{
+	Logger:   logrus.New(),
+	Insecure: true,
+}
@@ -47,6 +47,12 @@ func RequestRemote(url string, filepath string, auth v1alpha1.RemoteCreds) error
 		return err
 	}
 
+	defer func() {
+		if err := file.Close(); err != nil {
+			return
+		}
+	}()
+
 	if _, err := io.Copy(file, res.Body); err != nil {
 		return err
 	}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Record holds the tags rendered at build time. Tag templates are rendered once when images are built and
+// the rendered tags are read back at push time, as the variables of a later push can differ from the build
+// e.g. the current time, or the git repository of a build context which has since been cleaned up.
+type Record struct {
+	// StepVariables are the tag template variables of each built step by step name, used to render the tags of
+	// push specs
+	StepVariables map[string]Variables `json:"stepVariables"`
+	// Steps are the rendered tags of each built step by step name, with the primary tag first
+	Steps map[string][]string `json:"steps"`
+}
+
+// NewRecord returns an empty record of the tags of a build
+func NewRecord() *Record {
+	return &Record{
+		StepVariables: make(map[string]Variables),
+		Steps:         make(map[string][]string),
+	}
+}
+
+// ReadRecord reads the record of the tags of the last build. A nil record is returned if nothing has been built.
+func ReadRecord(path string) (*Record, error) {
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read tag record %s", path)
+	}
+	record := &Record{}
+	if err := json.Unmarshal(file, record); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal tag record %s", path)
+	}
+	if record.StepVariables == nil {
+		record.StepVariables = make(map[string]Variables)
+	}
+	if record.Steps == nil {
+		record.Steps = make(map[string][]string)
+	}
+	return record, nil
+}
+
+// Write writes the record to path
+func (r *Record) Write(path string) error {
+	record, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, record, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write tag record %s", path)
+	}
+	return nil
+}
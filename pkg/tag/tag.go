@@ -0,0 +1,221 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// EnvVarSourceDateEpoch is the reproducible builds environment variable used to pin the time used in tag templates
+// see https://reproducible-builds.org/specs/source-date-epoch/
+const EnvVarSourceDateEpoch = "SOURCE_DATE_EPOCH"
+
+var semVerRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z\-.]+))?(?:\+([0-9A-Za-z\-.]+))?$`)
+
+// Variables are the values made available to tag templates e.g. {{ .Git.ShortSHA }}
+type Variables struct {
+	// Git holds information about the git repository the build is run from
+	Git Git
+	// SemVer holds the semantic version parsed from the git tag at HEAD
+	SemVer SemVer
+	// Time is the time used for date and timestamp variables
+	Time time.Time
+}
+
+// Git holds information about the git repository the build is run from
+type Git struct {
+	// SHA is the full commit SHA of HEAD
+	SHA string
+	// ShortSHA is the abbreviated commit SHA of HEAD
+	ShortSHA string
+	// Branch is the branch checked out at HEAD
+	Branch string
+	// Tag is the git tag pointing at HEAD
+	Tag string
+}
+
+// SemVer is a semantic version parsed from a git tag
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Metadata   string
+	// Version is the full semantic version without a leading v
+	Version string
+}
+
+// Date formats the template time with a go time layout e.g. {{ .Date "20060102" }}
+func (v Variables) Date(layout string) string {
+	return v.Time.UTC().Format(layout)
+}
+
+// Timestamp returns the template time as a unix timestamp
+func (v Variables) Timestamp() string {
+	return strconv.FormatInt(v.Time.Unix(), 10)
+}
+
+// NewVariables resolves tag template variables from the git repository found at the passed in path.
+// Any branch or tag set in the git context takes precedence over what is read from the repository.
+// The template time is read from SOURCE_DATE_EPOCH, falling back to the commit time of HEAD and
+// finally the current time. Variables can differ between invocations, so tags are rendered once at
+// build time and pushed from a Record.
+func NewVariables(path string, gitContext *v1alpha1.GitContext) (Variables, error) {
+	vars := Variables{}
+
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err == nil {
+		if err := vars.readRepository(repo); err != nil {
+			return Variables{}, err
+		}
+	} else if err != git.ErrRepositoryNotExists {
+		return Variables{}, errors.Wrapf(err, "failed to open git repository at %s", path)
+	}
+
+	if gitContext != nil {
+		if gitContext.Branch != "" {
+			vars.Git.Branch = gitContext.Branch
+		}
+		if gitContext.Tag != "" {
+			vars.Git.Tag = gitContext.Tag
+		}
+	}
+	vars.SemVer = ParseSemVer(vars.Git.Tag)
+
+	if epoch, ok := os.LookupEnv(EnvVarSourceDateEpoch); ok {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return Variables{}, errors.Wrapf(err, "invalid %s value %s", EnvVarSourceDateEpoch, epoch)
+		}
+		vars.Time = time.Unix(seconds, 0)
+	}
+	if vars.Time.IsZero() {
+		vars.Time = time.Now()
+	}
+	return vars, nil
+}
+
+// readRepository populates the git variables and commit time from HEAD of the repository
+func (v *Variables) readRepository(repo *git.Repository) error {
+	head, err := repo.Head()
+	if err != nil {
+		// a freshly initialised repository has no HEAD to read from
+		if err == plumbing.ErrReferenceNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "failed to read git HEAD")
+	}
+
+	sha := head.Hash().String()
+	v.Git.SHA = sha
+	v.Git.ShortSHA = sha[:7]
+	if head.Name().IsBranch() {
+		v.Git.Branch = head.Name().Short()
+	}
+
+	if commit, err := repo.CommitObject(head.Hash()); err == nil {
+		v.Time = commit.Committer.When
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return errors.Wrap(err, "failed to read git tags")
+	}
+	return tags.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		// annotated tags point at a tag object rather than the commit itself
+		if annotated, err := repo.TagObject(ref.Hash()); err == nil {
+			target = annotated.Target
+		}
+		if target == head.Hash() {
+			v.Git.Tag = ref.Name().Short()
+		}
+		return nil
+	})
+}
+
+// ParseSemVer parses a semantic version, returning an empty SemVer if the version is not valid
+func ParseSemVer(version string) SemVer {
+	match := semVerRegex.FindStringSubmatch(version)
+	if match == nil {
+		return SemVer{}
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+	return SemVer{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		Prerelease: match[4],
+		Metadata:   match[5],
+		Version:    strings.TrimPrefix(version, "v"),
+	}
+}
+
+// Render renders a single tag template with the passed in variables
+func Render(tag string, vars Variables) (string, error) {
+	if !strings.Contains(tag, "{{") {
+		return tag, nil
+	}
+	tmpl, err := template.New("tag").Option("missingkey=error").Parse(tag)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse tag template %s", tag)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, vars); err != nil {
+		return "", errors.Wrapf(err, "failed to render tag template %s", tag)
+	}
+	rendered := strings.TrimSpace(buf.String())
+	if rendered == "" {
+		return "", errors.Errorf("tag template %s rendered an empty tag", tag)
+	}
+	return rendered, nil
+}
+
+// RenderAll renders the primary tag along with any additional tags, dropping empty and duplicate tags.
+// The primary tag is always returned first.
+func RenderAll(primary string, tags []string, vars Variables) ([]string, error) {
+	var rendered []string
+	seen := make(map[string]bool)
+	for _, t := range append([]string{primary}, tags...) {
+		if t == "" {
+			continue
+		}
+		r, err := Render(t, vars)
+		if err != nil {
+			return nil, err
+		}
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		rendered = append(rendered, r)
+	}
+	return rendered, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	rendered, err := Render("{{ .Git.Branch }}-{{ .Git.ShortSHA }}-{{ .Date \"20060102\" }}", vars)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "master-1a2b3c4-20200102", rendered)
+
+	rendered, err = Render("v{{ .SemVer.Major }}.{{ .SemVer.Minor }}", vars)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "v1.2", rendered)
+
+	rendered, err = Render("static-tag", vars)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "static-tag", rendered)
+}
+
+func TestRender_Empty(t *testing.T) {
+	_, err := Render("{{ .Git.Tag }}", Variables{})
+	assert.Error(t, err)
+
+	_, err = Render("{{ .Git.Unknown }}", vars)
+	assert.Error(t, err)
+}
+
+func TestRenderAll(t *testing.T) {
+	tags, err := RenderAll("{{ .Git.ShortSHA }}", []string{"latest", "{{ .SemVer.Version }}", "1a2b3c4", ""}, vars)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"1a2b3c4", "latest", "1.2.3-rc.1"}, tags)
+}
+
+func TestParseSemVer(t *testing.T) {
+	assert.Equal(t, SemVer{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc.1", Version: "1.2.3-rc.1"}, ParseSemVer("v1.2.3-rc.1"))
+	assert.Equal(t, SemVer{Major: 0, Minor: 1, Patch: 0, Metadata: "build.5", Version: "0.1.0+build.5"}, ParseSemVer("0.1.0+build.5"))
+	assert.Equal(t, SemVer{}, ParseSemVer("release-1"))
+}
+
+func TestNewVariables(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-tag")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Setenv(EnvVarSourceDateEpoch, "1577923200")
+	assert.Equal(t, nil, err)
+	defer os.Unsetenv(EnvVarSourceDateEpoch)
+
+	variables, err := NewVariables(dir, &v1alpha1.GitContext{Branch: "feature", Tag: "v2.0.1"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "feature", variables.Git.Branch)
+	assert.Equal(t, 2, variables.SemVer.Major)
+	assert.Equal(t, "20200102", variables.Date("20060102"))
+	assert.Equal(t, "1577923200", variables.Timestamp())
+}
+
+func TestRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ocib-tag")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tags.json")
+
+	record, err := ReadRecord(path)
+	assert.Equal(t, nil, err)
+	assert.Nil(t, record)
+
+	record = NewRecord()
+	record.StepVariables["my-step"] = vars
+	record.Steps["my-step"] = []string{"1a2b3c4", "latest"}
+	assert.Equal(t, nil, record.Write(path))
+
+	read, err := ReadRecord(path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, record.Steps, read.Steps)
+	assert.Equal(t, vars.Git, read.StepVariables["my-step"].Git)
+	assert.True(t, vars.Time.Equal(read.StepVariables["my-step"].Time))
+}
+
+var vars = Variables{
+	Git: Git{
+		SHA:      "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b",
+		ShortSHA: "1a2b3c4",
+		Branch:   "master",
+		Tag:      "v1.2.3-rc.1",
+	},
+	SemVer: ParseSemVer("v1.2.3-rc.1"),
+	Time:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 BlackRock, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// EncodeDockerConfigAuth encodes registry credentials in the docker config auth format of base64 encoded
+// username:password
+func EncodeDockerConfigAuth(auth types.AuthConfig) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", auth.Username, auth.Password)))
+}
+
+// WriteDockerConfigAuth writes the auth of a registry to the config.json of a docker config directory,
+// keeping the auth of any other registries and any other settings already in the config
+func WriteDockerConfigAuth(configDir string, auth types.AuthConfig) error {
+	configPath := filepath.Join(configDir, "config.json")
+
+	config := make(map[string]json.RawMessage)
+	if contents, err := ioutil.ReadFile(configPath); err == nil {
+		if err := json.Unmarshal(contents, &config); err != nil {
+			return errors.Wrapf(err, "failed to parse docker config %s", configPath)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	auths := make(map[string]types.AuthConfig)
+	if raw, ok := config["auths"]; ok {
+		if err := json.Unmarshal(raw, &auths); err != nil {
+			return errors.Wrapf(err, "failed to parse auths of docker config %s", configPath)
+		}
+	}
+	auths[auth.ServerAddress] = types.AuthConfig{
+		Auth: EncodeDockerConfigAuth(auth),
+	}
+
+	raw, err := json.Marshal(auths)
+	if err != nil {
+		return err
+	}
+	config["auths"] = raw
+
+	contents, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configPath, contents, 0600)
+}
+
+// WriteTempAuthFile writes registry credentials in the format username:password for the registry of an image
+// to a new docker config in a temporary directory, which only the current user can read. The path of the config
+// is returned for builders which read credentials from an auth file, keeping them out of process arguments.
+// The temporary directory is the directory of the config, which should be removed once the config has been read.
+func WriteTempAuthFile(image string, credentials string) (string, error) {
+	username, password := credentials, ""
+	if i := strings.Index(credentials, ":"); i >= 0 {
+		username, password = credentials[:i], credentials[i+1:]
+	}
+	auth := types.AuthConfig{
+		Username:      username,
+		Password:      password,
+		ServerAddress: ImageRegistry(image),
+	}
+
+	dir, err := ioutil.TempDir("", "ocib-auth")
+	if err != nil {
+		return "", err
+	}
+	if err := WriteDockerConfigAuth(dir, auth); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// WriteCommandAuthFile writes registry auth in the format username[:password] for the registry of an image to a
+// temporary auth file, returning the path of the auth file and the temporary files to remove once the command
+// reading it has been waited on. No auth file is written without registry auth.
+func WriteCommandAuthFile(image string, registryAuth string) (string, []string, error) {
+	if registryAuth == "" {
+		return "", nil, nil
+	}
+	authFile, err := WriteTempAuthFile(image, registryAuth)
+	if err != nil {
+		return "", nil, err
+	}
+	return authFile, []string{filepath.Dir(authFile)}, nil
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package util
 
 import (
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/mholt/archiver"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
+	"github.com/ocibuilder/ocibuilder/pkg/common"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -91,6 +94,14 @@ func ReadCredentials(client kubernetes.Interface, creds *v1alpha1.Credentials) (
 		return value, nil
 	}
 
+	if creds.File != "" {
+		value, err := ioutil.ReadFile(creds.File)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read the credentials file %s", creds.File)
+		}
+		return string(value), nil
+	}
+
 	if creds.KubeSecret != nil {
 		if client == nil {
 			return "", errors.New("kubernetes client is not initialized")
@@ -108,3 +119,15 @@ func ReadCredentials(client kubernetes.Interface, creds *v1alpha1.Credentials) (
 
 	return "", errors.New("unknown credentials format")
 }
+
+// ImageRegistry returns the registry of an image name, or the default registry if the name has no registry
+func ImageRegistry(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return common.DefaultImageRegistry
+	}
+	if domain := image[:i]; strings.ContainsAny(domain, ".:") || domain == "localhost" {
+		return domain
+	}
+	return common.DefaultImageRegistry
+}
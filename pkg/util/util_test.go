@@ -17,7 +17,9 @@ limitations under the License.
 package util
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
@@ -50,6 +52,21 @@ func TestReadCredentials(t *testing.T) {
 		convey.So(secret, convey.ShouldEqual, "secret")
 	})
 
+	convey.Convey("Given the credentials in a file, read the secret", t, func() {
+		file, err := ioutil.TempFile("", "ocib-creds")
+		convey.So(err, convey.ShouldBeNil)
+		defer os.Remove(file.Name())
+		_, err = file.WriteString("secret")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(file.Close(), convey.ShouldBeNil)
+		creds := &v1alpha1.Credentials{
+			File: file.Name(),
+		}
+		secret, err := ReadCredentials(fakeClient, creds)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(secret, convey.ShouldEqual, "secret")
+	})
+
 	convey.Convey("Given the credentials in Kubernetes secret", t, func() {
 		secretObj := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
@@ -65,3 +82,49 @@ func TestReadCredentials(t *testing.T) {
 		convey.So(string(sensor.Data["accessKey"]), convey.ShouldEqual, "access")
 	})
 }
+
+func TestImageRegistry(t *testing.T) {
+	convey.Convey("Given image names, return the registry of each image", t, func() {
+		convey.So(ImageRegistry("alpine"), convey.ShouldEqual, "docker.io")
+		convey.So(ImageRegistry("library/alpine"), convey.ShouldEqual, "docker.io")
+		convey.So(ImageRegistry("gcr.io/distroless/base"), convey.ShouldEqual, "gcr.io")
+		convey.So(ImageRegistry("localhost:5000/image-name"), convey.ShouldEqual, "localhost:5000")
+	})
+}
+
+func TestWriteTempAuthFile(t *testing.T) {
+	convey.Convey("Given registry credentials, write them to a temporary auth file", t, func() {
+		path, err := WriteTempAuthFile("localhost:5000/image-name:v0.1.0", "user:pass")
+		convey.So(err, convey.ShouldBeNil)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		contents, err := ioutil.ReadFile(path)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(string(contents), convey.ShouldContainSubstring, `"localhost:5000"`)
+		convey.So(string(contents), convey.ShouldContainSubstring, `"auth": "dXNlcjpwYXNz"`)
+		convey.So(string(contents), convey.ShouldNotContainSubstring, "pass\"")
+
+		info, err := os.Stat(path)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(info.Mode().Perm(), convey.ShouldEqual, os.FileMode(0600))
+	})
+}
+
+func TestWriteCommandAuthFile(t *testing.T) {
+	convey.Convey("Given registry credentials, write an auth file for a command", t, func() {
+		path, tempFiles, err := WriteCommandAuthFile("localhost:5000/image-name:v0.1.0", "user:pass")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(tempFiles, convey.ShouldResemble, []string{filepath.Dir(path)})
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err = os.Stat(path)
+		convey.So(err, convey.ShouldBeNil)
+	})
+
+	convey.Convey("Given no registry credentials, write no auth file", t, func() {
+		path, tempFiles, err := WriteCommandAuthFile("localhost:5000/image-name:v0.1.0", "")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(path, convey.ShouldEqual, "")
+		convey.So(tempFiles, convey.ShouldBeNil)
+	})
+}
@@ -18,7 +18,12 @@ package validate
 
 import (
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/ocibuilder/ocibuilder/pkg/apis/ocibuilder/v1alpha1"
 	"github.com/ocibuilder/ocibuilder/pkg/common"
 	"github.com/pkg/errors"
@@ -36,17 +41,146 @@ func Validate(spec *v1alpha1.OCIBuilderSpec) error {
 	if spec.Login == nil && spec.Push != nil {
 		return errors.New("at least one login must be provided")
 	}
+	for _, push := range spec.Push {
+		if push.From != "" {
+			if err := ValidatePushFrom(push.From, spec.Build); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// ValidatePushFrom validates a push spec references a build step whose image is kept after it is built
+func ValidatePushFrom(from string, build *v1alpha1.BuildSpec) error {
+	if build != nil {
+		for _, step := range build.Steps {
+			if step.ImageMetadata == nil || step.Name != from {
+				continue
+			}
+			if step.Purge {
+				return errors.Errorf("can't push from build step %s as its image is purged after it is built", from)
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("no build step named %s found to push from", from)
+}
+
 // ValidateBuildTemplateStep validates build template step
 func ValidateBuildTemplateStep(step v1alpha1.BuildTemplateStep) error {
-	if step.Ansible == nil && step.Docker == nil {
+	if step.Ansible == nil && step.Docker == nil && step.Packages == nil {
 		return errors.New("at least one step type should be defined")
 	}
 	if step.Docker != nil && step.Docker.Inline == nil && step.Docker.Path == "" && step.Docker.Url == "" {
 		return errors.New("at least one docker cmd location should be defined")
 	}
+	if step.Packages != nil {
+		return ValidatePackagesStep(*step.Packages)
+	}
+	return nil
+}
+
+// packageName matches the name of a package, which is added to a shell command unquoted
+var packageName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._+-]*$`)
+
+// packageVersion matches the version of a package, including the epoch and revision of the version
+var packageVersion = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._+:~-]*$`)
+
+// ValidatePackagesStep validates the package manager and packages of a packages step
+func ValidatePackagesStep(step v1alpha1.PackagesStep) error {
+	switch step.Manager {
+	case "", v1alpha1.AutoPackageManager, v1alpha1.AptPackageManager, v1alpha1.ApkPackageManager,
+		v1alpha1.YumPackageManager, v1alpha1.DnfPackageManager:
+	default:
+		return errors.Errorf("unknown package manager %s, must be one of auto, apt, apk, yum or dnf", step.Manager)
+	}
+	if len(step.Packages) == 0 {
+		return errors.New("at least one package should be defined in a packages step")
+	}
+	for _, pkg := range step.Packages {
+		if !packageName.MatchString(pkg.Name) {
+			return errors.Errorf("invalid package name %q", pkg.Name)
+		}
+		if pkg.Version != "" && !packageVersion.MatchString(pkg.Version) {
+			return errors.Errorf("invalid version %q of package %s", pkg.Version, pkg.Name)
+		}
+	}
+	return nil
+}
+
+// ValidateTemplateSource validates a template source has either a git repository or a url
+func ValidateTemplateSource(source v1alpha1.TemplateSource) error {
+	if source.Git == nil && source.Url == "" {
+		return errors.New("template source must have either a git repository or a url")
+	}
+	if source.Git != nil && source.Url != "" {
+		return errors.New("template source can't have both a git repository and a url")
+	}
+	if source.Git != nil && source.Git.URL == "" {
+		return errors.New("git template source must have a url")
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(source.Path), "/") {
+		if elem == ".." {
+			return errors.Errorf("template source path %s can't refer outside of the repository", source.Path)
+		}
+	}
+	return nil
+}
+
+// templateInputName matches the name of a template input, which is a go template identifier
+var templateInputName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ValidateTemplateInputs validates the inputs of a build template and the values passed to them by a stage
+func ValidateTemplateInputs(template v1alpha1.BuildTemplate, with map[string]string) error {
+	inputs := make(map[string]v1alpha1.TemplateInput)
+	for _, input := range template.Inputs {
+		if !templateInputName.MatchString(input.Name) {
+			return errors.Errorf("invalid input name %q of template %s, must be a letter or underscore followed by letters, digits or underscores", input.Name, template.Name)
+		}
+		if _, ok := inputs[input.Name]; ok {
+			return errors.Errorf("input %s of template %s is defined more than once", input.Name, template.Name)
+		}
+		if input.Default != "" {
+			if err := validateTemplateInputValue(input, input.Default); err != nil {
+				return errors.Wrapf(err, "invalid default of input %s of template %s", input.Name, template.Name)
+			}
+		}
+		inputs[input.Name] = input
+	}
+
+	for name, value := range with {
+		input, ok := inputs[name]
+		if !ok {
+			return errors.Errorf("unknown input %s passed to template %s", name, template.Name)
+		}
+		if err := validateTemplateInputValue(input, value); err != nil {
+			return errors.Wrapf(err, "invalid value of input %s of template %s", name, template.Name)
+		}
+	}
+	for _, input := range template.Inputs {
+		if _, ok := with[input.Name]; input.Required && !ok {
+			return errors.Errorf("missing value of required input %s of template %s", input.Name, template.Name)
+		}
+	}
+	return nil
+}
+
+// validateTemplateInputValue validates a value of a template input is of the type of the input
+func validateTemplateInputValue(input v1alpha1.TemplateInput, value string) error {
+	switch input.Type {
+	case "", v1alpha1.StringTemplateInput:
+	case v1alpha1.NumberTemplateInput:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.Errorf("%s is not a number", value)
+		}
+	case v1alpha1.BooleanTemplateInput:
+		if value != "true" && value != "false" {
+			return errors.Errorf("%s is not true or false", value)
+		}
+	default:
+		return errors.Errorf("unknown input type %s, must be one of string, number or boolean", input.Type)
+	}
 	return nil
 }
 
@@ -105,12 +239,100 @@ func ValidatePushSpec(spec *v1alpha1.PushSpec) error {
 	if spec.Image == "" {
 		return errors.New("image name must be specified for push")
 	}
-	if spec.Tag == "" {
+	if spec.Tag == "" && len(spec.Tags) == 0 {
 		return errors.New("tag must be specified for push")
 	}
 	return nil
 }
 
+// ValidatePullSpec validates an image pull specification
+func ValidatePullSpec(spec *v1alpha1.PullSpec) error {
+	if spec.Image == "" {
+		return errors.New("image must be specified for pull")
+	}
+	if spec.Tag != "" && spec.Digest != "" {
+		return errors.Errorf("only one of tag or digest can be specified to pull image %s", spec.Image)
+	}
+	if spec.Policy == "" {
+		spec.Policy = v1alpha1.PullIfNotPresent
+	}
+	switch spec.Policy {
+	case v1alpha1.PullAlways, v1alpha1.PullIfNotPresent:
+	default:
+		return errors.Errorf("invalid pull policy %s, must be one of Always or IfNotPresent", spec.Policy)
+	}
+	return nil
+}
+
+// ValidateExportSpec validates an image export specification
+func ValidateExportSpec(spec v1alpha1.ExportSpec) error {
+	switch spec.Format {
+	case v1alpha1.OCIExportFormat, v1alpha1.OCIArchiveExportFormat, v1alpha1.DockerArchiveExportFormat:
+	default:
+		return errors.Errorf("invalid export format %s, must be one of oci, oci-archive or docker-archive", spec.Format)
+	}
+	if spec.Path == "" {
+		return errors.New("path must be specified for export")
+	}
+	return nil
+}
+
+// idMapping matches a user namespace ID mapping in the form container-id:host-id:size
+var idMapping = regexp.MustCompile(`^\d+:\d+:\d+$`)
+
+// ValidateBuildahOptions validates the options for running buildah
+func ValidateBuildahOptions(options v1alpha1.BuildahOptions) error {
+	switch options.Isolation {
+	case "", v1alpha1.OCIIsolation, v1alpha1.RootlessIsolation, v1alpha1.ChrootIsolation:
+	default:
+		return errors.Errorf("invalid buildah isolation %s, must be one of oci, rootless or chroot", options.Isolation)
+	}
+	switch options.Format {
+	case "", v1alpha1.OCIImageFormat, v1alpha1.DockerImageFormat:
+	default:
+		return errors.Errorf("invalid buildah image format %s, must be one of oci or docker", options.Format)
+	}
+	switch options.CgroupNS {
+	case "", "host", "private":
+	default:
+		return errors.Errorf("invalid buildah cgroup namespace %s, must be one of host or private", options.CgroupNS)
+	}
+	for _, mapping := range append(options.UIDMap, options.GIDMap...) {
+		if !idMapping.MatchString(mapping) {
+			return errors.Errorf("invalid buildah id mapping %s, must be in the form container-id:host-id:size", mapping)
+		}
+	}
+	if options.UserNS == "host" && (len(options.UIDMap) > 0 || len(options.GIDMap) > 0) {
+		return errors.New("buildah id mappings cannot be used with the host user namespace")
+	}
+	return nil
+}
+
+// ValidateImageTest validates the structure tests of a build step
+func ValidateImageTest(test v1alpha1.ImageTest) error {
+	for _, commandTest := range test.Commands {
+		if len(commandTest.Command) == 0 {
+			return errors.Errorf("command must be specified for command test %s", commandTest.Name)
+		}
+		for _, expr := range append(commandTest.ExpectedOutput, commandTest.ExcludedOutput...) {
+			if _, err := regexp.Compile(expr); err != nil {
+				return errors.Wrapf(err, "invalid output regex in command test %s", commandTest.Name)
+			}
+		}
+	}
+	for _, fileTest := range test.Files {
+		if fileTest.Path == "" {
+			return errors.New("path must be specified for file test")
+		}
+	}
+	if test.MaxSize != "" {
+		if _, err := units.FromHumanSize(test.MaxSize); err != nil {
+			return errors.Wrapf(err, "invalid max image size %s", test.MaxSize)
+		}
+	}
+	return nil
+}
+
 // ValidateParams validates path to destination in param section of specs
 func ValidateParams(specJSON []byte, src string) error {
 	if res := gjson.GetBytes(specJSON, src); res.Str == "" {